@@ -0,0 +1,650 @@
+// Package api defines the machine-readable output of kroctl. Every result
+// carries a kind and apiVersion so downstream tooling can detect changes to
+// the format. Fields are only added within an apiVersion, never removed or
+// renamed.
+package api
+
+import "encoding/json"
+
+// APIVersion is the version of the output format.
+const APIVersion = "kroctl.kro.run/v1alpha1"
+
+// Kinds of results, as printed by kroctl schema.
+const (
+	KindPushResult      = "PushResult"
+	KindInspectResult   = "InspectResult"
+	KindLintReport      = "LintReport"
+	KindVersionInfo     = "VersionInfo"
+	KindOwners          = "Owners"
+	KindOutdatedReport  = "OutdatedReport"
+	KindUpdateReport    = "UpdateReport"
+	KindImageList       = "ImageList"
+	KindInspectList     = "InspectList"
+	KindRepoStatus      = "RepoStatus"
+	KindScanReport      = "ScanReport"
+	KindConflictReport  = "ConflictReport"
+	KindApproveResult   = "ApproveResult"
+	KindAttestResult    = "AttestResult"
+	KindDeprecateResult = "DeprecateResult"
+	KindBreakingReport  = "BreakingReport"
+	KindBumpResult      = "BumpResult"
+	KindChangelog       = "Changelog"
+	KindDiffReport      = "DiffReport"
+	KindDoctorReport    = "DoctorReport"
+	KindExportResult    = "ExportResult"
+	KindMergeResult     = "MergeResult"
+	KindSplitResult     = "SplitResult"
+	KindSizeReport      = "SizeReport"
+	KindVendorResult    = "VendorResult"
+	KindLayoutReport    = "LayoutReport"
+	KindWhoamiResult    = "WhoamiResult"
+	KindMigrateResult   = "MigrateResult"
+	KindFormatReport    = "FormatReport"
+)
+
+// TypeMeta identifies the kind and version of a result.
+type TypeMeta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+}
+
+// NewTypeMeta returns the TypeMeta of a kind at the current APIVersion.
+func NewTypeMeta(kind string) TypeMeta {
+	return TypeMeta{APIVersion: APIVersion, Kind: kind}
+}
+
+// PushResult is the output of kroctl push.
+type PushResult struct {
+	TypeMeta
+	// Reference is the reference the artifact was pushed to.
+	Reference string `json:"reference"`
+	// Digest is the digest of the pushed manifest.
+	Digest string `json:"digest"`
+	// Variant is set when the artifact was added to an index as a variant.
+	Variant string `json:"variant,omitempty"`
+	// Files are the names of the RGD files in the artifact.
+	Files []string `json:"files"`
+	// UploadedBytes is the number of bytes sent to the registry.
+	UploadedBytes int64 `json:"uploadedBytes"`
+	// SkippedBytes is the size of the blobs the registry already had.
+	SkippedBytes int64 `json:"skippedBytes"`
+	// Supersedes is the digest of the earlier version the stack names as
+	// its subject.
+	Supersedes string `json:"supersedes,omitempty"`
+}
+
+// InspectResult is the output of kroctl inspect.
+type InspectResult struct {
+	TypeMeta
+	// Reference is the reference that was inspected.
+	Reference string `json:"reference"`
+	// Registry is the host of the registry.
+	Registry string `json:"registry"`
+	// Repository is the repository within the registry.
+	Repository string `json:"repository"`
+	// Index is set when the reference points at an index.
+	Index *Index `json:"index,omitempty"`
+	// Manifest is the inspected manifest. It is unset for an index of
+	// several manifests when no variant was selected.
+	Manifest *Manifest `json:"manifest,omitempty"`
+	// Summary is set when inspect ran with --summary.
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// InspectList is the output of kroctl inspect for several references.
+type InspectList struct {
+	TypeMeta
+	Stacks []InspectedStack `json:"stacks"`
+}
+
+// InspectedStack is a row of an InspectList.
+type InspectedStack struct {
+	Reference string `json:"reference"`
+	// Version is the version in the stack config, or the tag of stacks
+	// pushed without one.
+	Version string `json:"version,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+	Created string `json:"created,omitempty"`
+	// RGDs is the number of ResourceGraphDefinitions in the stack.
+	RGDs int `json:"rgds"`
+	// Size is the total size of the manifest, config, and layers in bytes.
+	Size int64 `json:"size"`
+	// Signed reports whether a Notation or cosign signature is attached.
+	Signed bool `json:"signed"`
+	// Deprecation is set when the stack was marked with kroctl deprecate.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// Error is set when the reference could not be inspected.
+	Error string `json:"error,omitempty"`
+}
+
+// RepoStatus is the output of kroctl repo status.
+type RepoStatus struct {
+	TypeMeta
+	Repository string `json:"repository"`
+	// Latest is the highest release version among the tags, or the highest
+	// prerelease when there is no release.
+	Latest string `json:"latest,omitempty"`
+	// Signed and Deprecated count the tags that are signed and deprecated.
+	Signed     int `json:"signed"`
+	Deprecated int `json:"deprecated"`
+	// TotalSize is the size of the distinct manifests, configs, and layers
+	// of all tags in bytes; blobs shared between tags count once.
+	TotalSize int64            `json:"totalSize"`
+	Stacks    []InspectedStack `json:"stacks"`
+}
+
+// ScanReport is the output of kroctl scan.
+type ScanReport struct {
+	TypeMeta
+	// Reference is the scanned stack, empty when local files were scanned.
+	Reference string `json:"reference,omitempty"`
+	Scanner   string `json:"scanner"`
+	// Totals counts the findings of all images per severity.
+	Totals SeverityCounts `json:"totals"`
+	Images []ImageScan    `json:"images"`
+}
+
+// SeverityCounts counts vulnerabilities per severity.
+type SeverityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+// ImageScan is what the scanner found in an image a stack deploys.
+type ImageScan struct {
+	Image    string          `json:"image"`
+	Counts   SeverityCounts  `json:"counts"`
+	Findings []Vulnerability `json:"findings"`
+}
+
+// Vulnerability is a vulnerability found in a package of an image.
+type Vulnerability struct {
+	ID       string `json:"id"`
+	Package  string `json:"package"`
+	Version  string `json:"version,omitempty"`
+	Severity string `json:"severity"`
+}
+
+// ConflictReport is the output of kroctl conflicts.
+type ConflictReport struct {
+	TypeMeta
+	// Stacks are the compared stacks, as they were passed.
+	Stacks    []string   `json:"stacks"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Conflict is an RGD name or an API defined by more than one stack.
+type Conflict struct {
+	// Type is rgd when the stacks share an RGD name, and api when RGDs of
+	// different names define the same kind.
+	Type        string       `json:"type"`
+	Name        string       `json:"name"`
+	Definitions []Definition `json:"definitions"`
+	// Compatible is false when a definition breaks instances of another.
+	Compatible bool `json:"compatible"`
+	// Differences are the changes from the first definition to the others.
+	Differences []string `json:"differences,omitempty"`
+}
+
+// Definition is where a stack defines a conflicting RGD or API.
+type Definition struct {
+	Stack string `json:"stack"`
+	RGD   string `json:"rgd"`
+	File  string `json:"file"`
+}
+
+// Index is an OCI image index.
+type Index struct {
+	Digest    string       `json:"digest"`
+	Manifests []IndexEntry `json:"manifests"`
+}
+
+// IndexEntry is a manifest listed in an index.
+type IndexEntry struct {
+	Digest       string `json:"digest"`
+	ArtifactType string `json:"artifactType,omitempty"`
+	Variant      string `json:"variant,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	Digest       string `json:"digest"`
+	ArtifactType string `json:"artifactType"`
+	// Stack reports whether the manifest is a kro RGD stack.
+	Stack bool `json:"stack"`
+	// Size is the total size of the manifest, config, and layers in bytes.
+	Size        int64             `json:"size"`
+	Created     string            `json:"created,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Config describes the stack, for stacks pushed with a config.
+	Config *StackConfig `json:"config,omitempty"`
+	Layers []Layer      `json:"layers"`
+	// Supersedes lists the earlier versions the stack replaces, following
+	// the subject of each manifest, newest first.
+	Supersedes []Subject `json:"supersedes,omitempty"`
+	// SupersededBy lists the stacks that name this one as their subject.
+	SupersededBy []Subject `json:"supersededBy,omitempty"`
+	// Deprecation is set when the stack was marked with kroctl deprecate.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// Attestations are set when inspect ran with --attestations.
+	Attestations []Attestation `json:"attestations,omitempty"`
+}
+
+// Attestation is an in-toto attestation attached with kroctl attest.
+type Attestation struct {
+	Digest        string `json:"digest"`
+	PredicateType string `json:"predicateType"`
+	Created       string `json:"created,omitempty"`
+	// Predicate is the JSON document the attestation states.
+	Predicate json.RawMessage `json:"predicate"`
+}
+
+// Deprecation tells consumers to stop installing a stack.
+type Deprecation struct {
+	Message string `json:"message"`
+	Created string `json:"created,omitempty"`
+}
+
+// Subject is a manifest related to another through the OCI subject field.
+type Subject struct {
+	Digest       string `json:"digest"`
+	ArtifactType string `json:"artifactType,omitempty"`
+	Created      string `json:"created,omitempty"`
+}
+
+// StackConfig describes a stack without downloading its layers.
+type StackConfig struct {
+	Name          string        `json:"name"`
+	Version       string        `json:"version,omitempty"`
+	RGDs          []StackRGD    `json:"rgds"`
+	Dependencies  []string      `json:"dependencies"`
+	Compatibility Compatibility `json:"compatibility"`
+}
+
+// StackRGD is a ResourceGraphDefinition of a stack.
+type StackRGD struct {
+	Name   string `json:"name"`
+	API    string `json:"api,omitempty"`
+	File   string `json:"file"`
+	Digest string `json:"digest"`
+}
+
+// Compatibility constrains the clusters a stack can be installed in.
+type Compatibility struct {
+	KroAPIVersions   []string `json:"kroAPIVersions"`
+	KubernetesBefore string   `json:"kubernetesBefore,omitempty"`
+}
+
+// Layer is a layer of a manifest.
+type Layer struct {
+	Name      string `json:"name"`
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Summary describes what a stack will manage.
+type Summary struct {
+	RGDs         int         `json:"rgds"`
+	APIs         []string    `json:"apis"`
+	Resources    []KindCount `json:"resources"`
+	ExternalRefs int         `json:"externalRefs"`
+	// Images are the container images the resource templates reference.
+	Images []Image `json:"images,omitempty"`
+}
+
+// Image is a container image a stack deploys.
+type Image struct {
+	Name string `json:"name"`
+	// Licenses and Source are read from the labels of the image with
+	// inspect --licenses, and empty when it has none.
+	Licenses string `json:"licenses,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// KindCount is the number of resource templates of a Kubernetes kind.
+type KindCount struct {
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+// LintReport is the output of kroctl validate.
+type LintReport struct {
+	TypeMeta
+	// Files is the number of files that were validated.
+	Files       int          `json:"files"`
+	Errors      int          `json:"errors"`
+	Warnings    int          `json:"warnings"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Diagnostic is a problem found in an RGD file.
+type Diagnostic struct {
+	File string `json:"file"`
+	// Line is 0 when the problem is not tied to a line.
+	Line int `json:"line,omitempty"`
+	// Severity is error or warning.
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// VersionInfo is the output of kroctl version.
+type VersionInfo struct {
+	TypeMeta
+	Version string `json:"version"`
+	// Commit and BuildDate are empty for builds without VCS information.
+	Commit      string `json:"commit,omitempty"`
+	BuildDate   string `json:"buildDate,omitempty"`
+	GoVersion   string `json:"goVersion"`
+	OrasVersion string `json:"orasVersion,omitempty"`
+	Platform    string `json:"platform"`
+}
+
+// Owners is the output of kroctl owners.
+type Owners struct {
+	TypeMeta
+	Reference string `json:"reference"`
+	// Digest is the digest of the manifest the owners were read from.
+	Digest string `json:"digest"`
+	// Team, Slack, and Escalation are empty when the stack was pushed
+	// without them.
+	Team       string `json:"team,omitempty"`
+	Slack      string `json:"slack,omitempty"`
+	Escalation string `json:"escalation,omitempty"`
+}
+
+// OutdatedReport is the output of kroctl outdated.
+type OutdatedReport struct {
+	TypeMeta
+	Stacks []OutdatedStack `json:"stacks"`
+	// Outdated is the number of stacks with a newer version available.
+	Outdated int `json:"outdated"`
+}
+
+// OutdatedStack compares the version of a stack with the newest one.
+type OutdatedStack struct {
+	Reference string `json:"reference"`
+	// Current is the tag or digest of the reference.
+	Current string `json:"current"`
+	// Latest is set when a newer version is available.
+	Latest string `json:"latest,omitempty"`
+	// Status is up to date, update available, or not versioned.
+	Status string `json:"status"`
+}
+
+// UpdateReport is the output of kroctl update.
+type UpdateReport struct {
+	TypeMeta
+	// Written reports whether the vendor directory was updated.
+	Written bool          `json:"written"`
+	Updates []StackUpdate `json:"updates"`
+}
+
+// StackUpdate is a vendored stack with a newer version.
+type StackUpdate struct {
+	// Reference is the reference the stack was vendored from.
+	Reference    string `json:"reference"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	NewReference string `json:"newReference"`
+	OldDigest    string `json:"oldDigest"`
+	NewDigest    string `json:"newDigest"`
+	// Breaking is set when any of the changes is breaking.
+	Breaking bool `json:"breaking"`
+	// Changes lists the API changes between the versions, as printed by
+	// kroctl changelog.
+	Changes []string `json:"changes"`
+}
+
+// ImageList is the output of kroctl images.
+type ImageList struct {
+	TypeMeta
+	Images []ImageUse `json:"images"`
+}
+
+// ImageUse is a container image referenced in a resource template.
+type ImageUse struct {
+	Image    string `json:"image"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	RGD      string `json:"rgd"`
+	Resource string `json:"resource"`
+	// Pinned is the image with the digest it was pinned to by --pin.
+	Pinned string `json:"pinned,omitempty"`
+}
+
+// ApproveResult is the output of kroctl approve.
+type ApproveResult struct {
+	TypeMeta
+	Reference string `json:"reference"`
+	// Digest is the digest of the approved manifest.
+	Digest string `json:"digest"`
+	// Approval is the digest of the approval artifact.
+	Approval string `json:"approval"`
+	By       string `json:"by"`
+	Ticket   string `json:"ticket,omitempty"`
+}
+
+// AttestResult is the output of kroctl attest.
+type AttestResult struct {
+	TypeMeta
+	Reference string `json:"reference"`
+	// Digest is the digest of the attested manifest.
+	Digest string `json:"digest"`
+	// Attestation is the digest of the attestation artifact.
+	Attestation   string `json:"attestation"`
+	PredicateType string `json:"predicateType"`
+}
+
+// DeprecateResult is the output of kroctl deprecate.
+type DeprecateResult struct {
+	TypeMeta
+	Reference string `json:"reference"`
+	// Digest is the digest of the deprecated manifest.
+	Digest string `json:"digest"`
+	// Deprecation is the digest of the deprecation artifact.
+	Deprecation string `json:"deprecation"`
+	Message     string `json:"message"`
+}
+
+// APIChange is a change to the instance API of an RGD.
+type APIChange struct {
+	RGD string `json:"rgd"`
+	// Change is added, removed, or modified.
+	Change string `json:"change"`
+	// Subject is what changed, such as an RGD, a spec field, or a resource.
+	Subject string `json:"subject"`
+	// Path is the field path or resource ID, empty for the RGD itself.
+	Path   string `json:"path,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	// Breaking is set when existing instances or their consumers may stop
+	// working.
+	Breaking bool `json:"breaking"`
+}
+
+// BreakingReport is the output of kroctl breaking.
+type BreakingReport struct {
+	TypeMeta
+	Against string `json:"against"`
+	// Changes are the breaking changes.
+	Changes []APIChange `json:"changes"`
+	// Allowed is set when --version is a major version bump from the
+	// version of --against, which allows breaking changes.
+	Allowed bool `json:"allowed"`
+}
+
+// BumpResult is the output of kroctl bump without files to push.
+type BumpResult struct {
+	TypeMeta
+	Repository string `json:"repository"`
+	// Current is the latest release, empty when there is none.
+	Current string `json:"current,omitempty"`
+	Next    string `json:"next"`
+}
+
+// Changelog is the output of kroctl changelog.
+type Changelog struct {
+	TypeMeta
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Changes  []APIChange `json:"changes"`
+	Breaking int         `json:"breaking"`
+}
+
+// DiffReport is the output of kroctl diff.
+type DiffReport struct {
+	TypeMeta
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Files are the files that differ.
+	Files []FileDiff `json:"files"`
+}
+
+// FileDiff is a file that differs between two stacks.
+type FileDiff struct {
+	Name string `json:"name"`
+	// Status is added, removed, or modified.
+	Status string `json:"status"`
+	// Diff is the unified diff, without --semantic.
+	Diff string `json:"diff,omitempty"`
+	// Changes are the field-level changes, with --semantic.
+	Changes []string `json:"changes,omitempty"`
+}
+
+// DoctorReport is the output of kroctl doctor.
+type DoctorReport struct {
+	TypeMeta
+	Checks   []Check `json:"checks"`
+	Passed   int     `json:"passed"`
+	Warnings int     `json:"warnings"`
+	Failed   int     `json:"failed"`
+}
+
+// Check is the outcome of a single diagnostic of kroctl doctor.
+type Check struct {
+	Name string `json:"name"`
+	// Status is pass, warn, or fail.
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// ExportResult is the output of kroctl export.
+type ExportResult struct {
+	TypeMeta
+	Reference string `json:"reference"`
+	// Format is helm or kustomize.
+	Format string `json:"format"`
+	// Output is the directory the files were written to.
+	Output string   `json:"output"`
+	Files  []string `json:"files"`
+}
+
+// MergeResult is the output of kroctl merge.
+type MergeResult struct {
+	TypeMeta
+	Reference string   `json:"reference"`
+	Digest    string   `json:"digest"`
+	Sources   []string `json:"sources"`
+	// Files are the names of the RGD files in the merged artifact.
+	Files         []string `json:"files"`
+	UploadedBytes int64    `json:"uploadedBytes"`
+	SkippedBytes  int64    `json:"skippedBytes"`
+}
+
+// SplitResult is the output of kroctl split.
+type SplitResult struct {
+	TypeMeta
+	Reference string          `json:"reference"`
+	Artifacts []SplitArtifact `json:"artifacts"`
+}
+
+// SplitArtifact is an RGD published as an artifact of its own.
+type SplitArtifact struct {
+	File      string `json:"file"`
+	Reference string `json:"reference"`
+	Digest    string `json:"digest"`
+}
+
+// SizeReport is the output of kroctl size.
+type SizeReport struct {
+	TypeMeta
+	Repository string    `json:"repository"`
+	Tags       []TagSize `json:"tags"`
+	// Blobs is the number of distinct blobs of all tags.
+	Blobs int `json:"blobs"`
+	// Stored is the size of the distinct blobs in bytes.
+	Stored int64 `json:"stored"`
+	// Referenced is the sum of the sizes of all tags in bytes, counting
+	// shared blobs once per tag.
+	Referenced int64 `json:"referenced"`
+}
+
+// TagSize is the size of the blobs a tag references.
+type TagSize struct {
+	Tag    string `json:"tag"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+}
+
+// VendorResult is the output of kroctl vendor.
+type VendorResult struct {
+	TypeMeta
+	// Output is the vendor directory.
+	Output string          `json:"output"`
+	Stacks []VendoredStack `json:"stacks"`
+}
+
+// VendoredStack is a stack copied into a vendor directory.
+type VendoredStack struct {
+	Reference string `json:"reference"`
+	Digest    string `json:"digest"`
+	// Path is the directory of the stack, relative to the vendor directory.
+	Path  string   `json:"path"`
+	Files []string `json:"files"`
+}
+
+// LayoutReport is the output of kroctl verify-layout.
+type LayoutReport struct {
+	TypeMeta
+	Reference string `json:"reference"`
+	Digest    string `json:"digest"`
+	// Problems are the ways the artifact deviates from the kroctl layout.
+	Problems []string `json:"problems"`
+}
+
+// WhoamiResult is the output of kroctl whoami.
+type WhoamiResult struct {
+	TypeMeta
+	Registry string `json:"registry"`
+	// Credentials is where the credentials were looked up.
+	Credentials string `json:"credentials"`
+	Identity    string `json:"identity"`
+	// Access is ok when the registry accepts the credentials, and denied
+	// otherwise.
+	Access string `json:"access"`
+}
+
+// MigrateResult is the output of kroctl migrate-artifact.
+type MigrateResult struct {
+	TypeMeta
+	Reference    string `json:"reference"`
+	ArtifactType string `json:"artifactType"`
+	// Migrated is false when the stack already had the artifact type.
+	Migrated bool   `json:"migrated"`
+	Digest   string `json:"digest"`
+	// Previous is the digest of the manifest before the migration.
+	Previous string `json:"previous,omitempty"`
+}
+
+// FormatReport is the output of kroctl fmt.
+type FormatReport struct {
+	TypeMeta
+	// Files are the files that were not formatted.
+	Files []string `json:"files"`
+	// Written is set when the files were rewritten, and unset with --check.
+	Written bool `json:"written"`
+}
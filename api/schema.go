@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// kinds maps each kind to its Go type.
+var kinds = map[string]reflect.Type{
+	KindPushResult:      reflect.TypeFor[PushResult](),
+	KindInspectResult:   reflect.TypeFor[InspectResult](),
+	KindLintReport:      reflect.TypeFor[LintReport](),
+	KindVersionInfo:     reflect.TypeFor[VersionInfo](),
+	KindOwners:          reflect.TypeFor[Owners](),
+	KindOutdatedReport:  reflect.TypeFor[OutdatedReport](),
+	KindUpdateReport:    reflect.TypeFor[UpdateReport](),
+	KindImageList:       reflect.TypeFor[ImageList](),
+	KindInspectList:     reflect.TypeFor[InspectList](),
+	KindRepoStatus:      reflect.TypeFor[RepoStatus](),
+	KindScanReport:      reflect.TypeFor[ScanReport](),
+	KindConflictReport:  reflect.TypeFor[ConflictReport](),
+	KindApproveResult:   reflect.TypeFor[ApproveResult](),
+	KindAttestResult:    reflect.TypeFor[AttestResult](),
+	KindDeprecateResult: reflect.TypeFor[DeprecateResult](),
+	KindBreakingReport:  reflect.TypeFor[BreakingReport](),
+	KindBumpResult:      reflect.TypeFor[BumpResult](),
+	KindChangelog:       reflect.TypeFor[Changelog](),
+	KindDiffReport:      reflect.TypeFor[DiffReport](),
+	KindDoctorReport:    reflect.TypeFor[DoctorReport](),
+	KindExportResult:    reflect.TypeFor[ExportResult](),
+	KindMergeResult:     reflect.TypeFor[MergeResult](),
+	KindSplitResult:     reflect.TypeFor[SplitResult](),
+	KindSizeReport:      reflect.TypeFor[SizeReport](),
+	KindVendorResult:    reflect.TypeFor[VendorResult](),
+	KindLayoutReport:    reflect.TypeFor[LayoutReport](),
+	KindWhoamiResult:    reflect.TypeFor[WhoamiResult](),
+	KindMigrateResult:   reflect.TypeFor[MigrateResult](),
+	KindFormatReport:    reflect.TypeFor[FormatReport](),
+}
+
+// Kinds returns the kinds that have a schema, sorted by name.
+func Kinds() []string {
+	names := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		names = append(names, kind)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Schema returns the JSON Schema of a kind.
+func Schema(kind string) ([]byte, error) {
+	t, ok := kinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown kind %q, expected one of %s", kind, strings.Join(Kinds(), ", "))
+	}
+
+	schema := schemaFor(t)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = kind
+
+	// Pin the envelope to this kind and version
+	props := schema["properties"].(map[string]any)
+	props["apiVersion"] = map[string]any{"const": APIVersion}
+	props["kind"] = map[string]any{"const": kind}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	// Raw JSON fields hold documents of any shape
+	if t == reflect.TypeFor[json.RawMessage]() {
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		props := map[string]any{}
+		var required []string
+		addFields(t, props, &required)
+		schema := map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		panic(fmt.Sprintf("unsupported type %s in api", t))
+	}
+}
+
+// addFields adds the JSON fields of a struct, flattening embedded structs
+// as encoding/json does.
+func addFields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addFields(field.Type, props, required)
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		props[name] = schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
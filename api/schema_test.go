@@ -0,0 +1,84 @@
+package api_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/api"
+)
+
+func TestKinds(t *testing.T) {
+	assert.Equal(t, []string{"ApproveResult", "AttestResult", "BreakingReport", "BumpResult", "Changelog", "ConflictReport", "DeprecateResult", "DiffReport", "DoctorReport", "ExportResult", "FormatReport", "ImageList", "InspectList", "InspectResult", "LayoutReport", "LintReport", "MergeResult", "MigrateResult", "OutdatedReport", "Owners", "PushResult", "RepoStatus", "ScanReport", "SizeReport", "SplitResult", "UpdateReport", "VendorResult", "VersionInfo", "WhoamiResult"}, api.Kinds())
+}
+
+func TestSchema(t *testing.T) {
+	data, err := api.Schema(api.KindPushResult)
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	assert.Equal(t, "PushResult", schema["title"])
+	assert.Equal(t, "object", schema["type"])
+
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"const": api.APIVersion}, props["apiVersion"])
+	assert.Equal(t, map[string]any{"const": "PushResult"}, props["kind"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, props["files"])
+
+	assert.ElementsMatch(t, []any{"apiVersion", "kind", "reference", "digest", "files", "uploadedBytes", "skippedBytes"},
+		schema["required"])
+}
+
+func TestSchema_CoversEncodedFields(t *testing.T) {
+	result := api.InspectResult{
+		TypeMeta: api.NewTypeMeta(api.KindInspectResult),
+		Index:    &api.Index{Manifests: []api.IndexEntry{{Variant: "aws", Platform: "linux/amd64"}}},
+		Manifest: &api.Manifest{Created: "now", Annotations: map[string]string{"a": "b"}, Layers: []api.Layer{{}}},
+		Summary:  &api.Summary{Resources: []api.KindCount{{}}},
+	}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var encoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &encoded))
+
+	schemaData, err := api.Schema(api.KindInspectResult)
+	require.NoError(t, err)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(schemaData, &schema))
+
+	assertCovered(t, "", encoded, schema)
+}
+
+// assertCovered checks that every field of an encoded object is declared
+// in the schema, since the schemas do not allow additional properties.
+func assertCovered(t *testing.T, path string, value any, schema map[string]any) {
+	t.Helper()
+	switch v := value.(type) {
+	case map[string]any:
+		props, ok := schema["properties"].(map[string]any)
+		if !ok {
+			return // maps declare additionalProperties instead
+		}
+		for key, field := range v {
+			fieldSchema, ok := props[key].(map[string]any)
+			if !assert.True(t, ok, "field %s%s is missing from the schema", path, key) {
+				continue
+			}
+			assertCovered(t, path+key+".", field, fieldSchema)
+		}
+	case []any:
+		for _, item := range v {
+			assertCovered(t, path, item, schema["items"].(map[string]any))
+		}
+	}
+}
+
+func TestSchema_UnknownKind(t *testing.T) {
+	_, err := api.Schema("Nope")
+	assert.EqualError(t, err, `unknown kind "Nope", expected one of ApproveResult, AttestResult, BreakingReport, BumpResult, Changelog, ConflictReport, DeprecateResult, DiffReport, DoctorReport, ExportResult, FormatReport, ImageList, InspectList, InspectResult, LayoutReport, LintReport, MergeResult, MigrateResult, OutdatedReport, Owners, PushResult, RepoStatus, ScanReport, SizeReport, SplitResult, UpdateReport, VendorResult, VersionInfo, WhoamiResult`)
+}
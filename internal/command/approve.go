@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type ApproveOptions struct {
+	Reference string
+	By        string
+	Ticket    string
+	Variant   string
+}
+
+func NewApproveCommand(cli *CLI) *cobra.Command {
+	opts := ApproveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "approve <reference>",
+		Short: "Approve an RGD stack for installation",
+		Long: "Approve an RGD stack for installation.\n\n" +
+			"Attaches an approval to the stack as a referrer artifact, recording\n" +
+			"who approved it and the change ticket it was approved under. The\n" +
+			"approval belongs to the digest, so pushing new content to the tag\n" +
+			"requires a new approval.\n\n" +
+			"Export with --require-approval refuses stacks without one, as a\n" +
+			"lightweight promotion control.\n\n" +
+			"Examples:\n" +
+			"  kroctl approve ghcr.io/acme/kro-stack:v1.2.0 --by alice --ticket OPS-123\n\n" +
+			"  kroctl export helm ghcr.io/acme/kro-stack:v1.2.0 --require-approval\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunApprove(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.By, "by", "", "Who approves the stack (defaults to the current user)")
+	cmd.Flags().StringVar(&opts.Ticket, "ticket", "", "Change ticket the approval is recorded under")
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Variant to approve when the reference is an index of variants")
+
+	return cmd
+}
+
+func RunApprove(ctx context.Context, cli *CLI, opts *ApproveOptions) error {
+	by := opts.By
+	if by == "" {
+		by = currentUser()
+	}
+
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	desc, manifest, err := oci.FetchManifest(ctx, repo, opts.Reference, opts.Variant)
+	if err != nil {
+		return err
+	}
+	if !oci.IsStack(manifest) {
+		return fmt.Errorf("%s is not a kro RGD stack", opts.Reference)
+	}
+
+	approval, err := oci.Approve(ctx, repo, desc, by, opts.Ticket)
+	if err != nil {
+		return err
+	}
+
+	result := &api.ApproveResult{
+		TypeMeta:  api.NewTypeMeta(api.KindApproveResult),
+		Reference: opts.Reference,
+		Digest:    desc.Digest.String(),
+		Approval:  approval.Digest.String(),
+		By:        by,
+		Ticket:    opts.Ticket,
+	}
+	err = render(cli, result, func(s *view.Stream, result *api.ApproveResult) error {
+		s.Printf("Approved %s@%s by %s\n", result.Reference, result.Digest, result.By)
+		s.Printf("Approval: %s\n", result.Approval)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cli.auditChange("approve", opts.Reference, desc.Digest.String())
+	return nil
+}
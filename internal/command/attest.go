@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type AttestOptions struct {
+	Reference     string
+	PredicateType string
+	Predicate     string
+	Variant       string
+}
+
+func NewAttestCommand(cli *CLI) *cobra.Command {
+	opts := AttestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "attest <reference>",
+		Short: "Attach an attestation to an RGD stack",
+		Long: "Attach an attestation to an RGD stack.\n\n" +
+			"Wraps a JSON predicate, such as vulnerability scan results or a\n" +
+			"test report, in an in-toto statement about the stack and attaches\n" +
+			"it as a referrer artifact. The predicate type is a URI naming the\n" +
+			"kind of predicate. The statement is not signed.\n\n" +
+			"List the attestations of a stack with kroctl inspect\n" +
+			"--attestations.\n\n" +
+			"Examples:\n" +
+			"  kroctl attest ghcr.io/acme/kro-stack:v1.2.0 --type https://cosign.sigstore.dev/attestation/vuln/v1 --predicate scan.json\n\n" +
+			"  kroctl inspect ghcr.io/acme/kro-stack:v1.2.0 --attestations\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunAttest(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.PredicateType, "type", "", "URI of the predicate type (required)")
+	cmd.Flags().StringVar(&opts.Predicate, "predicate", "", "JSON file with the predicate (required)")
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Variant to attest when the reference is an index of variants")
+	_ = cmd.MarkFlagRequired("type")
+	_ = cmd.MarkFlagRequired("predicate")
+
+	return cmd
+}
+
+func RunAttest(ctx context.Context, cli *CLI, opts *AttestOptions) error {
+	predicate, err := os.ReadFile(opts.Predicate)
+	if err != nil {
+		return fmt.Errorf("failed to read predicate: %w", err)
+	}
+
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	desc, manifest, err := oci.FetchManifest(ctx, repo, opts.Reference, opts.Variant)
+	if err != nil {
+		return err
+	}
+	if !oci.IsStack(manifest) {
+		return fmt.Errorf("%s is not a kro RGD stack", opts.Reference)
+	}
+
+	name := repo.Reference.Registry + "/" + repo.Reference.Repository
+	attestation, err := oci.Attest(ctx, repo, name, desc, opts.PredicateType, predicate)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.Predicate, err)
+	}
+
+	result := &api.AttestResult{
+		TypeMeta:      api.NewTypeMeta(api.KindAttestResult),
+		Reference:     opts.Reference,
+		Digest:        desc.Digest.String(),
+		Attestation:   attestation.Digest.String(),
+		PredicateType: opts.PredicateType,
+	}
+	err = render(cli, result, func(s *view.Stream, result *api.AttestResult) error {
+		s.Printf("Attested %s@%s\n", result.Reference, result.Digest)
+		s.Printf("Attestation: %s\n", result.Attestation)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cli.auditChange("attest", opts.Reference, desc.Digest.String())
+	return nil
+}
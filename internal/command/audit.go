@@ -0,0 +1,73 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+)
+
+// auditEntry is a line of the audit log, recorded for every operation that
+// changes a registry.
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	Reference string    `json:"reference"`
+	Digest    string    `json:"digest"`
+}
+
+// audit appends an entry to the audit log, when one is configured with
+// KROCTL_AUDIT_LOG. The log is a JSON lines file.
+func (c *CLI) audit(operation, reference, digest string) error {
+	if c.AuditLog == "" {
+		return nil
+	}
+
+	entry := auditEntry{
+		Time:      time.Now().UTC(),
+		Operation: operation,
+		User:      currentUser(),
+		Reference: reference,
+		Digest:    digest,
+	}
+	entry.Host, _ = os.Hostname()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	f, err := os.OpenFile(c.AuditLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	// A single write per entry keeps lines whole when several kroctl
+	// processes append to the same file
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// auditChange records an operation whose change already reached the
+// registry. It cannot be rolled back, so failing to record it is reported
+// as a warning instead of failing a command that succeeded.
+func (c *CLI) auditChange(operation, reference, digest string) {
+	if err := c.audit(operation, reference, digest); err != nil {
+		c.warn("%s of %s succeeded, but %s", operation, reference, err)
+	}
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
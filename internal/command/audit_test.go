@@ -0,0 +1,41 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestAudit(t *testing.T) {
+	cli := NewCLI(view.ViewHuman, &bytes.Buffer{}, view.LogLevelSilent)
+	cli.AuditLog = filepath.Join(t.TempDir(), "audit.jsonl")
+
+	require.NoError(t, cli.audit("push", "ghcr.io/acme/stack:v1", "sha256:abc"))
+	require.NoError(t, cli.audit("split", "ghcr.io/acme/stack/network:v1", "sha256:def"))
+
+	data, err := os.ReadFile(cli.AuditLog)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry auditEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "split", entry.Operation)
+	assert.Equal(t, "ghcr.io/acme/stack/network:v1", entry.Reference)
+	assert.Equal(t, "sha256:def", entry.Digest)
+	assert.NotEmpty(t, entry.User)
+	assert.False(t, entry.Time.IsZero())
+}
+
+func TestAudit_Disabled(t *testing.T) {
+	cli := NewCLI(view.ViewHuman, &bytes.Buffer{}, view.LogLevelSilent)
+	assert.NoError(t, cli.audit("push", "ghcr.io/acme/stack:v1", "sha256:abc"))
+}
@@ -0,0 +1,151 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/diff"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type BreakingOptions struct {
+	Filenames []string
+	Against   string
+	Version   string
+}
+
+func NewBreakingCommand(cli *CLI) *cobra.Command {
+	opts := BreakingOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "breaking",
+		Short: "Detect breaking changes to the APIs of RGDs",
+		Long: "Detect breaking changes to the APIs of RGDs.\n\n" +
+			"Compares the RGDs in the given files against a previous release,\n" +
+			"a reference to an artifact or a local file or directory, and\n" +
+			"reports the changes that break instances or their consumers:\n" +
+			"removed RGDs and fields, type changes, and new required fields\n" +
+			"without a default. Renamed fields show up as a removal. See\n" +
+			"kroctl changelog for all changes.\n\n" +
+			"The command fails when breaking changes are found, so it can gate\n" +
+			"CI. With --version, breaking changes are allowed when the version\n" +
+			"bumps the major version of the --against tag, or the minor\n" +
+			"version before 1.0.0.\n\n" +
+			"Examples:\n" +
+			"  kroctl breaking -f ./rgds/ --against ghcr.io/acme/kro-stack:v1.2.0\n\n" +
+			"  kroctl breaking -f ./rgds/ --against ghcr.io/acme/kro-stack:v1.2.0 --version v2.0.0\n",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunBreaking(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
+		[]string{}, "RGD files or directories to check (required)")
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Previous release to compare against (required)")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Version the files will be released as")
+	_ = cmd.MarkFlagRequired("filenames")
+	_ = cmd.MarkFlagRequired("against")
+
+	return cmd
+}
+
+// allowsBreaking reports whether going from version old to new may break
+// the API. Before 1.0.0 the minor version carries breaking changes.
+func allowsBreaking(old, new semver) bool {
+	if old.Major == 0 && new.Major == 0 {
+		return new.Minor > old.Minor
+	}
+	return new.Major > old.Major
+}
+
+// referenceTag returns the tag of source when it is a tagged reference
+// rather than a local path.
+func referenceTag(source string) string {
+	if _, err := os.Stat(source); err == nil {
+		return ""
+	}
+	ref, err := registry.ParseReference(source)
+	if err != nil || ref.ValidateReferenceAsTag() != nil {
+		return ""
+	}
+	return ref.Reference
+}
+
+func RunBreaking(ctx context.Context, cli *CLI, opts *BreakingOptions) error {
+	var version semver
+	if opts.Version != "" {
+		v, ok := parseSemver(opts.Version)
+		if !ok {
+			return fmt.Errorf("--version %s is not a semantic version", opts.Version)
+		}
+		version = v
+	}
+
+	old, err := loadStackFiles(ctx, cli, opts.Against)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", opts.Against, err)
+	}
+	new, err := readStackFiles(opts.Filenames)
+	if err != nil {
+		return err
+	}
+	changes, err := compareAPIs(old, new)
+	if err != nil {
+		return err
+	}
+
+	var breaking []diff.APIChange
+	for _, c := range changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	report := &api.BreakingReport{
+		TypeMeta: api.NewTypeMeta(api.KindBreakingReport),
+		Against:  opts.Against,
+		Changes:  apiChanges(breaking),
+	}
+
+	var previous semver
+	if len(breaking) > 0 && opts.Version != "" {
+		tag := referenceTag(opts.Against)
+		if v, ok := parseSemver(tag); ok {
+			previous = v
+			report.Allowed = allowsBreaking(previous, version)
+		}
+		if tag == "" {
+			cli.Logger().Warn("Cannot tell the previous version, --against is not tagged with one",
+				"against", opts.Against)
+		}
+	}
+
+	err = render(cli, report, func(s *view.Stream, report *api.BreakingReport) error {
+		for _, c := range breaking {
+			s.Printf("%s\n", c)
+		}
+		switch {
+		case len(breaking) == 0:
+			s.Println("No breaking changes found")
+		case report.Allowed:
+			s.Printf("\n%d breaking change(s), allowed by the version bump from %s to %s\n",
+				len(breaking), previous, version)
+		default:
+			s.Println()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(breaking) > 0 && !report.Allowed {
+		return fmt.Errorf("found %d breaking change(s)", len(breaking))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowsBreaking(t *testing.T) {
+	for _, tc := range []struct {
+		old, new string
+		want     bool
+	}{
+		{"v1.2.0", "v2.0.0", true},
+		{"v1.2.0", "v1.3.0", false},
+		{"v1.2.0", "v1.2.1", false},
+		{"v0.2.0", "v0.3.0", true},
+		{"v0.2.0", "v0.2.1", false},
+		{"v0.2.0", "v1.0.0", true},
+	} {
+		old, _ := parseSemver(tc.old)
+		new, _ := parseSemver(tc.new)
+		assert.Equal(t, tc.want, allowsBreaking(old, new), "%s -> %s", tc.old, tc.new)
+	}
+}
+
+func TestReferenceTag(t *testing.T) {
+	assert.Equal(t, "v1.2.0", referenceTag("ghcr.io/acme/kro-stack:v1.2.0"))
+	assert.Empty(t, referenceTag("ghcr.io/acme/kro-stack"))
+	assert.Empty(t, referenceTag(t.TempDir()))
+}
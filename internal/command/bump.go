@@ -0,0 +1,130 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// Version levels accepted by bump.
+const (
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+type BumpOptions struct {
+	Level      string
+	Repository string
+	Filenames  []string
+}
+
+func NewBumpCommand(cli *CLI) *cobra.Command {
+	opts := BumpOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "bump <patch|minor|major> <repository>",
+		Short: "Compute the next version of a repository and push it",
+		Long: "Compute the next version of a repository and push it.\n\n" +
+			"Lists the semantic version tags of the repository, such as v1.2.3,\n" +
+			"and increments the latest release. Prerelease tags are ignored. A\n" +
+			"repository without version tags starts from 0.0.0, and the next\n" +
+			"version keeps the v prefix of the latest tag.\n\n" +
+			"With -f, the files are pushed under the next version, as kroctl\n" +
+			"push does. Otherwise the next version is only printed.\n\n" +
+			"Examples:\n" +
+			"  kroctl bump patch ghcr.io/acme/kro-stack\n\n" +
+			"  kroctl bump minor ghcr.io/acme/kro-stack -f ./rgds/\n",
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{BumpPatch, BumpMinor, BumpMajor},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Level = args[0]
+			opts.Repository = args[1]
+			return RunBump(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
+		[]string{}, "RGD files or directories to push under the next version")
+
+	return cmd
+}
+
+// nextVersion increments v at the given level.
+func nextVersion(v semver, level string) (semver, error) {
+	next := semver{Prefix: v.Prefix}
+	switch level {
+	case BumpPatch:
+		next.Major, next.Minor, next.Patch = v.Major, v.Minor, v.Patch+1
+	case BumpMinor:
+		next.Major, next.Minor = v.Major, v.Minor+1
+	case BumpMajor:
+		next.Major = v.Major + 1
+	default:
+		return semver{}, fmt.Errorf("unknown level %q, expected %s, %s, or %s", level, BumpPatch, BumpMinor, BumpMajor)
+	}
+	return next, nil
+}
+
+func RunBump(ctx context.Context, cli *CLI, opts *BumpOptions) error {
+	// Fail on a typo before talking to the registry
+	if _, err := nextVersion(semver{}, opts.Level); err != nil {
+		return err
+	}
+
+	repo, err := oci.SetupRepository(opts.Repository)
+	if err != nil {
+		return err
+	}
+	if repo.Reference.Reference != "" {
+		return fmt.Errorf("expected a repository without a tag or digest, got %s", opts.Repository)
+	}
+
+	tags, err := oci.ListTags(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	result := &api.BumpResult{
+		TypeMeta:   api.NewTypeMeta(api.KindBumpResult),
+		Repository: opts.Repository,
+	}
+	current, ok := latestSemver(tags, false)
+	if ok {
+		result.Current = current.String()
+	} else {
+		current = semver{Prefix: "v"}
+	}
+	next, err := nextVersion(current, opts.Level)
+	if err != nil {
+		return err
+	}
+	result.Next = next.String()
+
+	// With files, machine-readable output is the result of the push
+	if len(opts.Filenames) == 0 || !cli.IsStructured() {
+		err := render(cli, result, func(s *view.Stream, result *api.BumpResult) error {
+			current := result.Current
+			if current == "" {
+				current = "none"
+			}
+			s.Printf("Current:  %s\n", current)
+			s.Printf("Next:     %s\n", result.Next)
+			return nil
+		})
+		if err != nil || len(opts.Filenames) == 0 {
+			return err
+		}
+		cli.Println()
+	}
+
+	return RunPush(ctx, cli, &PushOptions{
+		Filenames: opts.Filenames,
+		Reference: opts.Repository + ":" + next.String(),
+	})
+}
@@ -0,0 +1,165 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/diff"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type ChangelogOptions struct {
+	From     string
+	To       string
+	Markdown bool
+}
+
+func NewChangelogCommand(cli *CLI) *cobra.Command {
+	opts := ChangelogOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "changelog <from> <to>",
+		Short: "Summarize the API changes between two RGD stacks",
+		Long: "Summarize the API changes between two RGD stacks.\n\n" +
+			"Each side is a local file or directory, or a reference to an\n" +
+			"artifact in a registry, as for kroctl diff. RGDs are matched by\n" +
+			"name and compared as consumers see them: added and removed RGDs,\n" +
+			"changes to the spec and status fields of their instance API, and\n" +
+			"changes to the resources they create.\n\n" +
+			"Removed RGDs and fields, type changes, and new required fields\n" +
+			"without a default are marked as breaking.\n\n" +
+			"With --markdown, the changelog is written as markdown for release\n" +
+			"notes.\n\n" +
+			"Examples:\n" +
+			"  kroctl changelog ghcr.io/acme/kro-stack:v1.0.0 ghcr.io/acme/kro-stack:v1.1.0\n\n" +
+			"  kroctl changelog ghcr.io/acme/kro-stack:v1.0.0 ./rgds/ --markdown\n",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.From = args[0]
+			opts.To = args[1]
+			return RunChangelog(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Markdown, "markdown", false, "Write the changelog as markdown")
+
+	return cmd
+}
+
+// loadAPIChanges compares the RGDs of two stacks, each read from disk or a
+// registry.
+func loadAPIChanges(ctx context.Context, cli *CLI, from, to string) ([]diff.APIChange, error) {
+	old, err := loadStackFiles(ctx, cli, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", from, err)
+	}
+	new, err := loadStackFiles(ctx, cli, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", to, err)
+	}
+	return compareAPIs(old, new)
+}
+
+// compareAPIs parses the files of two stacks and compares their RGDs.
+func compareAPIs(oldFiles, newFiles []stackFile) ([]diff.APIChange, error) {
+	old, err := parseStackFiles(oldFiles)
+	if err != nil {
+		return nil, err
+	}
+	new, err := parseStackFiles(newFiles)
+	if err != nil {
+		return nil, err
+	}
+	return diff.APIs(old, new), nil
+}
+
+func RunChangelog(ctx context.Context, cli *CLI, opts *ChangelogOptions) error {
+	if opts.Markdown && cli.IsStructured() {
+		return errHumanOnly("--markdown")
+	}
+
+	changes, err := loadAPIChanges(ctx, cli, opts.From, opts.To)
+	if err != nil {
+		return err
+	}
+
+	if opts.Markdown {
+		printMarkdownChangelog(cli, opts.From, opts.To, changes)
+		return nil
+	}
+
+	changelog := &api.Changelog{
+		TypeMeta: api.NewTypeMeta(api.KindChangelog),
+		From:     opts.From,
+		To:       opts.To,
+		Changes:  apiChanges(changes),
+	}
+	for _, c := range changes {
+		if c.Breaking {
+			changelog.Breaking++
+		}
+	}
+
+	return render(cli, changelog, func(s *view.Stream, changelog *api.Changelog) error {
+		if len(changes) == 0 {
+			s.Println("No API changes found")
+			return nil
+		}
+		for _, c := range changes {
+			s.Printf("%s\n", c)
+		}
+		s.Printf("\n%d change(s), %d breaking\n", len(changes), changelog.Breaking)
+		return nil
+	})
+}
+
+// apiChanges converts API changes to their machine-readable form.
+func apiChanges(changes []diff.APIChange) []api.APIChange {
+	out := make([]api.APIChange, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, api.APIChange{
+			RGD:      c.RGD,
+			Change:   string(c.Kind),
+			Subject:  c.Subject,
+			Path:     c.Path,
+			Detail:   c.Detail,
+			Breaking: c.Breaking,
+		})
+	}
+	return out
+}
+
+// printMarkdownChangelog writes the changes as release notes, breaking
+// changes first.
+func printMarkdownChangelog(cli *CLI, from, to string, changes []diff.APIChange) {
+	quote := func(s string) string { return "`" + s + "`" }
+
+	cli.Printf("## Changes from %s to %s\n", quote(from), quote(to))
+	if len(changes) == 0 {
+		cli.Printf("\nNo API changes.\n")
+		return
+	}
+
+	for _, section := range []struct {
+		title    string
+		breaking bool
+	}{
+		{"Breaking changes", true},
+		{"Changes", false},
+	} {
+		var printed bool
+		for _, c := range changes {
+			if c.Breaking != section.breaking {
+				continue
+			}
+			if !printed {
+				cli.Printf("\n### %s\n\n", section.title)
+				printed = true
+			}
+			cli.Printf("- **%s**: %s\n", c.RGD, c.Message(quote))
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/command"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+const changelogRGD = `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: app
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: App
+    spec:
+`
+
+func writeChangelogStack(t *testing.T, fields string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(changelogRGD+fields), 0o644))
+	return path
+}
+
+func TestRunChangelog_Markdown(t *testing.T) {
+	from := writeChangelogStack(t, "      name: string\n      size: string\n")
+	to := writeChangelogStack(t, "      name: string\n      replicas: integer | default=1\n")
+
+	buf := new(bytes.Buffer)
+	cli := command.NewCLI(view.ViewHuman, buf, view.LogLevelSilent)
+
+	err := command.RunChangelog(context.Background(), cli, &command.ChangelogOptions{
+		From: from, To: to, Markdown: true,
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "### Breaking changes\n\n- **app**: removed spec field `spec.size`\n")
+	assert.Contains(t, out, "### Changes\n\n- **app**: added spec field `spec.replicas`: integer\n")
+}
+
+func TestRunChangelog_NoChanges(t *testing.T) {
+	stack := writeChangelogStack(t, "      name: string\n")
+
+	buf := new(bytes.Buffer)
+	cli := command.NewCLI(view.ViewHuman, buf, view.LogLevelSilent)
+
+	err := command.RunChangelog(context.Background(), cli, &command.ChangelogOptions{From: stack, To: stack})
+	require.NoError(t, err)
+	assert.Equal(t, "No API changes found\n", buf.String())
+}
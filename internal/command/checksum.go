@@ -0,0 +1,86 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// checksumsFile is the name of the checksum layer, in the format written
+// by sha256sum.
+const checksumsFile = "SHA256SUMS"
+
+// formatChecksums writes the SHA-256 checksums of files, keyed by file name,
+// in sha256sum format sorted by name.
+func formatChecksums(sums map[string]string) []byte {
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[name], name)
+	}
+	return buf.Bytes()
+}
+
+// parseChecksums reads checksums in sha256sum format.
+func parseChecksums(data []byte) (map[string]string, error) {
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		// sha256sum marks binary mode with a * before the name
+		sum, name, ok := strings.Cut(text, " ")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+		if !ok || len(sum) != sha256.Size*2 || name == "" {
+			return nil, fmt.Errorf("%s:%d: malformed checksum line", checksumsFile, line)
+		}
+		sums[name] = strings.ToLower(sum)
+	}
+	return sums, scanner.Err()
+}
+
+// verifyChecksums checks every file against the checksum list. Files that
+// are missing from either side are reported as well.
+func verifyChecksums(files []stackFile, checksums []byte) error {
+	sums, err := parseChecksums(checksums)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		seen[f.Name] = true
+		expected, ok := sums[f.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s has no checksum", f.Name))
+			continue
+		}
+		sum := sha256.Sum256(f.Content)
+		if actual := hex.EncodeToString(sum[:]); actual != expected {
+			problems = append(problems, fmt.Sprintf("%s has checksum %s, expected %s", f.Name, actual, expected))
+		}
+	}
+	for name := range sums {
+		if !seen[name] {
+			problems = append(problems, fmt.Sprintf("%s is listed but missing", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("checksum verification failed:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
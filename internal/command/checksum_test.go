@@ -0,0 +1,53 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFormatChecksums(t *testing.T) {
+	sums := map[string]string{"vpc.yaml": "bb", "stack.yaml": "aa"}
+	assert.Equal(t, "aa  stack.yaml\nbb  vpc.yaml\n", string(formatChecksums(sums)))
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := sha256Hex("x")
+	sums, err := parseChecksums([]byte(sum + "  a.yaml\n\n" + sum + " *b.yaml\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a.yaml": sum, "b.yaml": sum}, sums)
+
+	_, err = parseChecksums([]byte("abc  a.yaml\n"))
+	assert.EqualError(t, err, "SHA256SUMS:1: malformed checksum line")
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	files := []stackFile{
+		{Name: "a.yaml", Content: []byte("a")},
+		{Name: "b.yaml", Content: []byte("b")},
+	}
+	sums := formatChecksums(map[string]string{"a.yaml": sha256Hex("a"), "b.yaml": sha256Hex("b")})
+	assert.NoError(t, verifyChecksums(files, sums))
+}
+
+func TestVerifyChecksums_Mismatch(t *testing.T) {
+	files := []stackFile{
+		{Name: "a.yaml", Content: []byte("tampered")},
+		{Name: "c.yaml", Content: []byte("c")},
+	}
+	sums := formatChecksums(map[string]string{"a.yaml": sha256Hex("a"), "b.yaml": sha256Hex("b")})
+
+	err := verifyChecksums(files, sums)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a.yaml has checksum "+sha256Hex("tampered")+", expected "+sha256Hex("a"))
+	assert.Contains(t, err.Error(), "b.yaml is listed but missing")
+	assert.Contains(t, err.Error(), "c.yaml has no checksum")
+}
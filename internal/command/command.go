@@ -3,10 +3,11 @@ package command
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 
 	"github.com/bschaatsbergen/kroctl/internal/view"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -17,11 +18,36 @@ type CLI struct {
 	view.Viewer
 	*view.Stream
 	Context string
+	// AuditLog is the file operations that change a registry are recorded
+	// in, empty when auditing is off
+	AuditLog string
+	// GitHubActions is set in GitHub Actions workflows, where findings are
+	// reported as workflow annotations
+	GitHubActions bool
+	// GitHubOutput is the file step outputs are written to, from
+	// GITHUB_OUTPUT
+	GitHubOutput string
+	// Format is the Go template results are printed with, from --format
+	Format string
+	// Notify are the URLs push events are posted to, from KROCTL_NOTIFY
+	Notify []string
+	// MetricsFile is the file the metrics of the invocation are written
+	// to, empty when they are not collected
+	MetricsFile string
+
+	metrics metrics
 }
 
 // highlight applies a blue color to the given format and arguments.
 func highlight(format string, a ...any) string {
-	return color.RGB(50, 108, 229).Sprintf(format, a...)
+	return view.Accent.Sprintf(format, a...)
+}
+
+// warn tells the user about something that needs their attention. Unlike
+// log warnings it is printed at every log level, on stderr so it stays out
+// of structured output.
+func (c *CLI) warn(format string, a ...any) {
+	fmt.Fprintln(os.Stderr, view.Caution.Sprintf("Warning: "+format, a...))
 }
 
 func NewCLI(vt view.ViewType, w io.Writer, logLevel view.LogLevel) *CLI {
@@ -82,3 +108,76 @@ func MaxArgs(number int) cobra.PositionalArgs {
 		return fmt.Errorf("expected at most %d arguments, got %d", number, len(args))
 	}
 }
+
+// collectYAMLFiles expands the given files and directories into the list of
+// YAML files they contain. Directories are walked recursively.
+func collectYAMLFiles(filenames []string) ([]string, error) {
+	var allFiles []string
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to access %s: %w", filename, err)
+		}
+
+		if info.IsDir() {
+			err := filepath.Walk(filename, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				ext := filepath.Ext(path)
+				if !info.IsDir() && (ext == ".yaml" || ext == ".yml") {
+					allFiles = append(allFiles, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk directory %s: %w", filename, err)
+			}
+		} else {
+			allFiles = append(allFiles, filename)
+		}
+	}
+
+	if len(allFiles) == 0 {
+		return nil, fmt.Errorf("no YAML files found in specified paths")
+	}
+
+	return allFiles, nil
+}
+
+// formatSize formats a byte count using binary units, e.g. 1.5 KiB.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// IsJSON reports whether output should be machine-readable JSON.
+func (c *CLI) IsJSON() bool {
+	return c.ViewType() == view.ViewJSON
+}
+
+// IsStructured reports whether results are printed as data, as JSON, YAML,
+// or with a --format template, rather than for humans.
+func (c *CLI) IsStructured() bool {
+	return c.ViewType() != view.ViewHuman || c.Format != ""
+}
+
+// errHumanOnly is returned when a machine-readable format is requested from
+// what writes a document of its own, such as manifests or markdown.
+func errHumanOnly(what string) error {
+	return fmt.Errorf("%s writes no machine-readable output, --json, --yaml, and --format are not supported", what)
+}
+
+// render prints the result of a command in the selected output format,
+// using human for the human view.
+func render[T any](cli *CLI, result T, human view.RendererFunc[T]) error {
+	return view.NewRenderer(cli.ViewType(), cli.Format, view.Renderer[T](human)).Render(cli.Stream, result)
+}
@@ -25,6 +25,13 @@ func TestNewCLI_WithJSONView(t *testing.T) {
 	assert.Equal(t, &bytes.Buffer{}, cli.Writer)
 }
 
+func TestNewCLI_WithYAMLView(t *testing.T) {
+	cli := command.NewCLI(view.ViewYAML, &bytes.Buffer{}, view.LogLevelSilent)
+	assert.IsType(t, &view.YAMLView{}, cli.Viewer)
+	assert.True(t, cli.IsStructured())
+	assert.False(t, cli.IsJSON())
+}
+
 func TestExactArgs_ExactMatch(t *testing.T) {
 	fn := command.ExactArgs(2)
 	err := fn(nil, []string{"a", "b"})
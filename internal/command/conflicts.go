@@ -0,0 +1,232 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/diff"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// Types of conflicts between stacks.
+const (
+	ConflictTypeRGD = "rgd"
+	ConflictTypeAPI = "api"
+)
+
+type ConflictsOptions struct {
+	Sources []string
+}
+
+func NewConflictsCommand(cli *CLI) *cobra.Command {
+	opts := ConflictsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "conflicts <reference|path> <reference|path>...",
+		Short: "Report RGD names and APIs defined by more than one stack",
+		Long: "Report RGD names and APIs defined by more than one stack.\n\n" +
+			"Stacks installed side by side overwrite each other when they\n" +
+			"contain an RGD of the same name, or RGDs that define the same\n" +
+			"kind in the same group, as they share a single CRD. Each stack is\n" +
+			"a reference or a local file or directory.\n\n" +
+			"For every collision the differences between the schemas are\n" +
+			"listed, and it is marked incompatible when one definition would\n" +
+			"break instances of another, such as by removing a field.\n\n" +
+			"The command fails when stacks conflict, so it can guard an\n" +
+			"install in CI.\n\n" +
+			"Examples:\n" +
+			"  kroctl conflicts ghcr.io/acme/network:v1.0.0 ghcr.io/other/network:v2.0.0\n\n" +
+			"  kroctl conflicts ./rgds/ ghcr.io/acme/platform:v3.1.0 --json\n",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Sources = args
+			return RunConflicts(cmd.Context(), cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunConflicts(ctx context.Context, cli *CLI, opts *ConflictsOptions) error {
+	stacks := make([][]definedRGD, 0, len(opts.Sources))
+	for i, source := range opts.Sources {
+		files, err := loadStackFiles(ctx, cli, source)
+		if err != nil {
+			return err
+		}
+		defs, err := defineRGDs(i, source, files)
+		if err != nil {
+			return err
+		}
+		stacks = append(stacks, defs)
+	}
+
+	report := &api.ConflictReport{
+		TypeMeta:  api.NewTypeMeta(api.KindConflictReport),
+		Stacks:    opts.Sources,
+		Conflicts: findConflicts(stacks),
+	}
+
+	err := render(cli, report, func(s *view.Stream, report *api.ConflictReport) error {
+		if len(report.Conflicts) == 0 {
+			s.Printf("No conflicts between %d stack(s)\n", len(report.Stacks))
+			return nil
+		}
+		for i, c := range report.Conflicts {
+			if i > 0 {
+				s.Println()
+			}
+			switch c.Type {
+			case ConflictTypeRGD:
+				s.Printf("RGD %s is defined by:\n", highlight("%s", c.Name))
+			case ConflictTypeAPI:
+				s.Printf("API %s is defined by:\n", highlight("%s", c.Name))
+			}
+			for _, d := range c.Definitions {
+				s.Printf("  %s (%s in %s)\n", d.Stack, d.RGD, d.File)
+			}
+			switch {
+			case !c.Compatible:
+				s.Println(view.Caution.Sprintf("  Incompatible schemas:"))
+			case len(c.Differences) > 0:
+				s.Println("  Compatible schemas:")
+			default:
+				s.Println("  Identical definitions")
+			}
+			for _, d := range c.Differences {
+				s.Printf("    %s\n", d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if n := len(report.Conflicts); n > 0 {
+		return fmt.Errorf("%d conflict(s) between stacks", n)
+	}
+	return nil
+}
+
+// definedRGD is an RGD of a stack, with where it came from.
+type definedRGD struct {
+	Stack string
+	File  string
+	RGD   *rgd.ResourceGraphDefinition
+	// index is the position of the stack in the arguments
+	index int
+}
+
+func defineRGDs(index int, stack string, files []stackFile) ([]definedRGD, error) {
+	var defs []definedRGD
+	for _, file := range files {
+		rgds, err := rgd.Parse(file.Name, file.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name, err)
+		}
+		for _, r := range rgds {
+			defs = append(defs, definedRGD{Stack: stack, File: file.Name, RGD: r, index: index})
+		}
+	}
+	return defs, nil
+}
+
+// findConflicts returns the RGD names and APIs that more than one stack
+// defines. An API defined under the same RGD name everywhere is only
+// reported as an RGD conflict.
+func findConflicts(stacks [][]definedRGD) []api.Conflict {
+	byName := map[string][]definedRGD{}
+	byAPI := map[string][]definedRGD{}
+	var names, apis []string
+	for i, defs := range stacks {
+		for _, d := range defs {
+			name := d.RGD.Metadata.Name
+			if !definedIn(byName[name], i) {
+				if byName[name] == nil {
+					names = append(names, name)
+				}
+				byName[name] = append(byName[name], d)
+			}
+
+			s := d.RGD.Spec.Schema
+			if s == nil || s.Kind == "" {
+				continue
+			}
+			group := s.Group
+			if group == "" {
+				group = rgd.Group
+			}
+			key := s.Kind + "." + group
+			if !definedIn(byAPI[key], i) {
+				if byAPI[key] == nil {
+					apis = append(apis, key)
+				}
+				byAPI[key] = append(byAPI[key], d)
+			}
+		}
+	}
+
+	conflicts := []api.Conflict{}
+	slices.Sort(names)
+	for _, name := range names {
+		if defs := byName[name]; len(defs) > 1 {
+			conflicts = append(conflicts, conflict(ConflictTypeRGD, name, defs))
+		}
+	}
+	slices.Sort(apis)
+	for _, key := range apis {
+		defs := byAPI[key]
+		if len(defs) < 2 {
+			continue
+		}
+		sameName := true
+		for _, d := range defs[1:] {
+			sameName = sameName && d.RGD.Metadata.Name == defs[0].RGD.Metadata.Name
+		}
+		if !sameName {
+			conflicts = append(conflicts, conflict(ConflictTypeAPI, key, defs))
+		}
+	}
+	return conflicts
+}
+
+// definedIn reports whether one of defs came from the stack at index i.
+// Duplicates within a stack are for validate to report.
+func definedIn(defs []definedRGD, i int) bool {
+	return slices.ContainsFunc(defs, func(d definedRGD) bool { return d.index == i })
+}
+
+// conflict compares every definition with the first. RGDs of the same
+// name are compared in full, others by the API they define.
+func conflict(kind, name string, defs []definedRGD) api.Conflict {
+	c := api.Conflict{Type: kind, Name: name, Compatible: true}
+	first := defs[0]
+	for _, d := range defs {
+		c.Definitions = append(c.Definitions, api.Definition{Stack: d.Stack, RGD: d.RGD.Metadata.Name, File: d.File})
+	}
+	for _, d := range defs[1:] {
+		compare := func(a, b *rgd.ResourceGraphDefinition) []diff.APIChange {
+			if a.Metadata.Name == b.Metadata.Name {
+				return diff.APIs([]*rgd.ResourceGraphDefinition{a}, []*rgd.ResourceGraphDefinition{b})
+			}
+			return diff.Schemas(a, b)
+		}
+		changes := compare(first.RGD, d.RGD)
+		for _, change := range changes {
+			c.Differences = append(c.Differences, d.Stack+": "+change.String())
+		}
+		// Either stack may be applied last
+		for _, change := range slices.Concat(changes, compare(d.RGD, first.RGD)) {
+			if change.Breaking {
+				c.Compatible = false
+			}
+		}
+	}
+	return c
+}
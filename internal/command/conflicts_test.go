@@ -0,0 +1,62 @@
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conflictRGD returns an RGD file whose schema has the given kind and spec
+// fields.
+func conflictRGD(name, kind, spec string) stackFile {
+	return stackFile{Name: name + ".yaml", Content: schemaRGD(name, kind, spec)}
+}
+
+func TestFindConflicts(t *testing.T) {
+	a, err := defineRGDs(0, "a", []stackFile{
+		conflictRGD("network", "Network", "      cidr: string"),
+		conflictRGD("app", "App", "      image: string"),
+	})
+	require.NoError(t, err)
+	b, err := defineRGDs(1, "b", []stackFile{
+		// Same name and API, same schema
+		conflictRGD("network", "Network", "      cidr: string"),
+		// Same API under another name, without the image field
+		conflictRGD("webapp", "App", "      name: string"),
+		conflictRGD("db", "Database", "      size: string"),
+	})
+	require.NoError(t, err)
+
+	conflicts := findConflicts([][]definedRGD{a, b})
+	require.Len(t, conflicts, 2)
+
+	assert.Equal(t, ConflictTypeRGD, conflicts[0].Type)
+	assert.Equal(t, "network", conflicts[0].Name)
+	assert.True(t, conflicts[0].Compatible)
+	assert.Empty(t, conflicts[0].Differences)
+
+	// Network.kro.run shares the RGD name and is not reported again
+	assert.Equal(t, ConflictTypeAPI, conflicts[1].Type)
+	assert.Equal(t, "App.kro.run", conflicts[1].Name)
+	assert.False(t, conflicts[1].Compatible)
+	assert.NotEmpty(t, conflicts[1].Differences)
+	require.Len(t, conflicts[1].Definitions, 2)
+	assert.Equal(t, "webapp", conflicts[1].Definitions[1].RGD)
+}
+
+func TestFindConflicts_None(t *testing.T) {
+	a, err := defineRGDs(0, "a", []stackFile{conflictRGD("network", "Network", "      cidr: string")})
+	require.NoError(t, err)
+	b, err := defineRGDs(1, "b", []stackFile{conflictRGD("db", "Database", "      size: string")})
+	require.NoError(t, err)
+
+	conflicts := findConflicts([][]definedRGD{a, b})
+	assert.Empty(t, conflicts)
+
+	// The schema requires the list, an empty result must not encode as null
+	data, err := json.Marshal(conflicts)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(data))
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type DeprecateOptions struct {
+	Reference string
+	Message   string
+	Variant   string
+}
+
+func NewDeprecateCommand(cli *CLI) *cobra.Command {
+	opts := DeprecateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "deprecate <reference>",
+		Short: "Mark an RGD stack as deprecated",
+		Long: "Mark an RGD stack as deprecated.\n\n" +
+			"Attaches a deprecation to the stack as a referrer artifact, with a\n" +
+			"message telling consumers what to use instead. The stack stays in\n" +
+			"the registry and the tag is left alone.\n\n" +
+			"Inspect shows the message prominently, and commands that pull the\n" +
+			"stack warn about it. Export refuses deprecated stacks unless\n" +
+			"--allow-deprecated is given.\n\n" +
+			"Examples:\n" +
+			"  kroctl deprecate ghcr.io/acme/kro-stack:v1.2.0 --message \"use v2 stacks\"\n\n" +
+			"  kroctl export helm ghcr.io/acme/kro-stack:v1.2.0 --allow-deprecated\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunDeprecate(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Message, "message", "m", "", "What consumers should use instead (required)")
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Variant to deprecate when the reference is an index of variants")
+	_ = cmd.MarkFlagRequired("message")
+
+	return cmd
+}
+
+func RunDeprecate(ctx context.Context, cli *CLI, opts *DeprecateOptions) error {
+	if opts.Message == "" {
+		return fmt.Errorf("a deprecation needs a message, use --message to tell consumers what to use instead")
+	}
+
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	desc, manifest, err := oci.FetchManifest(ctx, repo, opts.Reference, opts.Variant)
+	if err != nil {
+		return err
+	}
+	if !oci.IsStack(manifest) {
+		return fmt.Errorf("%s is not a kro RGD stack", opts.Reference)
+	}
+
+	deprecation, err := oci.Deprecate(ctx, repo, desc, opts.Message)
+	if err != nil {
+		return err
+	}
+
+	result := &api.DeprecateResult{
+		TypeMeta:    api.NewTypeMeta(api.KindDeprecateResult),
+		Reference:   opts.Reference,
+		Digest:      desc.Digest.String(),
+		Deprecation: deprecation.Digest.String(),
+		Message:     opts.Message,
+	}
+	err = render(cli, result, func(s *view.Stream, result *api.DeprecateResult) error {
+		s.Printf("Deprecated %s@%s\n", result.Reference, result.Digest)
+		s.Printf("Deprecation: %s\n", result.Deprecation)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cli.auditChange("deprecate", opts.Reference, desc.Digest.String())
+	return nil
+}
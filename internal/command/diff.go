@@ -0,0 +1,153 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/diff"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type DiffOptions struct {
+	Source   string
+	Target   string
+	Semantic bool
+}
+
+func NewDiffCommand(cli *CLI) *cobra.Command {
+	opts := DiffOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "diff <source> <target>",
+		Short: "Show differences between two RGD stacks",
+		Long: "Show differences between two RGD stacks.\n\n" +
+			"Each side is a local file or directory, or a reference to an\n" +
+			"artifact in a registry. Files are matched by name and compared\n" +
+			"as a unified text diff.\n\n" +
+			"With --semantic, files are parsed as YAML and compared\n" +
+			"structurally, ignoring key order, comments, and formatting.\n" +
+			"Changes are reported per field path. List items with an id or\n" +
+			"name are matched by it, so reordering resources is not a change.\n\n" +
+			"Examples:\n" +
+			"  kroctl diff localhost:5001/kro-stack-network:v1.0.0 ./rgds/\n\n" +
+			"  kroctl diff --semantic ghcr.io/acme/kro-stack:v1 ghcr.io/acme/kro-stack:v2\n",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Source = args[0]
+			opts.Target = args[1]
+			return RunDiff(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Semantic, "semantic", false, "Compare parsed YAML field by field instead of text")
+
+	return cmd
+}
+
+func RunDiff(ctx context.Context, cli *CLI, opts *DiffOptions) error {
+	source, err := loadStackFiles(ctx, cli, opts.Source)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", opts.Source, err)
+	}
+	target, err := loadStackFiles(ctx, cli, opts.Target)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", opts.Target, err)
+	}
+
+	sourceFiles := map[string][]byte{}
+	for _, f := range source {
+		sourceFiles[f.Name] = f.Content
+	}
+	targetFiles := map[string][]byte{}
+	for _, f := range target {
+		targetFiles[f.Name] = f.Content
+	}
+
+	// Walk files in source order, then the ones only the target has
+	names := make([]string, 0, len(source)+len(target))
+	for _, f := range source {
+		names = append(names, f.Name)
+	}
+	for _, f := range target {
+		if _, ok := sourceFiles[f.Name]; !ok {
+			names = append(names, f.Name)
+		}
+	}
+
+	report := &api.DiffReport{
+		TypeMeta: api.NewTypeMeta(api.KindDiffReport),
+		Source:   opts.Source,
+		Target:   opts.Target,
+		Files:    []api.FileDiff{},
+	}
+	for _, name := range names {
+		a, inSource := sourceFiles[name]
+		b, inTarget := targetFiles[name]
+
+		file := api.FileDiff{Name: name, Status: string(diff.Modified)}
+		switch {
+		case !inSource:
+			file.Status = string(diff.Added)
+		case !inTarget:
+			file.Status = string(diff.Removed)
+		}
+
+		if opts.Semantic {
+			if inSource && inTarget {
+				changes, err := diff.Semantic(a, b)
+				if err != nil {
+					return fmt.Errorf("failed to compare %s: %w", name, err)
+				}
+				if len(changes) == 0 {
+					continue
+				}
+				for _, c := range changes {
+					file.Changes = append(file.Changes, c.String())
+				}
+			}
+			report.Files = append(report.Files, file)
+			continue
+		}
+
+		nameA, nameB := "a/"+name, "b/"+name
+		if !inSource {
+			nameA = "/dev/null"
+		}
+		if !inTarget {
+			nameB = "/dev/null"
+		}
+		if file.Diff = diff.Unified(nameA, nameB, string(a), string(b)); file.Diff != "" {
+			report.Files = append(report.Files, file)
+		}
+	}
+
+	return render(cli, report, printDiff)
+}
+
+// printDiff prints unified diffs as they are, and semantic changes below
+// the name of their file, prefixed with +, - or ~.
+func printDiff(s *view.Stream, report *api.DiffReport) error {
+	if len(report.Files) == 0 {
+		s.Println("No differences found")
+		return nil
+	}
+	prefix := map[string]string{
+		string(diff.Added):    "+",
+		string(diff.Removed):  "-",
+		string(diff.Modified): "~",
+	}
+	for _, file := range report.Files {
+		if file.Diff != "" {
+			s.Printf("%s", file.Diff)
+			continue
+		}
+		s.Printf("%s %s\n", prefix[file.Status], file.Name)
+		for _, c := range file.Changes {
+			s.Printf("  %s\n", c)
+		}
+	}
+	return nil
+}
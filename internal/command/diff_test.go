@@ -0,0 +1,50 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// schemaRGD returns an RGD whose schema has the given kind and spec fields.
+func schemaRGD(name, kind, spec string) []byte {
+	return fmt.Appendf(nil, `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: %s
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: %s
+    spec:
+%s
+`, name, kind, spec)
+}
+
+func TestRunDiff_JSON(t *testing.T) {
+	source, target := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(source, "network.yaml"), schemaRGD("network", "Network", "      cidr: string"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "app.yaml"), schemaRGD("app", "App", "      image: string"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(target, "app.yaml"), schemaRGD("app", "App", "      image: string\n      port: integer"), 0o644))
+
+	var out bytes.Buffer
+	cli := NewCLI(view.ViewJSON, &out, view.LogLevelSilent)
+	require.NoError(t, RunDiff(t.Context(), cli, &DiffOptions{Source: source, Target: target, Semantic: true}))
+
+	var report api.DiffReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, "app.yaml", report.Files[0].Name)
+	assert.Equal(t, "modified", report.Files[0].Status)
+	assert.NotEmpty(t, report.Files[0].Changes)
+	assert.Equal(t, api.FileDiff{Name: "network.yaml", Status: "removed"}, report.Files[1])
+}
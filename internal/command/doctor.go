@@ -0,0 +1,217 @@
+package command
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// pingTimeout bounds the connectivity check of a single registry.
+const pingTimeout = 10 * time.Second
+
+type DoctorOptions struct {
+	Registries []string
+}
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "pass"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+)
+
+// doctorCheck is the outcome of a single diagnostic.
+type doctorCheck struct {
+	Name   string
+	Status checkStatus
+	Detail string
+}
+
+func NewDoctorCommand(cli *CLI) *cobra.Command {
+	opts := DoctorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor [registry...]",
+		Short: "Check the local environment for common problems",
+		Long: "Check the local environment for common problems.\n\n" +
+			"Validates the Docker config file kroctl reads credentials from,\n" +
+			"checks that the credential helpers it configures are installed,\n" +
+			"reports proxy settings, and checks that each registry is reachable\n" +
+			"over TLS and accepts the configured credentials.\n\n" +
+			"Registries default to the ones the Docker config file has\n" +
+			"credentials for. Each check reports pass, warn, or fail; the\n" +
+			"command fails when any check fails.\n\n" +
+			"Examples:\n" +
+			"  kroctl doctor\n\n" +
+			"  kroctl doctor ghcr.io localhost:5001\n",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Registries = args
+			return RunDoctor(cmd.Context(), cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunDoctor(ctx context.Context, cli *CLI, opts *DoctorOptions) error {
+	var checks []doctorCheck
+
+	config, err := oci.ReadDockerConfig()
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{"Docker config", checkFail, err.Error()})
+	case !config.Exists:
+		checks = append(checks, doctorCheck{"Docker config", checkWarn,
+			fmt.Sprintf("%s not found, only anonymous access is possible", config.Path)})
+	default:
+		checks = append(checks, doctorCheck{"Docker config", checkPass, config.Path})
+		for _, helper := range config.Helpers {
+			checks = append(checks, checkCredentialHelper(helper))
+		}
+	}
+
+	checks = append(checks, checkProxy())
+
+	registries := opts.Registries
+	if len(registries) == 0 && config != nil {
+		registries = config.Registries
+	}
+	for _, registry := range registries {
+		checks = append(checks, checkRegistry(ctx, cli, registry))
+	}
+
+	report := &api.DoctorReport{
+		TypeMeta: api.NewTypeMeta(api.KindDoctorReport),
+		Checks:   make([]api.Check, 0, len(checks)),
+	}
+	for _, c := range checks {
+		report.Checks = append(report.Checks, api.Check{Name: c.Name, Status: string(c.Status), Detail: c.Detail})
+		switch c.Status {
+		case checkFail:
+			report.Failed++
+		case checkWarn:
+			report.Warnings++
+		default:
+			report.Passed++
+		}
+	}
+
+	err = render(cli, report, func(s *view.Stream, report *api.DoctorReport) error {
+		for _, c := range report.Checks {
+			s.Printf("%s  %-24s %s\n", formatStatus(checkStatus(c.Status)), c.Name, c.Detail)
+		}
+		s.Printf("\n%d passed, %d warning(s), %d failed\n", report.Passed, report.Warnings, report.Failed)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d check(s) failed", report.Failed)
+	}
+	return nil
+}
+
+func checkCredentialHelper(helper string) doctorCheck {
+	name := "Credential helper"
+	binary := "docker-credential-" + helper
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return doctorCheck{name, checkFail, fmt.Sprintf("%s is configured but not in PATH", binary)}
+	}
+	return doctorCheck{name, checkPass, path}
+}
+
+// checkProxy reports the proxy environment, which Go's HTTP client and so
+// kroctl honor.
+func checkProxy() doctorCheck {
+	var settings []string
+	for _, key := range []string{"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY"} {
+		value := os.Getenv(key)
+		if value == "" {
+			value = os.Getenv(strings.ToLower(key))
+		}
+		if value == "" {
+			continue
+		}
+		if key != "NO_PROXY" {
+			if u, err := url.Parse(value); err != nil || u.Host == "" {
+				return doctorCheck{"Proxy", checkFail, fmt.Sprintf("%s=%s is not a valid URL", key, value)}
+			}
+		}
+		settings = append(settings, key+"="+value)
+	}
+	if len(settings) == 0 {
+		return doctorCheck{"Proxy", checkPass, "none configured"}
+	}
+	return doctorCheck{"Proxy", checkPass, strings.Join(settings, " ")}
+}
+
+// checkRegistry pings a registry with the configured credentials.
+func checkRegistry(ctx context.Context, cli *CLI, registry string) doctorCheck {
+	name := "Registry " + registry
+
+	reg, err := oci.SetupRegistry(registry)
+	if err != nil {
+		return doctorCheck{name, checkFail, err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	err = reg.Ping(ctx)
+	if err == nil {
+		return doctorCheck{name, checkPass, "reachable, credentials accepted"}
+	}
+	cli.Logger().Debug("Registry check failed", "registry", registry, "error", err.Error())
+
+	var resp *errcode.ErrorResponse
+	if errors.As(err, &resp) && resp.StatusCode == http.StatusUnauthorized {
+		return doctorCheck{name, checkWarn, "reachable, not logged in or credentials rejected"}
+	}
+	if isTLSError(err) {
+		return doctorCheck{name, checkFail, fmt.Sprintf("TLS handshake failed: %v", errors.Unwrap(err))}
+	}
+	return doctorCheck{name, checkFail, fmt.Sprintf("unreachable: %v", err)}
+}
+
+func isTLSError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	var recordHeader tls.RecordHeaderError
+	var verification *tls.CertificateVerificationError
+	return errors.As(err, &unknownAuthority) ||
+		errors.As(err, &invalid) ||
+		errors.As(err, &hostname) ||
+		errors.As(err, &recordHeader) ||
+		errors.As(err, &verification)
+}
+
+func formatStatus(status checkStatus) string {
+	switch status {
+	case checkPass:
+		return view.Success.Sprint(status)
+	case checkWarn:
+		return view.Caution.Sprint(status)
+	default:
+		return view.Failure.Sprint(status)
+	}
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProxy(t *testing.T) {
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(key, "")
+	}
+	assert.Equal(t, doctorCheck{"Proxy", checkPass, "none configured"}, checkProxy())
+
+	t.Setenv("https_proxy", "http://proxy.internal:3128")
+	t.Setenv("NO_PROXY", "localhost")
+	assert.Equal(t, doctorCheck{"Proxy", checkPass, "HTTPS_PROXY=http://proxy.internal:3128 NO_PROXY=localhost"}, checkProxy())
+
+	t.Setenv("https_proxy", "proxy.internal")
+	assert.Equal(t, checkFail, checkProxy().Status)
+}
+
+func TestCheckCredentialHelper_Missing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	check := checkCredentialHelper("osxkeychain")
+	assert.Equal(t, checkFail, check.Status)
+	assert.Equal(t, "docker-credential-osxkeychain is configured but not in PATH", check.Detail)
+}
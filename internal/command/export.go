@@ -0,0 +1,339 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// ExportFormat identifies the kind of package an RGD stack is exported to.
+type ExportFormat string
+
+const (
+	ExportFormatHelm      ExportFormat = "helm"
+	ExportFormatKustomize ExportFormat = "kustomize"
+)
+
+type ExportOptions struct {
+	Format          ExportFormat
+	Reference       string
+	Output          string
+	Variant         string
+	VerifyChecksums bool
+	RequireApproval bool
+	AllowDeprecated bool
+	AnyArtifactType bool
+	WithDocs        bool
+}
+
+// semverPattern matches tags that Helm accepts as a chart version.
+var semverPattern = regexp.MustCompile(`^v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`)
+
+func NewExportCommand(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export an RGD stack to another packaging format",
+		Long: "Export an RGD stack to another packaging format.\n\n" +
+			"Pulls the ResourceGraphDefinitions from an OCI artifact and writes\n" +
+			"them as a Helm chart or kustomize base, for clusters that install\n" +
+			"manifests with other tooling.\n",
+	}
+
+	cmd.AddCommand(
+		newExportFormatCommand(cli, ExportFormatHelm,
+			"Export an RGD stack as a Helm chart",
+			"  kroctl export helm localhost:5001/kro-stack-network:v1.0.0 -o ./chart\n"),
+		newExportFormatCommand(cli, ExportFormatKustomize,
+			"Export an RGD stack as a kustomize base",
+			"  kroctl export kustomize ghcr.io/acme/kro-stack:latest -o ./base\n"),
+	)
+
+	return cmd
+}
+
+func newExportFormatCommand(cli *CLI, format ExportFormat, short, example string) *cobra.Command {
+	opts := ExportOptions{Format: format}
+
+	cmd := &cobra.Command{
+		Use:   string(format) + " <reference>",
+		Short: short,
+		Long: short + ".\n\n" +
+			"The output directory defaults to the name of the repository.\n" +
+			"Stacks marked with kroctl deprecate are refused unless\n" +
+			"--allow-deprecated is given.\n\n" +
+			"Examples:\n" + example,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunExport(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Directory to write the exported files to")
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Variant to export when the reference is an index of variants")
+	cmd.Flags().BoolVar(&opts.VerifyChecksums, "verify-checksums", false, "Verify the files against the SHA256SUMS layer of the artifact")
+	cmd.Flags().BoolVar(&opts.WithDocs, "with-docs", false, "Also write the docs and examples shipped with the stack")
+	cmd.Flags().BoolVar(&opts.AnyArtifactType, "any-artifact-type", false, "Export RGD layers of artifacts that are not typed as a kro RGD stack")
+	cmd.Flags().BoolVar(&opts.RequireApproval, "require-approval", false, "Refuse to export a stack that was not approved with kroctl approve")
+	cmd.Flags().BoolVar(&opts.AllowDeprecated, "allow-deprecated", false, "Export a stack even when it was deprecated with kroctl deprecate")
+
+	return cmd
+}
+
+func RunExport(ctx context.Context, cli *CLI, opts *ExportOptions) error {
+	cli.Logger().Info("Exporting artifact",
+		"reference", opts.Reference,
+		"format", string(opts.Format))
+
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	manifest, files, err := pullStackFiles(ctx, cli, repo, opts.Reference, pullOptions{
+		Variant:         opts.Variant,
+		VerifyChecksums: opts.VerifyChecksums,
+		RequireApproval: opts.RequireApproval,
+		// Exported manifests get installed, where a deprecation matters most
+		RefuseDeprecated: !opts.AllowDeprecated,
+		AnyArtifactType:  opts.AnyArtifactType,
+	})
+	if err != nil {
+		return err
+	}
+
+	stackName := path.Base(repo.Reference.Repository)
+	output := opts.Output
+	if output == "" {
+		output = stackName
+	}
+
+	switch opts.Format {
+	case ExportFormatHelm:
+		err = writeHelmChart(output, stackName, repo.Reference.Reference, files)
+	case ExportFormatKustomize:
+		err = writeKustomization(output, files)
+	default:
+		err = fmt.Errorf("unknown export format %q", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.WithDocs {
+		// Read the docs of the manifest the files came from, the tag may
+		// have moved since
+		extracted, err := exportDocs(ctx, repo, manifest, output)
+		if err != nil {
+			return err
+		}
+		if extracted == 0 {
+			cli.Logger().Warn("Stack ships no docs or examples", "reference", opts.Reference)
+		}
+	}
+
+	result := &api.ExportResult{
+		TypeMeta:  api.NewTypeMeta(api.KindExportResult),
+		Reference: opts.Reference,
+		Format:    string(opts.Format),
+		Output:    output,
+		Files:     make([]string, 0, len(files)),
+	}
+	for _, f := range files {
+		result.Files = append(result.Files, f.Name)
+	}
+
+	return render(cli, result, func(s *view.Stream, result *api.ExportResult) error {
+		s.Printf("Exported %d RGD file(s) from %s as %s to %s\n",
+			len(result.Files), result.Reference, result.Format, result.Output)
+		return nil
+	})
+}
+
+// exportDocs extracts the docs and examples layers of manifest into dir,
+// and returns how many layers it extracted.
+func exportDocs(ctx context.Context, repo *remote.Repository, manifest *v1.Manifest, dir string) (int, error) {
+	var extracted int
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != oci.DocsMediaType && layer.MediaType != oci.ExamplesMediaType {
+			continue
+		}
+		data, err := oci.FetchLayer(ctx, repo, layer)
+		if err != nil {
+			return 0, err
+		}
+		if err := extractTarball(data, dir); err != nil {
+			return 0, fmt.Errorf("failed to extract %s: %w", oci.LayerTitle(layer), err)
+		}
+		extracted++
+	}
+	return extracted, nil
+}
+
+// extractTarball writes the regular files and directories of a gzipped
+// tarball into dir. Entries that would escape dir are rejected; links and
+// other special files are skipped.
+func extractTarball(data []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(path.Clean(header.Name))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("entry %s is outside the archive", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, content, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// helmChart is the subset of Chart.yaml written for an exported stack.
+type helmChart struct {
+	APIVersion  string `yaml:"apiVersion"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Type        string `yaml:"type"`
+	Version     string `yaml:"version"`
+	AppVersion  string `yaml:"appVersion,omitempty"`
+}
+
+func writeHelmChart(dir, name, tag string, files []stackFile) error {
+	// Helm requires a semver chart version, fall back when the tag is not one
+	version := "0.1.0"
+	if m := semverPattern.FindStringSubmatch(tag); m != nil {
+		version = m[1]
+	}
+
+	chart := helmChart{
+		APIVersion:  "v2",
+		Name:        name,
+		Description: "ResourceGraphDefinitions exported from an RGD stack by kroctl",
+		Type:        "application",
+		Version:     version,
+		AppVersion:  tag,
+	}
+
+	templates := filepath.Join(dir, "templates")
+	if err := os.MkdirAll(templates, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", templates, err)
+	}
+
+	if err := writeYAML(filepath.Join(dir, "Chart.yaml"), chart); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		// RGDs use ${...} for CEL, but guard against content that Helm
+		// would otherwise try to render as a template action.
+		content := f.Content
+		if strings.Contains(string(content), "{{") {
+			content = []byte("{{`" + strings.ReplaceAll(string(content), "`", "`}}{{\"`\"}}{{`") + "`}}\n")
+		}
+		if err := writeFile(filepath.Join(templates, f.Name), content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// kustomization is the subset of kustomization.yaml written for an exported stack.
+type kustomization struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Resources  []string `yaml:"resources"`
+}
+
+func writeKustomization(dir string, files []stackFile) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	k := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+	}
+	for _, f := range files {
+		if err := writeFile(filepath.Join(dir, f.Name), f.Content); err != nil {
+			return err
+		}
+		k.Resources = append(k.Resources, f.Name)
+	}
+
+	return writeYAML(filepath.Join(dir, "kustomization.yaml"), k)
+}
+
+func writeYAML(filename string, v any) error {
+	var buf bytes.Buffer
+	if err := encodeYAML(&buf, v); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filename, err)
+	}
+	return writeFile(filename, buf.Bytes())
+}
+
+// encodeYAML writes each value as a separate YAML document using the
+// two-space indentation common to Kubernetes manifests.
+func encodeYAML(w io.Writer, docs ...any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+func writeFile(filename string, data []byte) error {
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHelmChart(t *testing.T) {
+	dir := t.TempDir()
+	files := []stackFile{{Name: "vpc.yaml", Content: []byte("kind: ResourceGraphDefinition\n")}}
+
+	err := writeHelmChart(dir, "kro-stack-network", "v1.2.3", files)
+	require.NoError(t, err)
+
+	chart, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(chart), "name: kro-stack-network")
+	assert.Contains(t, string(chart), "version: 1.2.3")
+	assert.Contains(t, string(chart), "appVersion: v1.2.3")
+
+	rgd, err := os.ReadFile(filepath.Join(dir, "templates", "vpc.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ResourceGraphDefinition\n", string(rgd))
+}
+
+func TestWriteHelmChart_NonSemverTag(t *testing.T) {
+	dir := t.TempDir()
+
+	err := writeHelmChart(dir, "stack", "latest", []stackFile{{Name: "a.yaml"}})
+	require.NoError(t, err)
+
+	chart, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(chart), "version: 0.1.0")
+}
+
+func TestWriteKustomization(t *testing.T) {
+	dir := t.TempDir()
+	files := []stackFile{
+		{Name: "stack.yaml", Content: []byte("a: 1\n")},
+		{Name: "vpc.yaml", Content: []byte("b: 2\n")},
+	}
+
+	err := writeKustomization(dir, files)
+	require.NoError(t, err)
+
+	k, err := os.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: kustomize.config.k8s.io/v1beta1\n"+
+		"kind: Kustomization\n"+
+		"resources:\n"+
+		"  - stack.yaml\n"+
+		"  - vpc.yaml\n", string(k))
+}
+
+func tarball(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestExtractTarball(t *testing.T) {
+	dir := t.TempDir()
+	data := tarball(t, map[string]string{
+		"docs/README.md":     "# Network\n",
+		"docs/guides/vpc.md": "VPC\n",
+	})
+
+	require.NoError(t, extractTarball(data, dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, "docs", "guides", "vpc.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "VPC\n", string(content))
+}
+
+func TestExtractTarball_Escape(t *testing.T) {
+	dir := t.TempDir()
+	data := tarball(t, map[string]string{"../outside.md": "nope\n"})
+
+	assert.ErrorContains(t, extractTarball(data, dir), "outside the archive")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), "outside.md"))
+}
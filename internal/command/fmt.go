@@ -0,0 +1,107 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type FmtOptions struct {
+	Filenames []string
+	Check     bool
+}
+
+func NewFmtCommand(cli *CLI) *cobra.Command {
+	opts := FmtOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "fmt",
+		Short: "Rewrite ResourceGraphDefinitions in canonical format",
+		Long: "Rewrite ResourceGraphDefinitions in canonical format.\n\n" +
+			"Orders well-known fields consistently, indents with two spaces,\n" +
+			"trims whitespace inside CEL expressions, and ends every file with\n" +
+			"a single newline. Canonical files produce stable digests when\n" +
+			"they are pushed.\n\n" +
+			"With --check, no files are written and the command fails when a\n" +
+			"file is not formatted, which is useful in CI.\n\n" +
+			"Examples:\n" +
+			"  kroctl fmt -f ./rgds/\n\n" +
+			"  kroctl fmt --check -f stack.yaml -f vpc.yaml\n",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunFmt(cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
+		[]string{}, "RGD files or directories to format (required)")
+	cmd.Flags().BoolVar(&opts.Check, "check", false, "Fail if files are not formatted instead of rewriting them")
+	_ = cmd.MarkFlagRequired("filenames")
+
+	return cmd
+}
+
+func RunFmt(cli *CLI, opts *FmtOptions) error {
+	files, err := collectYAMLFiles(opts.Filenames)
+	if err != nil {
+		return err
+	}
+
+	report := &api.FormatReport{
+		TypeMeta: api.NewTypeMeta(api.KindFormatReport),
+		Files:    []string{},
+		Written:  !opts.Check,
+	}
+	for _, filename := range files {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+
+		formatted, err := rgd.Format(data)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", filename, err)
+		}
+
+		if bytes.Equal(data, formatted) {
+			cli.Logger().Debug("File already formatted", "file", filename)
+			continue
+		}
+
+		report.Files = append(report.Files, filename)
+
+		if opts.Check {
+			continue
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			return fmt.Errorf("failed to access %s: %w", filename, err)
+		}
+		if err := os.WriteFile(filename, formatted, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+	}
+
+	err = render(cli, report, func(s *view.Stream, report *api.FormatReport) error {
+		for _, filename := range report.Files {
+			s.Println(filename)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.Check && len(report.Files) > 0 {
+		return fmt.Errorf("%d file(s) are not formatted, run kroctl fmt to fix them", len(report.Files))
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestRunFmt_JSON(t *testing.T) {
+	dir := t.TempDir()
+	unformatted := schemaRGD("network", "Network", "        cidr:   string")
+	formatted, err := rgd.Format(schemaRGD("app", "App", "      image: string"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "network.yaml"), unformatted, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), formatted, 0o644))
+
+	var out bytes.Buffer
+	cli := NewCLI(view.ViewJSON, &out, view.LogLevelSilent)
+	err = RunFmt(cli, &FmtOptions{Filenames: []string{dir}, Check: true})
+	require.EqualError(t, err, "1 file(s) are not formatted, run kroctl fmt to fix them")
+
+	// The report is printed before --check fails
+	var report api.FormatReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, api.KindFormatReport, report.Kind)
+	assert.Equal(t, []string{filepath.Join(dir, "network.yaml")}, report.Files)
+	assert.False(t, report.Written)
+
+	require.NoError(t, RunFmt(cli, &FmtOptions{Filenames: []string{dir}}))
+
+	// Once rewritten, nothing is left to format
+	out.Reset()
+	require.NoError(t, RunFmt(cli, &FmtOptions{Filenames: []string{dir}}))
+	assert.JSONEq(t, `{"apiVersion": "`+api.APIVersion+`", "kind": "FormatReport", "files": [], "written": true}`, out.String())
+}
@@ -0,0 +1,31 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// objectMeta is the subset of Kubernetes object metadata set on generated manifests.
+type objectMeta struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+func NewGenerateCommand(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate manifests for deploying an RGD stack",
+		Long: "Generate manifests for deploying an RGD stack.\n\n" +
+			"Emits the Kubernetes objects that GitOps tooling needs to\n" +
+			"continuously reconcile an RGD stack artifact from a registry.\n" +
+			"The manifests are written to standard output.\n\n" +
+			"generate docs writes a markdown reference of the instance APIs\n" +
+			"the RGDs define instead.\n",
+	}
+
+	cmd.AddCommand(
+		newGenerateFluxCommand(cli),
+		newGenerateDocsCommand(cli),
+	)
+
+	return cmd
+}
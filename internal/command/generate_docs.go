@@ -0,0 +1,90 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+type GenerateDocsOptions struct {
+	Filenames []string
+	Output    string
+}
+
+func newGenerateDocsCommand(cli *CLI) *cobra.Command {
+	opts := GenerateDocsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate a markdown API reference for RGDs",
+		Long: "Generate a markdown API reference for RGDs.\n\n" +
+			"Documents the instance API of every ResourceGraphDefinition: its\n" +
+			"spec fields with their types, defaults, and markers, its status\n" +
+			"fields, its validation rules, and the resources it creates. The\n" +
+			"fields are read with the same simpleSchema parser kroctl validate\n" +
+			"uses.\n\n" +
+			"With --output, a <rgd-name>.md file is written per RGD to the\n" +
+			"directory. Otherwise the references are written to standard output.\n\n" +
+			"Examples:\n" +
+			"  kroctl generate docs -f rgd.yaml\n\n" +
+			"  kroctl generate docs -f ./rgds/ -o docs/\n",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGenerateDocs(cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
+		[]string{}, "RGD files or directories to document (required)")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Directory to write a reference per RGD to")
+	_ = cmd.MarkFlagRequired("filenames")
+
+	return cmd
+}
+
+func RunGenerateDocs(cli *CLI, opts *GenerateDocsOptions) error {
+	if cli.IsStructured() {
+		return errHumanOnly("kroctl generate docs")
+	}
+
+	files, err := readStackFiles(opts.Filenames)
+	if err != nil {
+		return err
+	}
+
+	rgds, err := parseStackFiles(files)
+	if err != nil {
+		return err
+	}
+	if len(rgds) == 0 {
+		return fmt.Errorf("no ResourceGraphDefinitions found")
+	}
+
+	if opts.Output == "" {
+		for i, r := range rgds {
+			if i > 0 {
+				cli.Println()
+			}
+			cli.Printf("%s", rgd.Markdown(r))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(opts.Output, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", opts.Output, err)
+	}
+	for _, r := range rgds {
+		path := filepath.Join(opts.Output, r.Metadata.Name+".md")
+		if err := os.WriteFile(path, rgd.Markdown(r), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		cli.Logger().Debug("Wrote reference", "rgd", r.Metadata.Name, "path", path)
+	}
+	cli.Printf("Wrote %d reference(s) to %s\n", len(rgds), opts.Output)
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package command
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+type GenerateFluxOptions struct {
+	Reference       string
+	Name            string
+	Namespace       string
+	Interval        string
+	Semver          string
+	Path            string
+	TargetNamespace string
+	Prune           bool
+	VerifyProvider  string
+	VerifySecretRef string
+}
+
+type fluxOCIRepository struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   objectMeta            `yaml:"metadata"`
+	Spec       fluxOCIRepositorySpec `yaml:"spec"`
+}
+
+type fluxOCIRepositorySpec struct {
+	Interval      string            `yaml:"interval"`
+	URL           string            `yaml:"url"`
+	Ref           fluxOCIRef        `yaml:"ref"`
+	LayerSelector fluxLayerSelector `yaml:"layerSelector"`
+	Insecure      bool              `yaml:"insecure,omitempty"`
+	Verify        *fluxVerification `yaml:"verify,omitempty"`
+}
+
+type fluxOCIRef struct {
+	Digest string `yaml:"digest,omitempty"`
+	Semver string `yaml:"semver,omitempty"`
+	Tag    string `yaml:"tag,omitempty"`
+}
+
+type fluxLayerSelector struct {
+	MediaType string `yaml:"mediaType"`
+	Operation string `yaml:"operation"`
+}
+
+type fluxVerification struct {
+	Provider  string        `yaml:"provider"`
+	SecretRef *fluxLocalRef `yaml:"secretRef,omitempty"`
+}
+
+type fluxLocalRef struct {
+	Name string `yaml:"name"`
+}
+
+type fluxKustomization struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   objectMeta            `yaml:"metadata"`
+	Spec       fluxKustomizationSpec `yaml:"spec"`
+}
+
+type fluxKustomizationSpec struct {
+	Interval        string        `yaml:"interval"`
+	SourceRef       fluxSourceRef `yaml:"sourceRef"`
+	Path            string        `yaml:"path"`
+	Prune           bool          `yaml:"prune"`
+	TargetNamespace string        `yaml:"targetNamespace,omitempty"`
+}
+
+type fluxSourceRef struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+func newGenerateFluxCommand(cli *CLI) *cobra.Command {
+	opts := GenerateFluxOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "flux <reference>",
+		Short: "Generate Flux OCIRepository and Kustomization manifests",
+		Long: "Generate Flux OCIRepository and Kustomization manifests.\n\n" +
+			"The OCIRepository tracks the stack artifact by tag, digest, or a\n" +
+			"semver range. Flux reads a single layer of an artifact, so it\n" +
+			"selects and extracts the archive of all RGD files that kroctl push\n" +
+			"adds with --archive; push the stack with it. The Kustomization\n" +
+			"applies the extracted ResourceGraphDefinitions.\n\n" +
+			"Examples:\n" +
+			"  kroctl push ghcr.io/acme/kro-stack:v1.0.0 -f ./rgds/ --archive\n" +
+			"  kroctl generate flux ghcr.io/acme/kro-stack:v1.0.0\n\n" +
+			"  kroctl generate flux ghcr.io/acme/kro-stack --semver '>=1.0.0 <2.0.0' \\\n" +
+			"    --verify-provider cosign\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunGenerateFlux(cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the generated objects (defaults to the repository name)")
+	cmd.Flags().StringVar(&opts.Namespace, "namespace", "flux-system", "Namespace of the generated objects")
+	cmd.Flags().StringVar(&opts.Interval, "interval", "10m", "Reconciliation interval")
+	cmd.Flags().StringVar(&opts.Semver, "semver", "", "Semver range to track instead of the reference tag")
+	cmd.Flags().StringVar(&opts.Path, "path", "./", "Path within the artifact to apply")
+	cmd.Flags().StringVar(&opts.TargetNamespace, "target-namespace", "", "Namespace to apply the RGDs to")
+	cmd.Flags().BoolVar(&opts.Prune, "prune", true, "Garbage collect RGDs removed from the artifact")
+	cmd.Flags().StringVar(&opts.VerifyProvider, "verify-provider", "", "Signature verification provider (cosign or notation)")
+	cmd.Flags().StringVar(&opts.VerifySecretRef, "verify-secret-ref", "", "Secret holding the verification public keys")
+
+	return cmd
+}
+
+func RunGenerateFlux(cli *CLI, opts *GenerateFluxOptions) error {
+	if cli.IsStructured() {
+		return errHumanOnly("kroctl generate flux")
+	}
+
+	ref, err := registry.ParseReference(opts.Reference)
+	if err != nil {
+		return fmt.Errorf("invalid reference %s: %w", opts.Reference, err)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = path.Base(ref.Repository)
+	}
+
+	source := fluxOCIRepository{
+		APIVersion: "source.toolkit.fluxcd.io/v1beta2",
+		Kind:       "OCIRepository",
+		Metadata:   objectMeta{Name: name, Namespace: opts.Namespace},
+		Spec: fluxOCIRepositorySpec{
+			Interval: opts.Interval,
+			URL:      "oci://" + ref.Registry + "/" + ref.Repository,
+			LayerSelector: fluxLayerSelector{
+				MediaType: oci.ArchiveMediaType,
+				Operation: "extract",
+			},
+			Insecure: oci.IsLocalRegistry(ref.Host()),
+		},
+	}
+
+	switch {
+	case opts.Semver != "":
+		source.Spec.Ref.Semver = opts.Semver
+	case ref.ValidateReferenceAsDigest() == nil:
+		source.Spec.Ref.Digest = ref.Reference
+	default:
+		source.Spec.Ref.Tag = ref.ReferenceOrDefault()
+	}
+
+	if opts.VerifyProvider != "" {
+		source.Spec.Verify = &fluxVerification{Provider: opts.VerifyProvider}
+		if opts.VerifySecretRef != "" {
+			source.Spec.Verify.SecretRef = &fluxLocalRef{Name: opts.VerifySecretRef}
+		}
+	}
+
+	kustomization := fluxKustomization{
+		APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+		Kind:       "Kustomization",
+		Metadata:   objectMeta{Name: name, Namespace: opts.Namespace},
+		Spec: fluxKustomizationSpec{
+			Interval:        opts.Interval,
+			SourceRef:       fluxSourceRef{Kind: source.Kind, Name: name},
+			Path:            opts.Path,
+			Prune:           opts.Prune,
+			TargetNamespace: opts.TargetNamespace,
+		},
+	}
+
+	cli.Printf("---\n")
+	return encodeYAML(cli.Writer, source, kustomization)
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// selectLayer returns the contents of the layer Flux selects from the
+// stack at tag in the OCI layout: the first layer of the media type.
+func selectLayer(t *testing.T, layout, tag, mediaType string) []byte {
+	t.Helper()
+	ctx := context.Background()
+
+	store, err := oci.OpenLayout(layout)
+	require.NoError(t, err)
+	desc, err := store.Resolve(ctx, tag)
+	require.NoError(t, err)
+	data, err := content.FetchAll(ctx, store, desc)
+	require.NoError(t, err)
+	var manifest v1.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == mediaType {
+			data, err := content.FetchAll(ctx, store, layer)
+			require.NoError(t, err)
+			return data
+		}
+	}
+	t.Fatalf("no layer of %s matches %s", tag, mediaType)
+	return nil
+}
+
+// TestRunGenerateFlux_SelectsEveryRGD applies the generated manifests the
+// way Flux does and checks that the Kustomization path holds every RGD.
+func TestRunGenerateFlux_SelectsEveryRGD(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	names := []string{"app", "db", "cache"}
+	rgds := filepath.Join(dir, "rgds")
+	require.NoError(t, os.Mkdir(rgds, 0o755))
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(rgds, name+".yaml"), testRGD(name), 0o644))
+	}
+
+	layout := filepath.Join(dir, "layout")
+	cli := NewCLI(view.ViewHuman, new(bytes.Buffer), view.LogLevelSilent)
+	for _, tag := range []string{"v1.0.0", "v1.0.1"} {
+		require.NoError(t, RunPush(ctx, cli, &PushOptions{
+			Filenames: []string{rgds},
+			Reference: "oci-layout:" + layout + ":" + tag,
+			Archive:   true,
+			Checksums: true,
+		}))
+	}
+
+	buf := new(bytes.Buffer)
+	cli = NewCLI(view.ViewHuman, buf, view.LogLevelSilent)
+	require.NoError(t, RunGenerateFlux(cli, &GenerateFluxOptions{
+		Reference: "ghcr.io/acme/kro-stack:v1.0.0",
+		Namespace: "flux-system",
+		Interval:  "10m",
+		Path:      "./",
+	}))
+
+	var source fluxOCIRepository
+	var kustomization fluxKustomization
+	dec := yaml.NewDecoder(buf)
+	require.NoError(t, dec.Decode(&source))
+	require.NoError(t, dec.Decode(&kustomization))
+	assert.Equal(t, "extract", source.Spec.LayerSelector.Operation)
+
+	archive := selectLayer(t, layout, "v1.0.0", source.Spec.LayerSelector.MediaType)
+	out := filepath.Join(dir, "out")
+	require.NoError(t, extractTarball(archive, out))
+
+	root := filepath.Join(out, kustomization.Spec.Path)
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, len(names))
+	for _, name := range names {
+		got, err := os.ReadFile(filepath.Join(root, name+".yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, testRGD(name), got)
+	}
+
+	// The archive is reproducible, so pushing the same files yields it again
+	assert.Equal(t, archive, selectLayer(t, layout, "v1.0.1", source.Spec.LayerSelector.MediaType))
+}
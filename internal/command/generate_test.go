@@ -0,0 +1,95 @@
+package command_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/command"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGenerateFlux_Tag(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cli := command.NewCLI(view.ViewHuman, buf, view.LogLevelSilent)
+
+	err := command.RunGenerateFlux(cli, &command.GenerateFluxOptions{
+		Reference: "ghcr.io/acme/kro-stack:v1.0.0",
+		Namespace: "flux-system",
+		Interval:  "10m",
+		Path:      "./",
+		Prune:     true,
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "kind: OCIRepository")
+	assert.Contains(t, out, "url: oci://ghcr.io/acme/kro-stack")
+	assert.Contains(t, out, "tag: v1.0.0")
+	assert.Contains(t, out, "kind: Kustomization")
+	assert.Contains(t, out, "name: kro-stack")
+	assert.NotContains(t, out, "insecure")
+	assert.NotContains(t, out, "verify")
+}
+
+func TestRunGenerateFlux_SemverAndVerify(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cli := command.NewCLI(view.ViewHuman, buf, view.LogLevelSilent)
+
+	err := command.RunGenerateFlux(cli, &command.GenerateFluxOptions{
+		Reference:      "localhost:5001/kro-stack",
+		Name:           "network",
+		Semver:         ">=1.0.0",
+		VerifyProvider: "cosign",
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "name: network")
+	assert.Contains(t, out, "semver: '>=1.0.0'")
+	assert.Contains(t, out, "provider: cosign")
+	assert.Contains(t, out, "insecure: true")
+	assert.NotContains(t, out, "tag:")
+}
+
+func TestRunGenerateFlux_InvalidReference(t *testing.T) {
+	cli := command.NewCLI(view.ViewHuman, new(bytes.Buffer), view.LogLevelSilent)
+
+	err := command.RunGenerateFlux(cli, &command.GenerateFluxOptions{Reference: "not a reference"})
+	assert.Error(t, err)
+}
+
+func TestRunGenerateDocs_Output(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: web-app
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: WebApp
+    spec:
+      name: string | required=true
+`), 0o644))
+
+	buf := new(bytes.Buffer)
+	cli := command.NewCLI(view.ViewHuman, buf, view.LogLevelSilent)
+
+	out := filepath.Join(dir, "docs")
+	err := command.RunGenerateDocs(cli, &command.GenerateDocsOptions{
+		Filenames: []string{file},
+		Output:    out,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Wrote 1 reference(s)")
+
+	doc, err := os.ReadFile(filepath.Join(out, "web-app.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), "# WebApp\n")
+	assert.Contains(t, string(doc), "| `spec.name` | string | yes |")
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+// workflowCommand formats a diagnostic as a GitHub Actions workflow
+// command, which annotates the line in the run summary and pull request.
+func workflowCommand(d rgd.Diagnostic) string {
+	level := "error"
+	if d.Severity == rgd.SeverityWarning {
+		level = "warning"
+	}
+
+	props := "file=" + escapeWorkflowProperty(d.File)
+	if d.Line > 0 {
+		props += fmt.Sprintf(",line=%d", d.Line)
+	}
+	return fmt.Sprintf("::%s %s::%s", level, props, escapeWorkflowData(d.Message))
+}
+
+// escapeWorkflowData escapes the message of a workflow command.
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeWorkflowProperty escapes a property value of a workflow command,
+// which additionally cannot hold the separators.
+func escapeWorkflowProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeWorkflowData(s))
+}
+
+// writeGitHubOutput appends step outputs to the file GitHub Actions names
+// in GITHUB_OUTPUT, so later steps can read them as
+// steps.<id>.outputs.<name>. Nothing is written outside of Actions.
+func (c *CLI) writeGitHubOutput(outputs map[string]string) error {
+	if c.GitHubOutput == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(outputs)) {
+		fmt.Fprintf(&b, "%s=%s\n", name, outputs[name])
+	}
+
+	f, err := os.OpenFile(c.GitHubOutput, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open GitHub output file: %w", err)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write GitHub output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write GitHub output file: %w", err)
+	}
+	return nil
+}
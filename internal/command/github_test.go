@@ -0,0 +1,44 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestWorkflowCommand(t *testing.T) {
+	assert.Equal(t, "::error file=rgds/app.yaml,line=4::unknown field \"sepc\"",
+		workflowCommand(rgd.Diagnostic{File: "rgds/app.yaml", Line: 4, Message: `unknown field "sepc"`}))
+
+	assert.Equal(t, "::warning file=C%3A\\app%2Cv2.yaml::100%25 deprecated%0Asee docs",
+		workflowCommand(rgd.Diagnostic{
+			File:     `C:\app,v2.yaml`,
+			Severity: rgd.SeverityWarning,
+			Message:  "100% deprecated\nsee docs",
+		}))
+}
+
+func TestWriteGitHubOutput(t *testing.T) {
+	cli := NewCLI(view.ViewHuman, new(bytes.Buffer), view.LogLevelSilent)
+
+	// Outside of Actions nothing is written
+	require.NoError(t, cli.writeGitHubOutput(map[string]string{"digest": "sha256:abc"}))
+
+	cli.GitHubOutput = filepath.Join(t.TempDir(), "output")
+	require.NoError(t, os.WriteFile(cli.GitHubOutput, []byte("previous=step\n"), 0o644))
+	require.NoError(t, cli.writeGitHubOutput(map[string]string{
+		"reference": "ghcr.io/acme/kro-stack:v1",
+		"digest":    "sha256:abc",
+	}))
+
+	data, err := os.ReadFile(cli.GitHubOutput)
+	require.NoError(t, err)
+	assert.Equal(t, "previous=step\ndigest=sha256:abc\nreference=ghcr.io/acme/kro-stack:v1\n", string(data))
+}
@@ -0,0 +1,170 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type ImagesOptions struct {
+	Reference string
+	Filenames []string
+	Pin       bool
+}
+
+func NewImagesCommand(cli *CLI) *cobra.Command {
+	opts := ImagesOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "images [reference]",
+		Short: "List the container images RGDs deploy",
+		Long: "List the container images RGDs deploy.\n\n" +
+			"Lists every container image referenced in the resource templates\n" +
+			"of a stack, or of local files with -f. Images computed by an\n" +
+			"expression, such as ${schema.spec.image}, depend on the instance\n" +
+			"and are left out.\n\n" +
+			"With --pin, images referenced by tag are resolved to the digest\n" +
+			"the tag points at and the files are rewritten to image:tag@digest,\n" +
+			"so a published stack always deploys the same images. Only the\n" +
+			"image values change. --pin requires -f.\n\n" +
+			"Examples:\n" +
+			"  kroctl images ghcr.io/acme/kro-stack:v1.2.0\n\n" +
+			"  kroctl images -f ./rgds/\n\n" +
+			"  kroctl images -f ./rgds/ --pin\n",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				opts.Reference = args[0]
+			}
+			return RunImages(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
+		[]string{}, "RGD files or directories to list the images of")
+	cmd.Flags().BoolVar(&opts.Pin, "pin", false, "Resolve image tags to digests and rewrite the files")
+
+	return cmd
+}
+
+func RunImages(ctx context.Context, cli *CLI, opts *ImagesOptions) error {
+	if (opts.Reference == "") == (len(opts.Filenames) == 0) {
+		return fmt.Errorf("pass either a reference or files with -f")
+	}
+	if opts.Pin && opts.Reference != "" {
+		return fmt.Errorf("--pin rewrites local files, use -f instead of a reference")
+	}
+
+	var files []stackFile
+	var err error
+	if opts.Reference != "" {
+		files, err = loadStackFiles(ctx, cli, opts.Reference)
+	} else {
+		files, err = readImageFiles(opts.Filenames)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := &api.ImageList{
+		TypeMeta: api.NewTypeMeta(api.KindImageList),
+		Images:   []api.ImageUse{},
+	}
+	// Digests are resolved once per image, stacks often repeat them
+	pinned := map[string]string{}
+	for _, file := range files {
+		rgds, err := rgd.Parse(file.Name, file.Content)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file.Name, err)
+		}
+
+		var refs []rgd.ImageRef
+		for _, r := range rgds {
+			refs = append(refs, rgd.Images(r)...)
+		}
+		for _, ref := range refs {
+			use := api.ImageUse{
+				Image:    ref.Image(),
+				File:     file.Name,
+				Line:     ref.Node.Line,
+				RGD:      ref.RGD,
+				Resource: ref.Resource,
+			}
+			if opts.Pin && !strings.Contains(ref.Image(), "@") {
+				to, ok := pinned[ref.Image()]
+				if !ok {
+					dgst, err := oci.ResolveImage(ctx, ref.Image())
+					if err != nil {
+						return err
+					}
+					to = ref.Image() + "@" + dgst.String()
+					pinned[ref.Image()] = to
+				}
+				use.Pinned = to
+			}
+			result.Images = append(result.Images, use)
+		}
+
+		if !opts.Pin || len(refs) == 0 {
+			continue
+		}
+		out := rgd.PinImages(file.Content, refs, pinned)
+		if string(out) == string(file.Content) {
+			continue
+		}
+		if err := os.WriteFile(file.Name, out, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Name, err)
+		}
+		cli.Logger().Debug("Pinned images", "file", file.Name)
+	}
+
+	return render(cli, result, func(s *view.Stream, result *api.ImageList) error {
+		if len(result.Images) == 0 {
+			s.Println("No images found")
+			return nil
+		}
+		w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Image\tRGD\tResource\tFile\n")
+		for _, use := range result.Images {
+			image := use.Image
+			if use.Pinned != "" {
+				image = use.Pinned
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s:%d\n", image, use.RGD, use.Resource, use.File, use.Line)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if opts.Pin {
+			s.Printf("\nPinned %d image(s)\n", len(pinned))
+		}
+		return nil
+	})
+}
+
+// readImageFiles reads the given files and directories, naming every file
+// by its path so it can be rewritten.
+func readImageFiles(filenames []string) ([]stackFile, error) {
+	paths, err := collectYAMLFiles(filenames)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]stackFile, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		files = append(files, stackFile{Name: path, Content: data})
+	}
+	return files, nil
+}
@@ -2,120 +2,676 @@ package command
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry/remote"
 
+	"github.com/bschaatsbergen/kroctl/api"
 	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
 )
 
 type InspectOptions struct {
 	Reference string
+	Summary   bool
+	Licenses  bool
+	Variant   string
+	AllTags   bool
+	RefFile   string
+	// AnyArtifactType inspects artifacts that are not typed as a kro RGD
+	// stack instead of refusing them
+	AnyArtifactType bool
+	// Attestations lists the attestations and fetches their predicates
+	Attestations bool
 }
 
 func NewInspectCommand(cli *CLI) *cobra.Command {
 	opts := InspectOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "inspect <reference>",
+		Use:   "inspect <reference>...",
 		Short: "Inspect a ResourceGraphDefinition artifact in an OCI registry",
 		Long: "Inspect a ResourceGraphDefinition artifact in an OCI registry.\n\n" +
 			"Fetches the manifest from the registry and displays information\n" +
 			"about the RGD stack, including all ResourceGraphDefinitions\n" +
 			"contained in the artifact.\n\n" +
+			"With --summary, the layers are downloaded as well and the APIs\n" +
+			"the stack defines, the Kubernetes kinds it manages, and the\n" +
+			"container images its templates reference are listed. With\n" +
+			"--licenses, the license and source labels of every image are\n" +
+			"looked up in its registry, for compliance reviews of the\n" +
+			"third-party images a stack deploys.\n\n" +
+			"When the reference points at an index, its manifests are listed.\n" +
+			"Use --variant to inspect one of the variants pushed with\n" +
+			"kroctl push --variant.\n\n" +
+			"Artifacts that are not typed as a kro RGD stack, such as a\n" +
+			"container image pushed to the wrong tag, are refused unless\n" +
+			"--any-artifact-type is given.\n\n" +
+			"Stacks pushed with --supersedes are linked to earlier versions\n" +
+			"through their OCI subject. The versions a stack supersedes, and\n" +
+			"the stacks that supersede it, are listed under Versions. Subjects\n" +
+			"set by other tools are shown with their artifact type.\n\n" +
+			"Stacks marked with kroctl deprecate show their deprecation\n" +
+			"message. With --attestations, the attestations attached with\n" +
+			"kroctl attest are listed, and their predicates are included in\n" +
+			"the JSON output.\n\n" +
+			"With several references, or --all-tags, the stacks are inspected\n" +
+			"concurrently and compared in a table of their version, creation\n" +
+			"time, RGD count, size, and whether a Notation or cosign signature\n" +
+			"is attached. --all-tags inspects every tag of the repository of\n" +
+			"each reference. With --ref-file, references are read from a file\n" +
+			"with one per line, or from stdin when the file is -. Blank lines\n" +
+			"and lines starting with # are skipped.\n\n" +
+			"With --format, the result is printed with a Go template over the\n" +
+			"fields of the JSON output, such as {{.Manifest.Digest}}. The json\n" +
+			"and join functions are available.\n\n" +
 			"Examples:\n" +
 			"  kroctl inspect localhost:5001/kro-stack-network:v1.0.0\n\n" +
-			"  kroctl inspect ghcr.io/acme/kro-stack:latest\n",
-		Args: cobra.ExactArgs(1),
+			"  kroctl inspect --summary ghcr.io/acme/kro-stack:latest\n\n" +
+			"  kroctl inspect --licenses ghcr.io/acme/kro-stack:latest\n\n" +
+			"  kroctl inspect --attestations ghcr.io/acme/kro-stack:v1.0.0 --json\n\n" +
+			"  kroctl inspect --variant aws ghcr.io/acme/kro-stack:v1.0.0\n\n" +
+			"  kroctl inspect ghcr.io/acme/network:v1.0.0 ghcr.io/acme/network:v1.1.0\n\n" +
+			"  kroctl inspect --all-tags ghcr.io/acme/network\n\n" +
+			"  kroctl inspect --ref-file refs.txt --json\n\n" +
+			"  kroctl inspect ghcr.io/acme/kro-stack:v1.0.0 --format '{{.Manifest.Digest}}'\n",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.RefFile != "" {
+				refs, err := readRefFile(opts.RefFile, cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				args = append(args, refs...)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("no references specified, pass references or use --ref-file")
+			}
+			if len(args) > 1 || opts.AllTags || opts.RefFile != "" {
+				return RunInspectList(cmd.Context(), cli, args, &opts)
+			}
 			opts.Reference = args[0]
 			return RunInspect(cmd.Context(), cli, &opts)
 		},
 	}
 
+	cmd.Flags().BoolVar(&opts.Summary, "summary", false, "Download the layers and summarize the resources the stack manages")
+	cmd.Flags().BoolVar(&opts.Licenses, "licenses", false, "Summarize and look up the licenses of the images the stack deploys")
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Variant to inspect when the reference is an index of variants")
+	cmd.Flags().BoolVar(&opts.Attestations, "attestations", false, "List the attestations attached with kroctl attest")
+	cmd.Flags().BoolVar(&opts.AnyArtifactType, "any-artifact-type", false, "Inspect artifacts that are not typed as a kro RGD stack")
+	cmd.Flags().BoolVar(&opts.AllTags, "all-tags", false, "Inspect and compare every tag of the repository")
+	cmd.Flags().StringVar(&opts.RefFile, "ref-file", "", "Read references from a file, one per line, or from stdin with -")
+
 	return cmd
 }
 
 func RunInspect(ctx context.Context, cli *CLI, opts *InspectOptions) error {
+	result, err := inspect(ctx, cli, opts)
+	if err != nil {
+		return err
+	}
+
+	return render(cli, result, printInspect)
+}
+
+// inspect fetches the artifact at the reference and describes it.
+func inspect(ctx context.Context, cli *CLI, opts *InspectOptions) (*api.InspectResult, error) {
 	cli.Logger().Info("Inspecting artifact", "reference", opts.Reference)
 
 	// Set up remote repository with authentication
 	repo, err := oci.SetupRepository(opts.Reference)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if repo.PlainHTTP {
 		cli.Logger().Debug("Using plain HTTP for local registry", "host", repo.Reference.Host())
 	}
 
+	result := &api.InspectResult{
+		TypeMeta:   api.NewTypeMeta(api.KindInspectResult),
+		Reference:  opts.Reference,
+		Registry:   repo.Reference.Host(),
+		Repository: repo.Reference.Repository,
+	}
+
+	root, err := oci.Resolve(ctx, repo, opts.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	reference, variant := opts.Reference, opts.Variant
+	if oci.IsIndex(root.MediaType) {
+		_, index, err := oci.FetchIndex(ctx, repo, opts.Reference)
+		if err != nil {
+			return nil, err
+		}
+		result.Index = indexResult(root, index)
+
+		// Without a variant, only an index that wraps a single manifest has
+		// an obvious one to show
+		if opts.Variant == "" && len(index.Manifests) != 1 {
+			return result, nil
+		}
+		selected, err := oci.SelectManifest(index, opts.Variant)
+		if err != nil {
+			return nil, err
+		}
+		reference, variant = selected.Digest.String(), ""
+	}
+
 	// Fetch the manifest
-	manifestDesc, rc, err := repo.FetchReference(ctx, opts.Reference)
+	manifestDesc, manifest, err := oci.FetchManifest(ctx, repo, reference, variant)
 	if err != nil {
-		return fmt.Errorf("failed to fetch manifest: %w", err)
+		return nil, err
 	}
-	defer rc.Close()
 
 	cli.Logger().Debug("Fetched manifest",
 		"digest", manifestDesc.Digest.String(),
 		"mediaType", manifestDesc.MediaType)
 
-	manifestBytes, err := io.ReadAll(rc)
+	if !oci.IsStack(manifest) && !opts.AnyArtifactType {
+		return nil, errNotStack(opts.Reference, manifest)
+	}
+
+	result.Manifest = manifestResult(manifestDesc, manifest)
+
+	// The config is small and describes the stack without the layers
+	config, err := oci.FetchConfig(ctx, repo, manifest)
 	if err != nil {
-		return fmt.Errorf("failed to read manifest: %w", err)
+		return nil, err
+	}
+	if config != nil {
+		result.Manifest.Config = configResult(config)
 	}
 
-	var manifest v1.Manifest
-	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
-		return fmt.Errorf("failed to parse manifest: %w", err)
+	supersedes, err := oci.Supersedes(ctx, repo, manifest)
+	if err != nil {
+		return nil, err
 	}
+	result.Manifest.Supersedes = subjectsResult(supersedes)
+	if result.Manifest.Stack {
+		newer, err := oci.SupersededBy(ctx, repo, manifestDesc)
+		if err != nil {
+			return nil, err
+		}
+		result.Manifest.SupersededBy = subjectsResult(newer)
 
-	artifactName := repo.Reference.Repository
-	if tag := repo.Reference.Reference; tag != "" {
-		artifactName = artifactName + ":" + tag
+		deprecations, err := oci.Deprecations(ctx, repo, manifestDesc)
+		if err != nil {
+			return nil, err
+		}
+		// A stack deprecated more than once shows the latest message
+		if n := len(deprecations); n > 0 {
+			d := deprecations[n-1]
+			result.Manifest.Deprecation = &api.Deprecation{Message: d.Message, Created: d.Created}
+		}
+
+		if opts.Attestations {
+			if result.Manifest.Attestations, err = attestationsResult(ctx, repo, manifestDesc); err != nil {
+				return nil, err
+			}
+		}
 	}
-	registry := repo.Reference.Host()
 
-	cli.Printf("Artifact:  %s\n", artifactName)
-	cli.Printf("Registry:  %s\n", registry)
-	cli.Printf("Digest:    %s\n", manifestDesc.Digest.String())
+	if !opts.Summary && !opts.Licenses {
+		return result, nil
+	}
+	if !result.Manifest.Stack {
+		cli.Logger().Warn("Artifact is not a kro RGD stack, skipping summary",
+			"artifactType", result.Manifest.ArtifactType)
+		return result, nil
+	}
+
+	var layers []v1.Descriptor
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == oci.LayerMediaType {
+			layers = append(layers, layer)
+		}
+	}
+	contents, err := fetchLayers(ctx, repo, layers)
+	if err != nil {
+		return nil, err
+	}
 
-	// If the created annotation is present, display it
-	if manifest.Config.Annotations != nil {
-		if created, ok := manifest.Config.Annotations[v1.AnnotationCreated]; ok {
-			if t, err := time.Parse(time.RFC3339, created); err == nil {
-				cli.Printf("Created:   %s\n", t.Format(time.RFC3339))
+	var rgds []*rgd.ResourceGraphDefinition
+	for i, layer := range layers {
+		parsed, err := rgd.Parse(oci.LayerTitle(layer), contents[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", oci.LayerTitle(layer), err)
+		}
+		rgds = append(rgds, parsed...)
+	}
+	result.Summary = summaryResult(rgd.Summarize(rgds))
+
+	if opts.Licenses {
+		for i, image := range result.Summary.Images {
+			// Images live in registries kroctl has no say over, a missing
+			// license is not worth failing the inspection for
+			labels, err := oci.ImageLabels(ctx, image.Name)
+			if err != nil {
+				cli.Logger().Warn("Failed to resolve image labels", "image", image.Name, "error", err.Error())
+				continue
 			}
+			result.Summary.Images[i].Licenses = labels[v1.AnnotationLicenses]
+			result.Summary.Images[i].Source = labels[v1.AnnotationSource]
 		}
 	}
 
-	if len(manifest.Layers) == 0 {
-		cli.Printf("\nNo ResourceGraphDefinitions found in artifact\n")
-		return nil
+	return result, nil
+}
+
+func indexResult(desc v1.Descriptor, index *v1.Index) *api.Index {
+	result := &api.Index{
+		Digest:    desc.Digest.String(),
+		Manifests: make([]api.IndexEntry, 0, len(index.Manifests)),
 	}
+	for _, m := range index.Manifests {
+		typ := m.ArtifactType
+		if typ == "" {
+			typ = m.MediaType
+		}
+		entry := api.IndexEntry{
+			Digest:       m.Digest.String(),
+			ArtifactType: typ,
+			Variant:      m.Annotations[oci.AnnotationVariant],
+		}
+		if m.Platform != nil {
+			entry.Platform = formatPlatform(m.Platform)
+		}
+		result.Manifests = append(result.Manifests, entry)
+	}
+	return result
+}
 
-	cli.Printf("\nResourceGraphDefinitions:\n")
+func manifestResult(desc v1.Descriptor, manifest *v1.Manifest) *api.Manifest {
+	result := &api.Manifest{
+		Digest:       desc.Digest.String(),
+		ArtifactType: oci.TypeOf(manifest),
+		Stack:        oci.IsStack(manifest),
+		Size:         artifactSize(desc, manifest),
+		Annotations:  manifest.Annotations,
+		Layers:       make([]api.Layer, 0, len(manifest.Layers)),
+	}
 
-	w := tabwriter.NewWriter(cli.Writer, 0, 0, 2, ' ', 0)
-	fmt.Fprintf(w, "Name\tDigest\n")
+	// Image spec v1.1 puts the created annotation on the manifest, older
+	// artifacts carry it on the config
+	created, ok := manifest.Annotations[v1.AnnotationCreated]
+	if !ok {
+		created, ok = manifest.Config.Annotations[v1.AnnotationCreated]
+	}
+	if ok {
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			result.Created = t.Format(time.RFC3339)
+		}
+	}
 
 	for _, layer := range manifest.Layers {
-		name := "unknown"
-		if layer.Annotations != nil {
-			if title, ok := layer.Annotations[v1.AnnotationTitle]; ok {
-				name = title
-			}
+		result.Layers = append(result.Layers, api.Layer{
+			Name:      oci.LayerTitle(layer),
+			MediaType: layer.MediaType,
+			Digest:    layer.Digest.String(),
+			Size:      layer.Size,
+		})
+	}
+	return result
+}
+
+func configResult(config *oci.StackConfig) *api.StackConfig {
+	result := &api.StackConfig{
+		Name:         config.Name,
+		Version:      config.Version,
+		RGDs:         make([]api.StackRGD, 0, len(config.RGDs)),
+		Dependencies: append([]string{}, config.Dependencies...),
+		Compatibility: api.Compatibility{
+			KroAPIVersions:   append([]string{}, config.Compatibility.KroAPIVersions...),
+			KubernetesBefore: config.Compatibility.KubernetesBefore,
+		},
+	}
+	for _, r := range config.RGDs {
+		result.RGDs = append(result.RGDs, api.StackRGD{Name: r.Name, API: r.API, File: r.File, Digest: r.Digest})
+	}
+	return result
+}
+
+// subjectsResult describes manifests related through their subject.
+func subjectsResult(descs []v1.Descriptor) []api.Subject {
+	var result []api.Subject
+	for _, desc := range descs {
+		typ := desc.ArtifactType
+		if typ == "" {
+			typ = desc.MediaType
+		}
+		result = append(result, api.Subject{
+			Digest:       desc.Digest.String(),
+			ArtifactType: typ,
+			Created:      desc.Annotations[v1.AnnotationCreated],
+		})
+	}
+	return result
+}
+
+func summaryResult(summary rgd.Summary) *api.Summary {
+	result := &api.Summary{
+		RGDs:         summary.RGDs,
+		APIs:         append([]string{}, summary.APIs...),
+		Resources:    make([]api.KindCount, 0, len(summary.Resources)),
+		ExternalRefs: summary.ExternalRefs,
+	}
+	for _, r := range summary.Resources {
+		result.Resources = append(result.Resources, api.KindCount{Kind: r.Kind, Count: r.Count})
+	}
+	for _, image := range summary.Images {
+		result.Images = append(result.Images, api.Image{Name: image})
+	}
+	return result
+}
+
+// printInspect writes an inspect result for humans.
+func printInspect(s *view.Stream, result *api.InspectResult) error {
+	s.Printf("Artifact:  %s\n", strings.TrimPrefix(result.Reference, result.Registry+"/"))
+	s.Printf("Registry:  %s\n", result.Registry)
+
+	if result.Index != nil {
+		printIndex(s, result.Index)
+		if result.Manifest == nil {
+			return nil
 		}
+		s.Println()
+	}
+
+	m := result.Manifest
+	s.Printf("Digest:    %s\n", m.Digest)
+	s.Printf("Type:      %s\n", describeType(m.ArtifactType))
+	s.Printf("Size:      %s\n", formatSize(m.Size))
+	if m.Created != "" {
+		s.Printf("Created:   %s\n", m.Created)
+	}
+	if m.Deprecation != nil {
+		s.Println()
+		s.Println(view.Caution.Sprintf("DEPRECATED: %s", m.Deprecation.Message))
+	}
+
+	if !m.Stack {
+		// Artifacts pushed by other tools get a generic listing
+		printLayers(s, m.Layers)
+		printRelations(s, m)
+		return nil
+	}
+
+	if len(m.Layers) == 0 {
+		s.Printf("\nNo ResourceGraphDefinitions found in artifact\n")
+		return nil
+	}
 
-		fmt.Fprintf(w, "%s\t%s\n", name, layer.Digest.String())
+	s.Printf("\nResourceGraphDefinitions:\n")
+
+	w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name\tSize\tDigest\n")
+
+	var checksums, docs, examples, archive *api.Layer
+	for _, layer := range m.Layers {
+		switch layer.MediaType {
+		case oci.ChecksumsMediaType:
+			checksums = &layer
+		case oci.DocsMediaType:
+			docs = &layer
+		case oci.ExamplesMediaType:
+			examples = &layer
+		case oci.ArchiveMediaType:
+			archive = &layer
+		default:
+			fmt.Fprintf(w, "%s\t%s\t%s\n", layer.Name, formatSize(layer.Size), layer.Digest)
+		}
 	}
 
 	w.Flush()
 
+	if checksums != nil || docs != nil || examples != nil || archive != nil {
+		s.Println()
+	}
+	if checksums != nil {
+		s.Printf("Checksums: %s (%s)\n", checksums.Name, checksums.Digest)
+	}
+	if docs != nil {
+		s.Printf("Docs:      %s (%s)\n", formatSize(docs.Size), docs.Digest)
+	}
+	if examples != nil {
+		s.Printf("Examples:  %s (%s)\n", formatSize(examples.Size), examples.Digest)
+	}
+	if archive != nil {
+		s.Printf("Archive:   %s (%s)\n", formatSize(archive.Size), archive.Digest)
+	}
+
+	if m.Config != nil {
+		printConfig(s, m.Config)
+	}
+
+	printRelations(s, m)
+	printAttestations(s, m.Attestations)
+
+	if result.Summary != nil {
+		printSummary(s, result.Summary)
+	}
+
 	return nil
 }
+
+// printRelations lists the versions a stack supersedes and is superseded
+// by. Subjects that are not stacks were set by other tools and are shown
+// with their type.
+func printRelations(s *view.Stream, m *api.Manifest) {
+	if len(m.Supersedes) == 0 && len(m.SupersededBy) == 0 {
+		return
+	}
+	s.Printf("\nVersions:\n")
+	for _, r := range m.SupersededBy {
+		s.Printf("  Superseded by  %s\n", describeSubject(r))
+	}
+	for _, r := range m.Supersedes {
+		s.Printf("  Supersedes     %s\n", describeSubject(r))
+	}
+}
+
+// attestationsResult lists the attestations of a stack with their
+// predicates.
+func attestationsResult(ctx context.Context, repo *remote.Repository, desc v1.Descriptor) ([]api.Attestation, error) {
+	attestations, err := oci.Attestations(ctx, repo, desc)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]api.Attestation, 0, len(attestations))
+	for _, a := range attestations {
+		statement, err := oci.FetchStatement(ctx, repo, a)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, api.Attestation{
+			Digest:        a.Digest,
+			PredicateType: a.PredicateType,
+			Created:       a.Created,
+			Predicate:     statement.Predicate,
+		})
+	}
+	return results, nil
+}
+
+// printAttestations lists the attestations of a stack. The predicates are
+// left to the JSON output.
+func printAttestations(s *view.Stream, attestations []api.Attestation) {
+	if len(attestations) == 0 {
+		return
+	}
+	s.Printf("\nAttestations:\n")
+	w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Predicate type\tCreated\tDigest\n")
+	for _, a := range attestations {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", a.PredicateType, orDash(a.Created), a.Digest)
+	}
+	w.Flush()
+}
+
+func describeSubject(subject api.Subject) string {
+	desc := subject.Digest
+	if subject.ArtifactType != oci.ArtifactType && subject.ArtifactType != oci.ArtifactTypeV1 {
+		desc += " (" + describeType(subject.ArtifactType) + ")"
+	}
+	if subject.Created != "" {
+		desc += "  " + subject.Created
+	}
+	return desc
+}
+
+// printIndex lists the manifests of an index.
+func printIndex(s *view.Stream, index *api.Index) {
+	s.Printf("Digest:    %s\n", index.Digest)
+	s.Printf("Type:      index of %d manifest(s)\n", len(index.Manifests))
+
+	s.Printf("\nManifests:\n")
+	w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Variant\tDigest\tType\tPlatform\n")
+	for _, m := range index.Manifests {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			orDash(m.Variant), m.Digest, describeType(m.ArtifactType), orDash(m.Platform))
+	}
+	w.Flush()
+}
+
+// printLayers lists layers of any media type, for artifacts that were not
+// pushed by kroctl.
+func printLayers(s *view.Stream, layers []api.Layer) {
+	if len(layers) == 0 {
+		s.Printf("\nNo layers found in artifact\n")
+		return
+	}
+
+	s.Printf("\nLayers:\n")
+	w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name\tMedia Type\tSize\tDigest\n")
+	for _, layer := range layers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", layer.Name, layer.MediaType, formatSize(layer.Size), layer.Digest)
+	}
+	w.Flush()
+}
+
+// printConfig writes what the config of a stack says about it.
+func printConfig(s *view.Stream, config *api.StackConfig) {
+	s.Printf("\nStack:\n")
+	s.Printf("  Name:          %s\n", orDash(config.Name))
+	s.Printf("  Version:       %s\n", orDash(config.Version))
+
+	var apis []string
+	for _, r := range config.RGDs {
+		if r.API != "" {
+			apis = append(apis, r.API)
+		}
+	}
+	if len(apis) > 0 {
+		s.Printf("  APIs:          %s\n", strings.Join(apis, ", "))
+	}
+	if len(config.Dependencies) > 0 {
+		s.Printf("  Requires:      %s\n", strings.Join(config.Dependencies, ", "))
+	}
+	if len(config.Compatibility.KroAPIVersions) > 0 {
+		s.Printf("  kro:           %s\n", strings.Join(config.Compatibility.KroAPIVersions, ", "))
+	}
+	if config.Compatibility.KubernetesBefore != "" {
+		s.Printf("  Kubernetes:    before %s\n", config.Compatibility.KubernetesBefore)
+	}
+}
+
+// printSummary writes the APIs and resource kinds of a stack.
+func printSummary(s *view.Stream, summary *api.Summary) {
+	s.Printf("\nSummary:\n")
+	s.Printf("  ResourceGraphDefinitions:  %d\n", summary.RGDs)
+	if len(summary.APIs) > 0 {
+		s.Printf("  APIs:                      %s\n", strings.Join(summary.APIs, ", "))
+	}
+	if summary.ExternalRefs > 0 {
+		s.Printf("  External references:       %d\n", summary.ExternalRefs)
+	}
+
+	if len(summary.Resources) == 0 {
+		return
+	}
+
+	s.Printf("\nManaged resources:\n")
+	w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Kind\tCount\n")
+	for _, r := range summary.Resources {
+		fmt.Fprintf(w, "%s\t%d\n", r.Kind, r.Count)
+	}
+	w.Flush()
+
+	if len(summary.Images) == 0 {
+		return
+	}
+
+	s.Printf("\nImages:\n")
+	w = tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Image\tLicense\tSource\n")
+	for _, image := range summary.Images {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", image.Name, orDash(image.Licenses), orDash(image.Source))
+	}
+	w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// knownTypes labels the artifact types inspect is likely to come across.
+var knownTypes = map[string]string{
+	oci.ArtifactType:                                       "kro RGD stack",
+	oci.ArtifactTypeV1:                                     "kro RGD stack",
+	oci.ApprovalArtifactType:                               "kro stack approval",
+	v1.MediaTypeImageConfig:                                "container image",
+	"application/vnd.docker.container.image.v1+json":       "container image",
+	"application/vnd.cncf.flux.config.v1+json":             "Flux artifact",
+	"application/vnd.cncf.helm.config.v1+json":             "Helm chart",
+	"application/vnd.oci.empty.v1+json":                    "generic artifact",
+	"application/vnd.unknown.config.v1+json":               "generic artifact",
+	v1.MediaTypeImageManifest:                              "image manifest",
+	"application/vnd.docker.distribution.manifest.v2+json": "image manifest",
+}
+
+// describeType adds a human-readable label to well-known artifact types.
+func describeType(typ string) string {
+	if typ == "" {
+		return "unknown"
+	}
+	if label, ok := knownTypes[typ]; ok {
+		return fmt.Sprintf("%s (%s)", label, typ)
+	}
+	return typ
+}
+
+func formatPlatform(p *v1.Platform) string {
+	if p == nil {
+		return "-"
+	}
+	platform := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		platform += "/" + p.Variant
+	}
+	return platform
+}
+
+// artifactSize is the number of bytes stored for an artifact: its
+// manifest, config, and layers.
+func artifactSize(manifestDesc v1.Descriptor, manifest *v1.Manifest) int64 {
+	size := manifestDesc.Size + manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size
+}
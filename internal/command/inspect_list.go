@@ -0,0 +1,231 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// inspectConcurrency bounds the number of references inspected at once.
+const inspectConcurrency = 4
+
+// RunInspectList inspects several references concurrently and prints them
+// side by side. With AllTags, every tag of the repository of each
+// reference is inspected instead.
+func RunInspectList(ctx context.Context, cli *CLI, references []string, opts *InspectOptions) error {
+	if opts.Summary || opts.Licenses {
+		return fmt.Errorf("--summary and --licenses inspect a single reference")
+	}
+
+	if opts.AllTags {
+		var err error
+		if references, err = expandTags(ctx, references); err != nil {
+			return err
+		}
+		if len(references) == 0 {
+			return fmt.Errorf("no tags found")
+		}
+	}
+
+	stacks, _ := inspectStacks(ctx, cli, references, opts)
+	list := &api.InspectList{
+		TypeMeta: api.NewTypeMeta(api.KindInspectList),
+		Stacks:   stacks,
+	}
+
+	err := render(cli, list, func(s *view.Stream, list *api.InspectList) error {
+		w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Reference\tVersion\tCreated\tRGDs\tSize\tSigned\n")
+		for _, stack := range list.Stacks {
+			if stack.Error != "" {
+				fmt.Fprintf(w, "%s\t%s\n", stack.Reference, view.Caution.Sprintf("error: %s", stack.Error))
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				stack.Reference, orDash(stack.Version), orDash(stack.Created),
+				stack.RGDs, formatSize(stack.Size), yesNo(stack.Signed))
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return inspectFailures(list.Stacks)
+}
+
+// inspectFailures returns an error when any of the stacks failed to
+// inspect.
+func inspectFailures(stacks []api.InspectedStack) error {
+	var failed int
+	for _, stack := range stacks {
+		if stack.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d reference(s) could not be inspected", failed)
+	}
+	return nil
+}
+
+// inspectStacks inspects the references with up to inspectConcurrency in
+// flight. Rows and results are returned in the order of references; the
+// result of a reference that failed is nil.
+func inspectStacks(ctx context.Context, cli *CLI, references []string, opts *InspectOptions) ([]api.InspectedStack, []*api.InspectResult) {
+	stacks := make([]api.InspectedStack, len(references))
+	results := make([]*api.InspectResult, len(references))
+
+	sem := make(chan struct{}, inspectConcurrency)
+	var wg sync.WaitGroup
+	for i, reference := range references {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			stacks[i], results[i] = inspectStack(ctx, cli, reference, opts)
+		}()
+	}
+	wg.Wait()
+	return stacks, results
+}
+
+// inspectStack describes a single row of the list. Failures are recorded
+// in the row, so one missing tag does not hide the others.
+func inspectStack(ctx context.Context, cli *CLI, reference string, opts *InspectOptions) (api.InspectedStack, *api.InspectResult) {
+	stack := api.InspectedStack{Reference: reference}
+
+	result, err := inspect(ctx, cli, &InspectOptions{
+		Reference:       reference,
+		Variant:         opts.Variant,
+		AnyArtifactType: opts.AnyArtifactType,
+	})
+	if err != nil {
+		stack.Error = oci.ExplainError(err).Error()
+		return stack, nil
+	}
+
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		stack.Error = err.Error()
+		return stack, nil
+	}
+	stack.Version = repo.Reference.Reference
+
+	m := result.Manifest
+	if m == nil {
+		// An index of variants without --variant has no single stack
+		stack.Digest = result.Index.Digest
+		stack.Error = fmt.Sprintf("index of %d manifests, pick one with --variant", len(result.Index.Manifests))
+		return stack, result
+	}
+
+	stack.Digest = m.Digest
+	stack.Created = m.Created
+	stack.Size = m.Size
+	stack.Deprecation = m.Deprecation
+	if m.Config != nil {
+		stack.RGDs = len(m.Config.RGDs)
+		if m.Config.Version != "" {
+			stack.Version = m.Config.Version
+		}
+	} else {
+		for _, layer := range m.Layers {
+			if layer.MediaType == oci.LayerMediaType {
+				stack.RGDs++
+			}
+		}
+	}
+
+	signed, err := oci.Signed(ctx, repo, v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    digest.Digest(m.Digest),
+	})
+	if err != nil {
+		stack.Error = err.Error()
+		return stack, result
+	}
+	stack.Signed = signed
+	return stack, result
+}
+
+// readRefFile reads references from a file with one per line, or from
+// stdin when path is -. Blank lines and # comments are skipped.
+func readRefFile(path string, stdin io.Reader) ([]string, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open reference file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var refs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read references: %w", err)
+	}
+	return refs, nil
+}
+
+// expandTags returns a reference for every tag in the repositories of the
+// given references, sorted by version where the tags are versions.
+// Repositories that fail to list are reported together.
+func expandTags(ctx context.Context, references []string) ([]string, error) {
+	var expanded []string
+	var errs []error
+	for _, reference := range references {
+		repo, err := oci.SetupRepository(reference)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tags, err := oci.ListTags(ctx, repo)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slices.SortStableFunc(tags, compareTags)
+		name := repo.Reference.Registry + "/" + repo.Reference.Repository
+		for _, tag := range tags {
+			// Signatures and attestations stored under sha256-<hex> tags
+			// are not stacks
+			if strings.HasPrefix(tag, "sha256-") {
+				continue
+			}
+			expanded = append(expanded, name+":"+tag)
+		}
+	}
+	return expanded, errors.Join(errs...)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,136 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, formatSize(tt.size))
+	}
+}
+
+func TestArtifactSize(t *testing.T) {
+	manifest := &v1.Manifest{
+		Config: v1.Descriptor{Size: 2},
+		Layers: []v1.Descriptor{{Size: 100}, {Size: 200}},
+	}
+
+	assert.Equal(t, int64(802), artifactSize(v1.Descriptor{Size: 500}, manifest))
+}
+
+func TestDescribeType(t *testing.T) {
+	assert.Equal(t, "kro RGD stack (application/vnd.kro.rgd.stack.v1)", describeType("application/vnd.kro.rgd.stack.v1"))
+	assert.Equal(t, "container image (application/vnd.oci.image.config.v1+json)", describeType(v1.MediaTypeImageConfig))
+	assert.Equal(t, "application/vnd.acme.thing", describeType("application/vnd.acme.thing"))
+	assert.Equal(t, "unknown", describeType(""))
+}
+
+func TestFormatPlatform(t *testing.T) {
+	assert.Equal(t, "-", formatPlatform(nil))
+	assert.Equal(t, "linux/amd64", formatPlatform(&v1.Platform{OS: "linux", Architecture: "amd64"}))
+	assert.Equal(t, "linux/arm/v7", formatPlatform(&v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}))
+}
+
+// serveManifest serves a single manifest as a registry does, and returns a
+// reference to it. Blobs are not served.
+func serveManifest(t *testing.T, manifest v1.Manifest) string {
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v2/acme/stack/manifests/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", manifest.MediaType)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(data).String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return strings.TrimPrefix(server.URL, "http://") + "/acme/stack:v1"
+}
+
+// containerImage is the manifest of an image pushed where a stack belongs.
+var containerImage = v1.Manifest{
+	MediaType: v1.MediaTypeImageManifest,
+	Config:    v1.Descriptor{MediaType: v1.MediaTypeImageConfig, Digest: digest.FromString("config"), Size: 6},
+	Layers: []v1.Descriptor{
+		{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.FromString("layer"), Size: 5},
+	},
+}
+
+func TestInspect_RefusesOtherArtifactTypes(t *testing.T) {
+	reference := serveManifest(t, containerImage)
+
+	cli := NewCLI(view.ViewHuman, &bytes.Buffer{}, view.LogLevelSilent)
+	_, err := inspect(context.Background(), cli, &InspectOptions{Reference: reference})
+	require.EqualError(t, err, reference+" is a container image (application/vnd.oci.image.config.v1+json),"+
+		" not a kro RGD stack ("+oci.ArtifactType+"), use --any-artifact-type to read it anyway")
+}
+
+func TestInspect_AnyArtifactType(t *testing.T) {
+	reference := serveManifest(t, containerImage)
+
+	var out bytes.Buffer
+	cli := NewCLI(view.ViewJSON, &out, view.LogLevelSilent)
+	require.NoError(t, RunInspect(context.Background(), cli, &InspectOptions{Reference: reference, AnyArtifactType: true}))
+
+	var result api.InspectResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	require.NotNil(t, result.Manifest)
+	assert.False(t, result.Manifest.Stack)
+	assert.Equal(t, v1.MediaTypeImageConfig, result.Manifest.ArtifactType)
+	assert.Len(t, result.Manifest.Layers, 1)
+}
+
+func TestReadRefFile(t *testing.T) {
+	input := "# fleet\nghcr.io/acme/network:v1.0.0\n\n  ghcr.io/acme/storage:v2.0.0  \n"
+
+	refs, err := readRefFile("-", strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ghcr.io/acme/network:v1.0.0", "ghcr.io/acme/storage:v2.0.0"}, refs)
+
+	path := filepath.Join(t.TempDir(), "refs.txt")
+	require.NoError(t, os.WriteFile(path, []byte(input), 0o644))
+	fromFile, err := readRefFile(path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, refs, fromFile)
+
+	_, err = readRefFile(filepath.Join(t.TempDir(), "missing.txt"), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type MergeOptions struct {
+	Reference string
+	Sources   []string
+}
+
+func NewMergeCommand(cli *CLI) *cobra.Command {
+	opts := MergeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "merge <reference> <source>...",
+		Short: "Combine several RGD stacks into one artifact",
+		Long: "Combine several RGD stacks into one artifact.\n\n" +
+			"Pulls the source artifacts and pushes their ResourceGraphDefinitions\n" +
+			"as a single stack to reference, for teams that ship an umbrella\n" +
+			"platform stack. Identical layers are included once. Files with\n" +
+			"the same name but different contents are rejected.\n\n" +
+			"Manifest annotations of the sources are merged, later sources\n" +
+			"winning on conflicts.\n\n" +
+			"Examples:\n" +
+			"  kroctl merge ghcr.io/acme/platform:v1.0.0 \\\n" +
+			"    ghcr.io/acme/network:v1.2.0 ghcr.io/acme/database:v0.4.1\n",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			opts.Sources = args[1:]
+			return RunMerge(cmd.Context(), cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunMerge(ctx context.Context, cli *CLI, opts *MergeOptions) error {
+	cli.Logger().Info("Merging artifacts",
+		"reference", opts.Reference,
+		"sources", len(opts.Sources))
+
+	merger := newStackMerger()
+	for _, source := range opts.Sources {
+		repo, err := oci.SetupRepository(source)
+		if err != nil {
+			return err
+		}
+
+		_, manifest, err := oci.FetchManifest(ctx, repo, source, "")
+		if err != nil {
+			return err
+		}
+		if !oci.IsStack(manifest) {
+			return fmt.Errorf("%s is not a kro RGD stack", source)
+		}
+
+		if err := merger.add(ctx, cli, source, repo, manifest); err != nil {
+			return err
+		}
+	}
+
+	if len(merger.layers) == 0 {
+		return fmt.Errorf("no ResourceGraphDefinitions found in sources")
+	}
+
+	config, err := oci.NewStackConfig(opts.Reference, merger.layers, merger.contents)
+	if err != nil {
+		return err
+	}
+	manifestDesc, err := oci.PackStack(ctx, merger.store, config, merger.layers, merger.annotations, nil)
+	if err != nil {
+		return err
+	}
+
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+	if err := merger.store.Tag(ctx, manifestDesc, opts.Reference); err != nil {
+		return fmt.Errorf("failed to tag manifest: %w", err)
+	}
+
+	var stats pushStats
+	copyOpts := oras.DefaultCopyOptions
+	copyOpts.PostCopy = stats.copied
+	copyOpts.OnCopySkipped = stats.skipped
+	if _, err := oras.Copy(ctx, merger.store, opts.Reference, repo, opts.Reference, copyOpts); err != nil {
+		return fmt.Errorf("failed to push artifact: %w", err)
+	}
+
+	result := &api.MergeResult{
+		TypeMeta:      api.NewTypeMeta(api.KindMergeResult),
+		Reference:     opts.Reference,
+		Digest:        manifestDesc.Digest.String(),
+		Sources:       opts.Sources,
+		Files:         make([]string, 0, len(merger.layers)),
+		UploadedBytes: stats.uploadedBytes,
+		SkippedBytes:  stats.skippedBytes,
+	}
+	for _, layer := range merger.layers {
+		result.Files = append(result.Files, oci.LayerTitle(layer))
+	}
+
+	err = render(cli, result, func(s *view.Stream, result *api.MergeResult) error {
+		s.Printf("Successfully merged %d RGD file(s) from %d artifact(s) into %s\n",
+			len(result.Files), len(result.Sources), result.Reference)
+		s.Printf("Digest: %s\n", result.Digest)
+		stats.print(s)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cli.auditChange("merge", opts.Reference, result.Digest)
+	return nil
+}
+
+// stackMerger collects the RGD layers and annotations of the source stacks
+// into a single stack.
+type stackMerger struct {
+	store       *memory.Store
+	annotations map[string]string
+	layers      []v1.Descriptor
+	contents    [][]byte
+	titles      map[string]v1.Descriptor
+}
+
+func newStackMerger() *stackMerger {
+	return &stackMerger{
+		store:       memory.New(),
+		annotations: map[string]string{},
+		titles:      map[string]v1.Descriptor{},
+	}
+}
+
+// add merges the stack manifest of source, fetching its layers from
+// fetcher. Layers identical to one of an earlier source are skipped.
+func (m *stackMerger) add(ctx context.Context, cli *CLI, source string, fetcher content.Fetcher, manifest *v1.Manifest) error {
+	for k, v := range manifest.Annotations {
+		// The merged artifact gets its own creation time
+		if k == v1.AnnotationCreated {
+			continue
+		}
+		if old, ok := m.annotations[k]; ok && old != v {
+			cli.Logger().Warn("Overriding annotation", "key", k, "source", source)
+		}
+		m.annotations[k] = v
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != oci.LayerMediaType {
+			cli.Logger().Debug("Skipping non-RGD layer",
+				"source", source,
+				"digest", layer.Digest.String())
+			continue
+		}
+
+		title := oci.LayerTitle(layer)
+		if existing, ok := m.titles[title]; ok {
+			if existing.Digest == layer.Digest {
+				cli.Logger().Debug("Skipping duplicate layer", "source", source, "file", title)
+				continue
+			}
+			return fmt.Errorf("%s in %s conflicts with a different %s from an earlier source", title, source, title)
+		}
+
+		data, err := oci.FetchLayer(ctx, fetcher, layer)
+		if err != nil {
+			return err
+		}
+		if err := m.store.Push(ctx, layer, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to store %s: %w", title, err)
+		}
+
+		m.titles[title] = layer
+		m.layers = append(m.layers, layer)
+		m.contents = append(m.contents, data)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// testManifest reads back the manifest packTestStack pushed.
+func testManifest(t *testing.T, store *memory.Store, desc v1.Descriptor) *v1.Manifest {
+	t.Helper()
+	data, err := content.FetchAll(context.Background(), store, desc)
+	require.NoError(t, err)
+	var manifest v1.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	return &manifest
+}
+
+func TestStackMerger(t *testing.T) {
+	ctx := context.Background()
+	cli := NewCLI(view.ViewHuman, io.Discard, view.LogLevelSilent)
+
+	network, networkDesc := packTestStack(t, "network.yaml", testRGD("network"))
+	app, appDesc := packTestStack(t, "app.yaml", testRGD("app"))
+	networkManifest := testManifest(t, network, networkDesc)
+	networkManifest.Annotations = map[string]string{
+		v1.AnnotationCreated: "2026-01-01T00:00:00Z",
+		v1.AnnotationVendor:  "acme",
+		v1.AnnotationVersion: "v1",
+	}
+	appManifest := testManifest(t, app, appDesc)
+	appManifest.Annotations = map[string]string{
+		v1.AnnotationCreated:     "2026-02-01T00:00:00Z",
+		v1.AnnotationVersion:     "v2",
+		v1.AnnotationDescription: "app",
+	}
+
+	merger := newStackMerger()
+	require.NoError(t, merger.add(ctx, cli, "network", network, networkManifest))
+	require.NoError(t, merger.add(ctx, cli, "app", app, appManifest))
+
+	// Later sources win, and the creation time is left to the merged stack
+	assert.Equal(t, map[string]string{
+		v1.AnnotationVendor:      "acme",
+		v1.AnnotationVersion:     "v2",
+		v1.AnnotationDescription: "app",
+	}, merger.annotations)
+
+	require.Len(t, merger.layers, 2)
+	assert.Equal(t, "network.yaml", oci.LayerTitle(merger.layers[0]))
+	assert.Equal(t, "app.yaml", oci.LayerTitle(merger.layers[1]))
+	assert.Equal(t, [][]byte{testRGD("network"), testRGD("app")}, merger.contents)
+
+	for _, layer := range merger.layers {
+		exists, err := merger.store.Exists(ctx, layer)
+		require.NoError(t, err)
+		assert.True(t, exists, oci.LayerTitle(layer))
+	}
+}
+
+func TestStackMerger_IdenticalLayer(t *testing.T) {
+	ctx := context.Background()
+	cli := NewCLI(view.ViewHuman, io.Discard, view.LogLevelSilent)
+
+	a, aDesc := packTestStack(t, "app.yaml", testRGD("app"))
+	b, bDesc := packTestStack(t, "app.yaml", testRGD("app"))
+
+	merger := newStackMerger()
+	require.NoError(t, merger.add(ctx, cli, "a", a, testManifest(t, a, aDesc)))
+	require.NoError(t, merger.add(ctx, cli, "b", b, testManifest(t, b, bDesc)))
+
+	require.Len(t, merger.layers, 1)
+	assert.Equal(t, "app.yaml", oci.LayerTitle(merger.layers[0]))
+}
+
+func TestStackMerger_ConflictingTitle(t *testing.T) {
+	ctx := context.Background()
+	cli := NewCLI(view.ViewHuman, io.Discard, view.LogLevelSilent)
+
+	a, aDesc := packTestStack(t, "app.yaml", testRGD("app"))
+	b, bDesc := packTestStack(t, "app.yaml", testRGD("other"))
+
+	merger := newStackMerger()
+	require.NoError(t, merger.add(ctx, cli, "a", a, testManifest(t, a, aDesc)))
+	err := merger.add(ctx, cli, "b", b, testManifest(t, b, bDesc))
+	require.EqualError(t, err, "app.yaml in b conflicts with a different app.yaml from an earlier source")
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+// metricsReport is written to the metrics file after every invocation, so
+// CI pipelines can track how long artifact operations take over time.
+type metricsReport struct {
+	Command    string           `json:"command"`
+	Started    time.Time        `json:"started"`
+	DurationMS int64            `json:"durationMs"`
+	Succeeded  bool             `json:"succeeded"`
+	Phases     []phaseMetric    `json:"phases"`
+	Counters   map[string]int64 `json:"counters"`
+	Registry   oci.Metrics      `json:"registry"`
+}
+
+// phaseMetric is how long a step of a command took.
+type phaseMetric struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+// metrics collects the phases and counters of the running command.
+type metrics struct {
+	mu       sync.Mutex
+	phases   []phaseMetric
+	counters map[string]int64
+}
+
+// phase starts timing a step of the command, the returned function ends
+// it. Phases that run more than once are recorded each time.
+func (c *CLI) phase(name string) func() {
+	start := time.Now()
+	return func() {
+		c.metrics.mu.Lock()
+		defer c.metrics.mu.Unlock()
+		c.metrics.phases = append(c.metrics.phases, phaseMetric{
+			Name:       name,
+			DurationMS: time.Since(start).Milliseconds(),
+		})
+	}
+}
+
+// count adds n to a named counter of the command.
+func (c *CLI) count(name string, n int64) {
+	c.metrics.mu.Lock()
+	defer c.metrics.mu.Unlock()
+	if c.metrics.counters == nil {
+		c.metrics.counters = map[string]int64{}
+	}
+	c.metrics.counters[name] += n
+}
+
+// writeMetrics writes the metrics of the command to the file set with
+// --metrics-file or KROCTL_METRICS_FILE, replacing the previous run's.
+func (c *CLI) writeMetrics(command string, started time.Time, succeeded bool) error {
+	if c.MetricsFile == "" {
+		return nil
+	}
+
+	c.metrics.mu.Lock()
+	report := metricsReport{
+		Command:    command,
+		Started:    started.UTC(),
+		DurationMS: time.Since(started).Milliseconds(),
+		Succeeded:  succeeded,
+		Phases:     append([]phaseMetric{}, c.metrics.phases...),
+		Counters:   map[string]int64{},
+		Registry:   oci.ReadMetrics(),
+	}
+	for name, n := range c.metrics.counters {
+		report.Counters[name] = n
+	}
+	c.metrics.mu.Unlock()
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+	if err := os.WriteFile(c.MetricsFile, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return nil
+}
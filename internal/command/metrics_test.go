@@ -0,0 +1,43 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	cli := NewCLI(view.ViewHuman, &bytes.Buffer{}, view.LogLevelSilent)
+	cli.MetricsFile = filepath.Join(t.TempDir(), "metrics.json")
+
+	done := cli.phase("validate")
+	done()
+	cli.count("skippedBlobs", 2)
+	cli.count("skippedBlobs", 1)
+
+	require.NoError(t, cli.writeMetrics("kroctl push", time.Now(), true))
+
+	data, err := os.ReadFile(cli.MetricsFile)
+	require.NoError(t, err)
+	var report metricsReport
+	require.NoError(t, json.Unmarshal(data, &report))
+
+	assert.Equal(t, "kroctl push", report.Command)
+	assert.True(t, report.Succeeded)
+	require.Len(t, report.Phases, 1)
+	assert.Equal(t, "validate", report.Phases[0].Name)
+	assert.Equal(t, int64(3), report.Counters["skippedBlobs"])
+}
+
+func TestWriteMetrics_Disabled(t *testing.T) {
+	cli := NewCLI(view.ViewHuman, &bytes.Buffer{}, view.LogLevelSilent)
+	assert.NoError(t, cli.writeMetrics("kroctl push", time.Now(), true))
+}
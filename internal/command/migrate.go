@@ -0,0 +1,127 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type MigrateArtifactOptions struct {
+	Reference string
+}
+
+func NewMigrateArtifactCommand(cli *CLI) *cobra.Command {
+	opts := MigrateArtifactOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "migrate-artifact <reference>",
+		Short: "Rewrite an RGD stack in the current artifact format",
+		Long: "Rewrite an RGD stack in the current artifact format.\n\n" +
+			"Stacks pushed as " + oci.ArtifactTypeV1 + " have an\n" +
+			"empty config. Migrating repacks the manifest as\n" +
+			oci.ArtifactType + " with a config that indexes the\n" +
+			"RGDs and their dependencies, and moves the tag to it. Layers and\n" +
+			"annotations are kept, so the files of the stack do not change.\n\n" +
+			"kroctl reads both formats; migrating lets other tools see what a\n" +
+			"stack holds from its config alone.\n\n" +
+			"Examples:\n" +
+			"  kroctl migrate-artifact ghcr.io/acme/kro-stack:v1.0.0\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunMigrateArtifact(cmd.Context(), cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunMigrateArtifact(ctx context.Context, cli *CLI, opts *MigrateArtifactOptions) error {
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	desc, err := oci.Resolve(ctx, repo, opts.Reference)
+	if err != nil {
+		return err
+	}
+	if oci.IsIndex(desc.MediaType) {
+		return fmt.Errorf("%s is an index of variants, push each variant again to migrate it", opts.Reference)
+	}
+
+	_, manifest, err := oci.FetchManifest(ctx, repo, opts.Reference, "")
+	if err != nil {
+		return err
+	}
+	if !oci.IsStack(manifest) {
+		return fmt.Errorf("%s is not a kro RGD stack", opts.Reference)
+	}
+	result := &api.MigrateResult{
+		TypeMeta:     api.NewTypeMeta(api.KindMigrateResult),
+		Reference:    opts.Reference,
+		ArtifactType: oci.ArtifactType,
+		Digest:       desc.Digest.String(),
+	}
+	if oci.TypeOf(manifest) == oci.ArtifactType {
+		return render(cli, result, printMigrate)
+	}
+
+	contents := make([][]byte, len(manifest.Layers))
+	var rgdLayers []v1.Descriptor
+	var indexes []int
+	for i, layer := range manifest.Layers {
+		if layer.MediaType == oci.LayerMediaType {
+			rgdLayers = append(rgdLayers, layer)
+			indexes = append(indexes, i)
+		}
+	}
+	fetched, err := fetchLayers(ctx, repo, rgdLayers)
+	if err != nil {
+		return err
+	}
+	for i, data := range fetched {
+		contents[indexes[i]] = data
+	}
+
+	config, err := oci.NewStackConfig(opts.Reference, manifest.Layers, contents)
+	if err != nil {
+		return err
+	}
+	// The layers are in the repository already, only the config and the
+	// manifest are pushed
+	migrated, err := oci.PackStack(ctx, repo, config, manifest.Layers, manifest.Annotations, manifest.Subject)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Reference.ValidateReferenceAsTag(); err == nil {
+		if err := repo.Tag(ctx, migrated, repo.Reference.Reference); err != nil {
+			return fmt.Errorf("failed to tag migrated manifest: %w", err)
+		}
+	}
+	result.Migrated = true
+	result.Digest = migrated.Digest.String()
+	result.Previous = desc.Digest.String()
+	if err := render(cli, result, printMigrate); err != nil {
+		return err
+	}
+	cli.auditChange("migrate-artifact", opts.Reference, result.Digest)
+	return nil
+}
+
+func printMigrate(s *view.Stream, result *api.MigrateResult) error {
+	if !result.Migrated {
+		s.Printf("%s is already a %s artifact\n", result.Reference, result.ArtifactType)
+		return nil
+	}
+	s.Printf("Migrated %s to %s\n", result.Reference, result.ArtifactType)
+	s.Printf("Digest: %s (was %s)\n", result.Digest, result.Previous)
+	return nil
+}
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// notifyTimeout bounds the delivery of an event to a single URL.
+const notifyTimeout = 10 * time.Second
+
+// pushEvent is posted to the notify URLs after a successful push.
+type pushEvent struct {
+	Event     string    `json:"event"`
+	Time      time.Time `json:"time"`
+	Reference string    `json:"reference"`
+	Digest    string    `json:"digest"`
+	Files     []string  `json:"files"`
+	Pusher    string    `json:"pusher"`
+	Host      string    `json:"host"`
+}
+
+// slackMessage is the payload of a Slack incoming webhook.
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// isSlackWebhook reports whether u is a Slack incoming webhook, which
+// expects a message instead of the event.
+func isSlackWebhook(u string) bool {
+	parsed, err := url.Parse(u)
+	return err == nil && parsed.Host == "hooks.slack.com"
+}
+
+// notifyPush posts a push event to every URL. The push already happened, so
+// failed deliveries are reported as warnings rather than errors.
+func (c *CLI) notifyPush(ctx context.Context, urls []string, reference, digest string, files []string) {
+	if len(urls) == 0 {
+		return
+	}
+
+	event := pushEvent{
+		Event:     "push",
+		Time:      time.Now().UTC(),
+		Reference: reference,
+		Digest:    digest,
+		Files:     files,
+		Pusher:    currentUser(),
+	}
+	event.Host, _ = os.Hostname()
+
+	for _, u := range urls {
+		var payload any = event
+		if isSlackWebhook(u) {
+			payload = slackMessage{Text: fmt.Sprintf("%s pushed %s (%d file(s))\n%s",
+				event.Pusher, event.Reference, len(event.Files), event.Digest)}
+		}
+		if err := postJSON(ctx, u, payload); err != nil {
+			c.warn("failed to notify %s: %s", notifyTarget(u), err)
+			continue
+		}
+		c.Logger().Debug("Sent push notification", "url", notifyTarget(u))
+	}
+}
+
+func postJSON(ctx context.Context, u string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The error repeats the URL, which may embed a token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// notifyTarget returns the scheme and host of a notify URL. Webhook URLs
+// carry their secret in the path or query, keep it out of logs.
+func notifyTarget(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return "invalid URL"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// notifyURLs splits the comma-separated URLs of KROCTL_NOTIFY.
+func notifyURLs(env string) []string {
+	var urls []string
+	for _, u := range strings.Split(env, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
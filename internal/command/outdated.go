@@ -0,0 +1,149 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// Statuses of a stack in an outdated report.
+const (
+	OutdatedStatusCurrent     = "up to date"
+	OutdatedStatusAvailable   = "update available"
+	OutdatedStatusUnversioned = "not versioned"
+)
+
+type OutdatedOptions struct {
+	References []string
+	VendorDir  string
+}
+
+func NewOutdatedCommand(cli *CLI) *cobra.Command {
+	opts := OutdatedOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "outdated [reference]...",
+		Short: "Report stacks with newer versions available",
+		Long: "Report stacks with newer versions available.\n\n" +
+			"Compares the tag of each stack against the newest semantic\n" +
+			"version tag with the same prefix in its repository. Prereleases\n" +
+			"only count for stacks that are on a prerelease themselves. Stacks\n" +
+			"pinned by digest or tagged with something other than a version,\n" +
+			"such as latest, are reported as not versioned.\n\n" +
+			"Without references, the stacks recorded in the vendor.yaml of\n" +
+			"the vendor directory are checked, see kroctl vendor.\n\n" +
+			"The command fails when updates are available, so automation can\n" +
+			"act on the exit code.\n\n" +
+			"Examples:\n" +
+			"  kroctl outdated\n\n" +
+			"  kroctl outdated --vendor-dir third_party/kro --json\n\n" +
+			"  kroctl outdated ghcr.io/acme/network:v1.2.0\n",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.References = args
+			return RunOutdated(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.VendorDir, "vendor-dir", filepath.Join("vendor", "kro"), "Vendor directory to read the stacks from")
+
+	return cmd
+}
+
+func RunOutdated(ctx context.Context, cli *CLI, opts *OutdatedOptions) error {
+	references := opts.References
+	if len(references) == 0 {
+		manifestPath := filepath.Join(opts.VendorDir, vendorManifestFile)
+		manifest, err := readVendorManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+		for _, stack := range manifest.Stacks {
+			references = append(references, stack.Reference)
+		}
+		if len(references) == 0 {
+			return fmt.Errorf("no stacks found in %s, pass references or vendor stacks with kroctl vendor", manifestPath)
+		}
+	}
+
+	report, err := checkOutdated(ctx, cli, references)
+	if err != nil {
+		return err
+	}
+
+	err = render(cli, report, func(s *view.Stream, report *api.OutdatedReport) error {
+		w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Stack\tCurrent\tLatest\tStatus\n")
+		for _, stack := range report.Stacks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", stack.Reference, stack.Current, orDash(stack.Latest), stack.Status)
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	if report.Outdated > 0 {
+		return fmt.Errorf("%d stack(s) have updates available", report.Outdated)
+	}
+	return nil
+}
+
+// checkOutdated compares the tag of each reference with the newest version
+// in its repository.
+func checkOutdated(ctx context.Context, cli *CLI, references []string) (*api.OutdatedReport, error) {
+	report := &api.OutdatedReport{
+		TypeMeta: api.NewTypeMeta(api.KindOutdatedReport),
+		Stacks:   make([]api.OutdatedStack, 0, len(references)),
+	}
+	// Vendor directories can hold several versions of a repository
+	tagsByRepository := map[string][]string{}
+	for _, reference := range references {
+		repo, err := oci.SetupRepository(reference)
+		if err != nil {
+			return nil, err
+		}
+
+		stack := api.OutdatedStack{Reference: reference, Current: repo.Reference.Reference, Status: OutdatedStatusUnversioned}
+		current, ok := parseSemver(repo.Reference.Reference)
+		if ok {
+			name := repo.Reference.Registry + "/" + repo.Reference.Repository
+			tags, listed := tagsByRepository[name]
+			if !listed {
+				if tags, err = oci.ListTags(ctx, repo); err != nil {
+					return nil, err
+				}
+				tagsByRepository[name] = tags
+			}
+
+			stack.Status = OutdatedStatusCurrent
+			if latest, ok := newestMatching(current, tags); ok && latest.compare(current) > 0 {
+				stack.Latest = latest.String()
+				stack.Status = OutdatedStatusAvailable
+				report.Outdated++
+			}
+		}
+		cli.Logger().Debug("Checked stack", "reference", reference, "status", stack.Status)
+		report.Stacks = append(report.Stacks, stack)
+	}
+
+	return report, nil
+}
+
+// newestMatching returns the highest version among tags with the prefix of
+// current. Prereleases are only considered when current is one.
+func newestMatching(current semver, tags []string) (semver, bool) {
+	var matching []string
+	for _, tag := range tags {
+		if v, ok := parseSemver(tag); ok && v.Prefix == current.Prefix {
+			matching = append(matching, tag)
+		}
+	}
+	return latestSemver(matching, current.Prerelease != "")
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type OwnersOptions struct {
+	Reference string
+	Variant   string
+}
+
+func NewOwnersCommand(cli *CLI) *cobra.Command {
+	opts := OwnersOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "owners <reference>",
+		Short: "Show who owns an RGD stack",
+		Long: "Show who owns an RGD stack.\n\n" +
+			"Prints the owning team, Slack channel, and on-call escalation\n" +
+			"recorded with kroctl push --team, --slack, and --escalation, so\n" +
+			"consumers know whom to contact when a stack misbehaves. Only the\n" +
+			"manifest is fetched.\n\n" +
+			"Examples:\n" +
+			"  kroctl owners ghcr.io/acme/kro-stack:v1.2.0\n\n" +
+			"  kroctl owners ghcr.io/acme/kro-stack:v1.2.0 --format '{{.Slack}}'\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunOwners(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Variant to read when the reference is an index of variants")
+
+	return cmd
+}
+
+func RunOwners(ctx context.Context, cli *CLI, opts *OwnersOptions) error {
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	desc, manifest, err := oci.FetchManifest(ctx, repo, opts.Reference, opts.Variant)
+	if err != nil {
+		return err
+	}
+
+	owners := oci.OwnersOf(manifest.Annotations)
+	result := &api.Owners{
+		TypeMeta:   api.NewTypeMeta(api.KindOwners),
+		Reference:  opts.Reference,
+		Digest:     desc.Digest.String(),
+		Team:       owners.Team,
+		Slack:      owners.Slack,
+		Escalation: owners.Escalation,
+	}
+
+	return render(cli, result, func(s *view.Stream, result *api.Owners) error {
+		if owners.IsZero() {
+			s.Printf("No owners recorded for %s\n", result.Reference)
+			return nil
+		}
+		s.Printf("Team:        %s\n", orDash(result.Team))
+		s.Printf("Slack:       %s\n", orDash(result.Slack))
+		s.Printf("Escalation:  %s\n", orDash(result.Escalation))
+		return nil
+	})
+}
@@ -1,22 +1,49 @@
 package command
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry"
 
+	"github.com/bschaatsbergen/kroctl/api"
 	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/scan"
+	"github.com/bschaatsbergen/kroctl/internal/view"
 )
 
+// archiveFile is the title of the layer added with --archive.
+const archiveFile = "rgds.tar.gz"
+
 type PushOptions struct {
-	Filenames []string
-	Reference string
+	Filenames  []string
+	Reference  string
+	Variant    string
+	Supersedes string
+	Owners     oci.Owners
+	Notify     []string
+	Checksums  bool
+	Archive    bool
+	Docs       string
+	Examples   string
+	// FailOnSeverity blocks the push when an image the stack deploys has a
+	// vulnerability of this severity or worse
+	FailOnSeverity string
+	Scanner        string
+	ScanReports    []string
 }
 
 func NewPushCommand(cli *CLI) *cobra.Command {
@@ -27,11 +54,56 @@ func NewPushCommand(cli *CLI) *cobra.Command {
 		Short: "Push ResourceGraphDefinitions to an OCI registry",
 		Long: "Push ResourceGraphDefinitions to an OCI registry.\n\n" +
 			"Packages and pushes ResourceGraphDefinitions as an OCI artifact\n" +
-			"to a specified registry. The RGDs must be valid YAML files.\n\n" +
+			"to a specified registry. The RGDs are validated before pushing,\n" +
+			"see kroctl validate for the checks that are applied.\n\n" +
+			"With --checksums, a SHA256SUMS layer listing the checksum of\n" +
+			"every file is added, for consumers that require explicit\n" +
+			"checksum files.\n\n" +
+			"With --archive, a gzipped tarball of all RGD files is added as a\n" +
+			"layer of its own, for consumers that read a single layer of an\n" +
+			"artifact. kroctl generate flux requires it.\n\n" +
+			"With --variant, the stack is added to an OCI index under the tag\n" +
+			"instead of replacing it, so related stacks such as per-cloud\n" +
+			"variants can share a tag. Pushing a variant again replaces it.\n\n" +
+			"With --docs and --examples, a directory of documentation or\n" +
+			"example instances is added as a layer of its own. They travel with\n" +
+			"the stack but are not RGDs: export only writes them with\n" +
+			"--with-docs.\n\n" +
+			"When KROCTL_AUDIT_LOG names a file, every push, merge, and split\n" +
+			"is recorded in it as a JSON line with the user, reference, and\n" +
+			"digest.\n\n" +
+			"With --notify, or KROCTL_NOTIFY holding comma-separated URLs, a\n" +
+			"JSON event with the reference, digest, files, and pusher is posted\n" +
+			"to each URL after the push. Slack incoming webhooks get a message\n" +
+			"instead. Failed deliveries are reported but do not fail the push.\n\n" +
+			"In GitHub Actions, the reference and digest are written to\n" +
+			"GITHUB_OUTPUT as the reference and digest step outputs.\n\n" +
+			"With --team, --slack, and --escalation, the owners of the stack\n" +
+			"are recorded as annotations, see kroctl owners.\n\n" +
+			"With --supersedes, the stack names an earlier version in the same\n" +
+			"repository, by tag or digest, as its OCI subject. kroctl inspect\n" +
+			"follows these links to show which versions a stack replaces and\n" +
+			"which replace it.\n\n" +
+			"A reference of the form oci-layout:<path>:<tag> pushes to an OCI\n" +
+			"image layout directory instead of a registry, which is created\n" +
+			"when missing. Other OCI tooling, such as oras and skopeo, reads\n" +
+			"these directories. The other kroctl commands read from a registry\n" +
+			"and reject oci-layout: references.\n\n" +
+			"With --fail-on-severity, the images the RGDs deploy are scanned\n" +
+			"before anything is pushed, as with kroctl scan, and the push is\n" +
+			"refused when any of them has a vulnerability of that severity or\n" +
+			"worse. The scan report is printed instead of the push result.\n\n" +
 			"Examples:\n" +
 			"  kroctl push localhost:5001/kro-stack-network:v1.0.0 \\\n" +
 			"    -f stack.yaml -f subnet.yaml -f vpc.yaml\n\n" +
-			"  kroctl push ghcr.io/myorg/kro-stack:latest -f ./rgds/\n",
+			"  kroctl push ghcr.io/myorg/kro-stack:latest -f ./rgds/\n\n" +
+			"  kroctl push ghcr.io/myorg/kro-stack:v1.0.0 --variant aws -f ./aws/\n\n" +
+			"  kroctl push ghcr.io/myorg/kro-stack:v1.0.0 -f ./rgds/ --docs ./docs --examples ./examples\n\n" +
+			"  kroctl push ghcr.io/myorg/kro-stack:v1.1.0 -f ./rgds/ --supersedes v1.0.0\n\n" +
+			"  kroctl push ghcr.io/myorg/kro-stack:v1.1.0 -f ./rgds/ --team platform --slack '#platform-help'\n\n" +
+			"  kroctl push ghcr.io/myorg/kro-stack:v1.1.0 -f ./rgds/ --notify https://hooks.example.com/releases\n\n" +
+			"  kroctl push ghcr.io/myorg/kro-stack:v1.1.0 -f ./rgds/ --fail-on-severity critical\n\n" +
+			"  kroctl push oci-layout:./out:v1.0.0 -f ./rgds/\n",
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Reference = args[0]
@@ -41,51 +113,215 @@ func NewPushCommand(cli *CLI) *cobra.Command {
 
 	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
 		[]string{}, "RGD files or directories to push (required)")
+	cmd.Flags().BoolVar(&opts.Checksums, "checksums", false, "Add a SHA256SUMS layer with the checksums of all files")
+	cmd.Flags().BoolVar(&opts.Archive, "archive", false, "Add a gzipped tarball of all RGD files as a layer, as Flux requires")
+	cmd.Flags().StringVar(&opts.Docs, "docs", "", "Directory of documentation to ship with the stack")
+	cmd.Flags().StringVar(&opts.Examples, "examples", "", "Directory of example instances to ship with the stack")
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Add the stack to an index under the tag as this variant")
+	cmd.Flags().StringVar(&opts.Owners.Team, "team", "", "Team that owns the stack")
+	cmd.Flags().StringVar(&opts.Owners.Slack, "slack", "", "Slack channel to ask about the stack")
+	cmd.Flags().StringVar(&opts.Owners.Escalation, "escalation", "", "On-call escalation for the stack, such as a pager rotation")
+	cmd.Flags().StringArrayVar(&opts.Notify, "notify", nil, "URL to post a JSON event to after the push, can be repeated")
+	cmd.Flags().StringVar(&opts.Supersedes, "supersedes", "", "Tag or digest of the earlier version this stack supersedes")
+	cmd.Flags().StringVar(&opts.FailOnSeverity, "fail-on-severity", "", "Refuse to push when an image has a vulnerability of this severity or worse: critical, high, medium, or low")
+	cmd.Flags().StringVar(&opts.Scanner, "scanner", "trivy", "Scanner to run for --fail-on-severity: trivy or grype")
+	cmd.Flags().StringArrayVar(&opts.ScanReports, "scan-report", nil, "JSON report of an earlier Trivy or Grype scan to use for --fail-on-severity, can be repeated")
 	_ = cmd.MarkFlagRequired("filenames")
 
 	return cmd
 }
 
+// pushStats tallies what a copy uploaded and what the registry already had.
+// oras calls the hooks concurrently, hence the mutex.
+type pushStats struct {
+	mu            sync.Mutex
+	uploadedBytes int64
+	skippedBlobs  int
+	skippedBytes  int64
+}
+
+func (s *pushStats) copied(_ context.Context, desc v1.Descriptor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploadedBytes += desc.Size
+	return nil
+}
+
+func (s *pushStats) skipped(_ context.Context, desc v1.Descriptor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skippedBlobs++
+	s.skippedBytes += desc.Size
+	return nil
+}
+
+// checkVulnerabilities scans the images the files deploy and refuses the
+// push when one has a finding at or above opts.FailOnSeverity. The report
+// is printed then, so CI sees the findings with --json.
+func checkVulnerabilities(ctx context.Context, cli *CLI, filenames []string, opts *PushOptions) error {
+	threshold, err := scan.ParseSeverity(opts.FailOnSeverity)
+	if err != nil {
+		return fmt.Errorf("invalid --fail-on-severity: %w", err)
+	}
+
+	defer cli.phase("scan")()
+	files, err := readStackFiles(filenames)
+	if err != nil {
+		return err
+	}
+	report, err := scanStackImages(ctx, cli, files, opts.Scanner, opts.ScanReports)
+	if err != nil {
+		return err
+	}
+	report.Reference = opts.Reference
+
+	var violations, images int
+	for _, image := range report.Images {
+		n := countAtLeast(image.Findings, threshold)
+		if n > 0 {
+			violations += n
+			images++
+		}
+	}
+	if violations == 0 {
+		cli.Logger().Debug("No vulnerabilities at or above threshold", "severity", threshold.String(), "images", len(report.Images))
+		return nil
+	}
+
+	if err := render(cli, report, printScanReport); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d vulnerability(ies) of severity %s or worse in %d image(s), not pushing",
+		violations, strings.ToLower(threshold.String()), images)
+}
+
+// countAtLeast counts the findings of the given severity or worse.
+func countAtLeast(findings []api.Vulnerability, threshold scan.Severity) int {
+	var n int
+	for _, f := range findings {
+		if severity, _ := scan.ParseSeverity(f.Severity); severity >= threshold {
+			n++
+		}
+	}
+	return n
+}
+
+// openPushTarget opens what push writes to: the OCI image layout directory
+// of an oci-layout: reference, or the repository in a registry otherwise.
+// It returns the target and the tag to push under.
+func openPushTarget(cli *CLI, reference string) (oras.Target, string, error) {
+	layout, ok, err := oci.ParseLayoutReference(reference)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		store, err := oci.OpenLayout(layout.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		return store, layout.Tag, nil
+	}
+
+	// Set up remote repository with authentication
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		return nil, "", err
+	}
+	if repo.PlainHTTP {
+		cli.Logger().Debug("Using plain HTTP for local registry", "host", repo.Reference.Host())
+	}
+	return repo, repo.Reference.Reference, nil
+}
+
+// pushArtifact copies the artifact with the manifest desc from src to dst
+// and tags it, or adds it to the index at tag as variant. It returns the
+// descriptor the tag points at. dst is a remote repository or an OCI image
+// layout, and in tests an in-memory store.
+func pushArtifact(ctx context.Context, src oras.ReadOnlyTarget, desc v1.Descriptor, dst oras.Target, tag, variant string, stats *pushStats) (v1.Descriptor, error) {
+	copyOpts := oras.DefaultCopyGraphOptions
+	copyOpts.PostCopy = stats.copied
+	copyOpts.OnCopySkipped = stats.skipped
+	if err := oras.CopyGraph(ctx, src, dst, desc, copyOpts); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to push artifact: %w", err)
+	}
+
+	if variant != "" {
+		// The manifest stays untagged, the index takes the tag
+		return oci.AddVariant(ctx, dst, tag, desc, variant)
+	}
+	if err := dst.Tag(ctx, desc, tag); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to tag artifact: %w", err)
+	}
+	return desc, nil
+}
+
+// addChecksums adds a SHA256SUMS layer to the file store. The file store
+// writes titled content that is not backed by a file to its working
+// directory, so the checksums are written to a file in dir first.
+func addChecksums(ctx context.Context, store *file.Store, dir string, data []byte) (v1.Descriptor, error) {
+	path := filepath.Join(dir, checksumsFile)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to write %s: %w", checksumsFile, err)
+	}
+
+	desc, err := store.Add(ctx, checksumsFile, oci.ChecksumsMediaType, path)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to add %s to store: %w", checksumsFile, err)
+	}
+	return desc, nil
+}
+
+// print writes how much was uploaded and what was skipped.
+func (s *pushStats) print(w *view.Stream) {
+	w.Printf("Uploaded: %s", formatSize(s.uploadedBytes))
+	switch {
+	case s.skippedBlobs > 0:
+		w.Printf(" (%d blob(s) of %s already in registry)",
+			s.skippedBlobs, formatSize(s.skippedBytes))
+	case s.uploadedBytes == 0:
+		// oras only retags when the manifest itself already exists
+		w.Printf(" (artifact already in registry)")
+	}
+	w.Println()
+}
+
 func RunPush(ctx context.Context, cli *CLI, opts *PushOptions) error {
 	if len(opts.Filenames) == 0 {
 		return fmt.Errorf("no files specified, use -f to provide RGD files")
 	}
 
 	// Collect all YAML files
-	var allFiles []string
-	for _, filename := range opts.Filenames {
-		info, err := os.Stat(filename)
-		if err != nil {
-			return fmt.Errorf("failed to access %s: %w", filename, err)
-		}
+	allFiles, err := collectYAMLFiles(opts.Filenames)
+	if err != nil {
+		return err
+	}
 
-		if info.IsDir() {
-			err := filepath.Walk(filename, func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-				ext := filepath.Ext(path)
-				if !info.IsDir() && (ext == ".yaml" || ext == ".yml") {
-					allFiles = append(allFiles, path)
-				}
-				return nil
-			})
-			if err != nil {
-				return fmt.Errorf("failed to walk directory %s: %w", filename, err)
-			}
-		} else {
-			allFiles = append(allFiles, filename)
-		}
+	// Refuse to bake malformed RGDs into the artifact
+	done := cli.phase("validate")
+	diags, err := validateFiles(cli, allFiles, rgd.LintOptions{})
+	done()
+	if err != nil {
+		return err
+	}
+	if diags.HasErrors() {
+		return fmt.Errorf("invalid ResourceGraphDefinitions:\n%w", diags)
+	}
+	// Warnings such as deprecated APIs must show without --debug
+	for _, diag := range diags {
+		cli.warn("%s:%d: %s", diag.File, diag.Line, diag.Message)
 	}
 
-	if len(allFiles) == 0 {
-		return fmt.Errorf("no YAML files found in specified paths")
+	if opts.FailOnSeverity != "" {
+		if err := checkVulnerabilities(ctx, cli, allFiles, opts); err != nil {
+			return err
+		}
 	}
 
 	cli.Logger().Info("Preparing to push RGD stack",
 		"reference", opts.Reference,
 		"files", len(allFiles))
 
+	done = cli.phase("pack")
 	// Create a file store for the artifact
 	store, err := file.New("")
 	if err != nil {
@@ -95,7 +331,12 @@ func RunPush(ctx context.Context, cli *CLI, opts *PushOptions) error {
 
 	// Add files to the store
 	layers := make([]v1.Descriptor, 0, len(allFiles))
+	contents := make([][]byte, 0, len(allFiles))
 	for _, file := range allFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
 		desc, err := store.Add(ctx, filepath.Base(file), oci.LayerMediaType, file)
 		if err != nil {
 			return fmt.Errorf("failed to add %s to store: %w", file, err)
@@ -104,40 +345,200 @@ func RunPush(ctx context.Context, cli *CLI, opts *PushOptions) error {
 			"file", filepath.Base(file),
 			"digest", desc.Digest.String())
 		layers = append(layers, desc)
+		contents = append(contents, data)
+	}
+
+	if opts.Checksums {
+		// Layer digests are the SHA-256 of the file contents
+		sums := make(map[string]string, len(layers))
+		for _, layer := range layers {
+			sums[oci.LayerTitle(layer)] = layer.Digest.Encoded()
+		}
+		dir, err := os.MkdirTemp("", "kroctl-checksums-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		desc, err := addChecksums(ctx, store, dir, formatChecksums(sums))
+		if err != nil {
+			return err
+		}
+		layers = append(layers, desc)
+		contents = append(contents, nil)
+	}
+
+	if opts.Archive {
+		dir, err := os.MkdirTemp("", "kroctl-archive-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		desc, err := addArchive(ctx, store, dir, allFiles, contents[:len(allFiles)])
+		if err != nil {
+			return err
+		}
+		cli.Logger().Debug("Added archive to artifact", "digest", desc.Digest.String())
+		layers = append(layers, desc)
+		contents = append(contents, nil)
 	}
 
-	packOpts := oras.PackManifestOptions{
-		Layers: layers,
+	for _, extra := range []struct{ dir, name, mediaType string }{
+		{opts.Docs, "docs", oci.DocsMediaType},
+		{opts.Examples, "examples", oci.ExamplesMediaType},
+	} {
+		if extra.dir == "" {
+			continue
+		}
+		info, err := os.Stat(extra.dir)
+		if err != nil {
+			return fmt.Errorf("failed to access %s: %w", extra.dir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("--%s expects a directory, %s is a file", extra.name, extra.dir)
+		}
+		// The file store packs directories as gzipped tarballs
+		desc, err := store.Add(ctx, extra.name, extra.mediaType, extra.dir)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to store: %w", extra.dir, err)
+		}
+		cli.Logger().Debug("Added directory to artifact",
+			"dir", extra.dir,
+			"digest", desc.Digest.String())
+		layers = append(layers, desc)
+		contents = append(contents, nil)
 	}
-	manifestDesc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, oci.ArtifactType, packOpts)
+
+	target, tag, err := openPushTarget(cli, opts.Reference)
 	if err != nil {
-		return fmt.Errorf("failed to pack manifest: %w", err)
+		return err
 	}
 
-	if err := store.Tag(ctx, manifestDesc, opts.Reference); err != nil {
-		return fmt.Errorf("failed to tag manifest: %w", err)
+	if opts.Variant != "" {
+		if err := (registry.Reference{Reference: tag}).ValidateReferenceAsTag(); err != nil {
+			return fmt.Errorf("--variant requires a tag to add the variant to: %w", err)
+		}
 	}
 
-	// Set up remote repository with authentication
-	repo, err := oci.SetupRepository(opts.Reference)
+	var subject *v1.Descriptor
+	if opts.Supersedes != "" {
+		desc, err := oci.ResolveSuperseded(ctx, target, opts.Supersedes)
+		if err != nil {
+			return err
+		}
+		cli.Logger().Debug("Superseding stack", "reference", opts.Supersedes, "digest", desc.Digest.String())
+		subject = &desc
+	}
+
+	config, err := oci.NewStackConfig(opts.Reference, layers, contents)
 	if err != nil {
 		return err
 	}
+	manifestDesc, err := oci.PackStack(ctx, store, config, layers, opts.Owners.Annotations(), subject)
+	if err != nil {
+		return err
+	}
+	done()
 
-	if repo.PlainHTTP {
-		cli.Logger().Debug("Using plain HTTP for local registry", "host", repo.Reference.Host())
+	cli.Logger().Info("Pushing artifact", "reference", opts.Reference)
+	var stats pushStats
+	done = cli.phase("upload")
+	tagged, err := pushArtifact(ctx, store, manifestDesc, target, tag, opts.Variant, &stats)
+	done()
+	cli.count("uploadedBytes", stats.uploadedBytes)
+	cli.count("skippedBlobs", int64(stats.skippedBlobs))
+	cli.count("skippedBytes", stats.skippedBytes)
+	if err != nil {
+		return err
+	}
+	if opts.Variant != "" {
+		cli.Logger().Debug("Updated index",
+			"digest", tagged.Digest.String(),
+			"variant", opts.Variant)
+	}
+
+	result := &api.PushResult{
+		TypeMeta:      api.NewTypeMeta(api.KindPushResult),
+		Reference:     opts.Reference,
+		Digest:        manifestDesc.Digest.String(),
+		Variant:       opts.Variant,
+		Files:         make([]string, 0, len(allFiles)),
+		UploadedBytes: stats.uploadedBytes,
+		SkippedBytes:  stats.skippedBytes,
+	}
+	if subject != nil {
+		result.Supersedes = subject.Digest.String()
+	}
+	for _, file := range allFiles {
+		result.Files = append(result.Files, filepath.Base(file))
 	}
 
-	// Copy from file store to remote registry
-	cli.Logger().Info("Pushing artifact to registry", "reference", opts.Reference)
-	_, err = oras.Copy(ctx, store, opts.Reference, repo, opts.Reference, oras.DefaultCopyOptions)
+	cli.notifyPush(ctx, slices.Concat(cli.Notify, opts.Notify), opts.Reference, result.Digest, result.Files)
+
+	err = render(cli, result, func(s *view.Stream, result *api.PushResult) error {
+		s.Printf("Successfully pushed %d RGD file(s) to %s\n",
+			len(result.Files), result.Reference)
+		s.Printf("Digest: %s\n", result.Digest)
+		if result.Supersedes != "" {
+			s.Printf("Supersedes: %s\n", result.Supersedes)
+		}
+		stats.print(s)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to push artifact: %w", err)
+		return err
 	}
 
-	cli.Printf("Successfully pushed %d RGD file(s) to %s\n",
-		len(allFiles), opts.Reference)
-	cli.Printf("Digest: %s\n", manifestDesc.Digest.String())
+	// For a variant the audit log records the index, which the tag points at
+	cli.auditChange("push", opts.Reference, tagged.Digest.String())
 
+	// The push already happened, so a later step missing its outputs is
+	// reported rather than failing the command
+	if err := cli.writeGitHubOutput(map[string]string{
+		"reference": opts.Reference,
+		"digest":    manifestDesc.Digest.String(),
+	}); err != nil {
+		cli.warn("push of %s succeeded, but %s", opts.Reference, err)
+	}
 	return nil
 }
+
+// addArchive adds a gzipped tarball of the files to the file store, with
+// every file at the root of the archive. Modification times are left out,
+// so pushing the same files again yields the same layer.
+func addArchive(ctx context.Context, store *file.Store, dir string, files []string, contents [][]byte) (v1.Descriptor, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for i, f := range files {
+		header := &tar.Header{
+			Name:     filepath.Base(f),
+			Mode:     0o644,
+			Size:     int64(len(contents[i])),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("failed to archive %s: %w", f, err)
+		}
+		if _, err := tw.Write(contents[i]); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("failed to archive %s: %w", f, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to archive files: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to archive files: %w", err)
+	}
+
+	path := filepath.Join(dir, archiveFile)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to write %s: %w", archiveFile, err)
+	}
+	desc, err := store.Add(ctx, archiveFile, oci.ArchiveMediaType, path)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to add %s to store: %w", archiveFile, err)
+	}
+	return desc, nil
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+// testRGD returns a minimal ResourceGraphDefinition named name.
+func testRGD(name string) []byte {
+	return []byte("apiVersion: kro.run/v1alpha1\n" +
+		"kind: ResourceGraphDefinition\n" +
+		"metadata:\n" +
+		"  name: " + name + "\n" +
+		"spec:\n" +
+		"  schema:\n" +
+		"    apiVersion: v1alpha1\n" +
+		"    kind: App\n")
+}
+
+// packTestStack packs a single layer stack into an in-memory store.
+func packTestStack(t *testing.T, name string, data []byte) (*memory.Store, v1.Descriptor) {
+	t.Helper()
+	ctx := context.Background()
+
+	store := memory.New()
+	layer := content.NewDescriptorFromBytes(oci.LayerMediaType, data)
+	layer.Annotations = map[string]string{v1.AnnotationTitle: name}
+	require.NoError(t, store.Push(ctx, layer, bytes.NewReader(data)))
+
+	layers := []v1.Descriptor{layer}
+	config, err := oci.NewStackConfig("example.com/stack:v1", layers, [][]byte{data})
+	require.NoError(t, err)
+	desc, err := oci.PackStack(ctx, store, config, layers, nil, nil)
+	require.NoError(t, err)
+	return store, desc
+}
+
+func TestPushArtifact(t *testing.T) {
+	ctx := context.Background()
+	src, desc := packTestStack(t, "app.yaml", testRGD("app"))
+	dst := memory.New()
+
+	var stats pushStats
+	tagged, err := pushArtifact(ctx, src, desc, dst, "v1", "", &stats)
+	require.NoError(t, err)
+	assert.Equal(t, desc, tagged)
+	assert.Positive(t, stats.uploadedBytes)
+
+	resolved, err := dst.Resolve(ctx, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, desc.Digest, resolved.Digest)
+
+	// Pushing again uploads nothing
+	stats = pushStats{}
+	_, err = pushArtifact(ctx, src, desc, dst, "v1", "", &stats)
+	require.NoError(t, err)
+	assert.Zero(t, stats.uploadedBytes)
+	assert.Equal(t, 1, stats.skippedBlobs)
+}
+
+func TestPushArtifact_Variants(t *testing.T) {
+	ctx := context.Background()
+	dst := memory.New()
+
+	aws, awsDesc := packTestStack(t, "aws.yaml", testRGD("aws"))
+	gcp, gcpDesc := packTestStack(t, "gcp.yaml", testRGD("gcp"))
+
+	_, err := pushArtifact(ctx, aws, awsDesc, dst, "v1", "aws", &pushStats{})
+	require.NoError(t, err)
+	indexDesc, err := pushArtifact(ctx, gcp, gcpDesc, dst, "v1", "gcp", &pushStats{})
+	require.NoError(t, err)
+	assert.Equal(t, v1.MediaTypeImageIndex, indexDesc.MediaType)
+
+	resolved, err := dst.Resolve(ctx, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, indexDesc.Digest, resolved.Digest)
+
+	manifests, err := content.Successors(ctx, dst, indexDesc)
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "aws", manifests[0].Annotations[oci.AnnotationVariant])
+	assert.Equal(t, "gcp", manifests[1].Annotations[oci.AnnotationVariant])
+}
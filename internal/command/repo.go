@@ -0,0 +1,166 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type RepoStatusOptions struct {
+	Repository string
+}
+
+func NewRepoCommand(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repo",
+		Short: "Work with the repositories RGD stacks are published to",
+		Long: "Work with the repositories RGD stacks are published to.\n\n" +
+			"Repository commands look at every tag of a repository at once,\n" +
+			"rather than a single stack.\n",
+	}
+
+	cmd.AddCommand(newRepoStatusCommand(cli))
+
+	return cmd
+}
+
+func newRepoStatusCommand(cli *CLI) *cobra.Command {
+	opts := RepoStatusOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "status <repository>",
+		Short: "Show an overview of the stacks in a repository",
+		Long: "Show an overview of the stacks in a repository.\n\n" +
+			"Inspects every tag of the repository and reports the latest\n" +
+			"version, how many tags are signed and deprecated, and the total\n" +
+			"size of the repository, followed by a row per tag. Blobs shared\n" +
+			"between tags count once towards the total size. Signatures and\n" +
+			"attestations stored under sha256-<hex> tags are not listed.\n\n" +
+			"A tag on the repository is ignored, only its name is used.\n\n" +
+			"Examples:\n" +
+			"  kroctl repo status ghcr.io/acme/network\n\n" +
+			"  kroctl repo status localhost:5001/kro-stack-network --json\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Repository = args[0]
+			return RunRepoStatus(cmd.Context(), cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunRepoStatus(ctx context.Context, cli *CLI, opts *RepoStatusOptions) error {
+	repo, err := oci.SetupRepository(opts.Repository)
+	if err != nil {
+		return err
+	}
+	name := repo.Reference.Registry + "/" + repo.Reference.Repository
+
+	references, err := expandTags(ctx, []string{name})
+	if err != nil {
+		return err
+	}
+	if len(references) == 0 {
+		return fmt.Errorf("no tags found in %s", name)
+	}
+
+	cli.Logger().Info("Inspecting repository", "repository", name, "tags", len(references))
+	stacks, results := inspectStacks(ctx, cli, references, &InspectOptions{})
+	status := repoStatus(name, stacks, results)
+
+	err = render(cli, status, func(s *view.Stream, status *api.RepoStatus) error {
+		s.Printf("Repository:  %s\n", status.Repository)
+		s.Printf("Tags:        %d\n", len(status.Stacks))
+		s.Printf("Latest:      %s\n", orDash(status.Latest))
+		s.Printf("Signed:      %d of %d\n", status.Signed, len(status.Stacks))
+		if status.Deprecated > 0 {
+			s.Printf("Deprecated:  %s\n", view.Caution.Sprintf("%d", status.Deprecated))
+		} else {
+			s.Printf("Deprecated:  0\n")
+		}
+		s.Printf("Total size:  %s\n", formatSize(status.TotalSize))
+		s.Println()
+
+		w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Tag\tVersion\tCreated\tRGDs\tSize\tSigned\tDeprecated\n")
+		for _, stack := range status.Stacks {
+			tag := strings.TrimPrefix(stack.Reference, status.Repository+":")
+			if stack.Error != "" {
+				fmt.Fprintf(w, "%s\t%s\n", tag, view.Caution.Sprintf("error: %s", stack.Error))
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				tag, orDash(stack.Version), orDash(stack.Created), stack.RGDs,
+				formatSize(stack.Size), yesNo(stack.Signed), yesNo(stack.Deprecation != nil))
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return inspectFailures(status.Stacks)
+}
+
+// repoStatus sums up the inspected tags of a repository.
+func repoStatus(repository string, stacks []api.InspectedStack, results []*api.InspectResult) *api.RepoStatus {
+	status := &api.RepoStatus{
+		TypeMeta:   api.NewTypeMeta(api.KindRepoStatus),
+		Repository: repository,
+		Stacks:     stacks,
+	}
+
+	var tags []string
+	for _, stack := range stacks {
+		tags = append(tags, strings.TrimPrefix(stack.Reference, repository+":"))
+		if stack.Signed {
+			status.Signed++
+		}
+		if stack.Deprecation != nil {
+			status.Deprecated++
+		}
+	}
+	latest, ok := latestSemver(tags, false)
+	if !ok {
+		latest, ok = latestSemver(tags, true)
+	}
+	if ok {
+		status.Latest = latest.String()
+	}
+
+	// Tags of the same stack and stacks that share RGD files point at the
+	// same blobs, which the registry stores once
+	seen := map[string]bool{}
+	for _, result := range results {
+		if result == nil || result.Manifest == nil {
+			continue
+		}
+		m := result.Manifest
+		if seen[m.Digest] {
+			continue
+		}
+		seen[m.Digest] = true
+
+		// Size covers the manifest, config, and layers; only the layers are
+		// listed, the rest is never shared
+		own := m.Size
+		for _, layer := range m.Layers {
+			own -= layer.Size
+			if !seen[layer.Digest] {
+				seen[layer.Digest] = true
+				status.TotalSize += layer.Size
+			}
+		}
+		status.TotalSize += own
+	}
+
+	return status
+}
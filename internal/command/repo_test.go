@@ -0,0 +1,41 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bschaatsbergen/kroctl/api"
+)
+
+func TestRepoStatus(t *testing.T) {
+	shared := api.Layer{Digest: "sha256:shared", Size: 100}
+	manifest := func(digest string, layers ...api.Layer) *api.InspectResult {
+		size := int64(10)
+		for _, l := range layers {
+			size += l.Size
+		}
+		return &api.InspectResult{Manifest: &api.Manifest{Digest: digest, Size: size, Layers: layers}}
+	}
+
+	stacks := []api.InspectedStack{
+		{Reference: "ghcr.io/acme/network:v1.0.0", Signed: true},
+		{Reference: "ghcr.io/acme/network:v1.1.0", Deprecation: &api.Deprecation{Message: "use v2"}},
+		{Reference: "ghcr.io/acme/network:latest"},
+		{Reference: "ghcr.io/acme/network:v2.0.0-rc.1", Error: "boom"},
+	}
+	results := []*api.InspectResult{
+		manifest("sha256:a", shared),
+		manifest("sha256:b", shared, api.Layer{Digest: "sha256:b1", Size: 50}),
+		// latest points at the same manifest as v1.1.0
+		manifest("sha256:b", shared, api.Layer{Digest: "sha256:b1", Size: 50}),
+		nil,
+	}
+
+	status := repoStatus("ghcr.io/acme/network", stacks, results)
+	assert.Equal(t, "v1.1.0", status.Latest)
+	assert.Equal(t, 1, status.Signed)
+	assert.Equal(t, 1, status.Deprecated)
+	// Two manifests of 10 bytes, the shared layer once, and b1
+	assert.Equal(t, int64(10+10+100+50), status.TotalSize)
+}
@@ -3,27 +3,35 @@ package command
 import (
 	"os"
 	"strings"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/bschaatsbergen/kroctl/internal/oci"
 	"github.com/bschaatsbergen/kroctl/internal/view"
 	"github.com/bschaatsbergen/kroctl/version"
 )
 
 var (
-	jsonFlag  bool
-	debugFlag bool
-	rootCmd   *cobra.Command
+	jsonFlag      bool
+	yamlFlag      bool
+	debugFlag     bool
+	limitRateFlag string
+	noInputFlag   bool
+	formatFlag    string
+	colorFlag     string
+	metricsFlag   string
+	registryFlag  string
+	rootCmd       *cobra.Command
 )
 
-func NewRootCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use: "kroctl",
-		Short: color.RGB(50, 108, 229).Sprintf("kroctl [global options] <subcommand> [args]") + `\n` +
-			"A utility to work with kro's resource graph definitions and package them as OCI artifacts",
-		Long: color.RGB(50, 108, 229).Sprintf("Usage: kroctl [global options] <subcommand> [args]\n") +
-			`
+// rootHelp returns the short and long help of the root command. They are
+// styled when they are built, so they are rebuilt once --color is known.
+func rootHelp() (string, string) {
+	short := view.Accent.Sprintf("kroctl [global options] <subcommand> [args]") + `\n` +
+		"A utility to work with kro's resource graph definitions and package them as OCI artifacts"
+	long := view.Accent.Sprintf("Usage: kroctl [global options] <subcommand> [args]\n") +
+		`
  __
 |  | _________  ____
 |  |/ /\_  __ \/  _ \
@@ -31,20 +39,29 @@ func NewRootCommand() *cobra.Command {
 |__|_ \ |__|   \____/
      \/
 		` + "\n" +
-			"kroctl is a CLI utility for working with kro ResourceGraphDefinitions\n" +
-			"(RGDs) and packaging them as OCI artifacts for distribution and reuse\n" +
-			"across Kubernetes clusters.\n\n" +
-			"kro (Kube Resource Orchestrator) is a Kubernetes-native project that\n" +
-			"lets you define custom Kubernetes APIs using simple configuration.\n" +
-			"ResourceGraphDefinitions bundle multiple Kubernetes resources together\n" +
-			"with logical operations, conditions, and dependencies using CEL\n" +
-			"(Common Expression Language). Platform teams use RGDs to encapsulate\n" +
-			"best practices and security policies, while development teams consume\n" +
-			"these simplified APIs to deploy complex application stacks.\n\n" +
-			"With kroctl, you can package RGDs as OCI artifacts and publish them to\n" +
-			"OCI-compliant registries for sharing and distribution across teams and\n" +
-			"clusters.\n\n" +
-			"Learn more about kro at https://kro.run\n\n",
+		"kroctl is a CLI utility for working with kro ResourceGraphDefinitions\n" +
+		"(RGDs) and packaging them as OCI artifacts for distribution and reuse\n" +
+		"across Kubernetes clusters.\n\n" +
+		"kro (Kube Resource Orchestrator) is a Kubernetes-native project that\n" +
+		"lets you define custom Kubernetes APIs using simple configuration.\n" +
+		"ResourceGraphDefinitions bundle multiple Kubernetes resources together\n" +
+		"with logical operations, conditions, and dependencies using CEL\n" +
+		"(Common Expression Language). Platform teams use RGDs to encapsulate\n" +
+		"best practices and security policies, while development teams consume\n" +
+		"these simplified APIs to deploy complex application stacks.\n\n" +
+		"With kroctl, you can package RGDs as OCI artifacts and publish them to\n" +
+		"OCI-compliant registries for sharing and distribution across teams and\n" +
+		"clusters.\n\n" +
+		"Learn more about kro at https://kro.run\n\n"
+	return short, long
+}
+
+func NewRootCommand() *cobra.Command {
+	short, long := rootHelp()
+	cmd := &cobra.Command{
+		Use:           "kroctl",
+		Short:         short,
+		Long:          long,
 		Version:       version.Version,
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -57,12 +74,20 @@ func NewRootCommand() *cobra.Command {
 
 	cmd.CompletionOptions.DisableDefaultCmd = true
 	cmd.PersistentFlags().BoolVar(&jsonFlag, "json", false, "Output in JSON format")
+	cmd.PersistentFlags().BoolVar(&yamlFlag, "yaml", false, "Output in YAML format")
+	cmd.MarkFlagsMutuallyExclusive("json", "yaml")
+	cmd.PersistentFlags().StringVar(&formatFlag, "format", "", "Print results with a Go template, e.g. '{{.Manifest.Digest}}'")
 	cmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "Set log level to debug")
+	cmd.PersistentFlags().StringVar(&colorFlag, "color", string(view.ColorAuto), "When to color output: auto, always, or never")
+	cmd.PersistentFlags().BoolVar(&noInputFlag, "no-input", false, "Never prompt and disable color, implied when CI is set")
+	cmd.PersistentFlags().StringVar(&limitRateFlag, "limit-rate", "", "Limit blob transfers to a rate such as 5MiB per second (env KROCTL_LIMIT_RATE)")
+	cmd.PersistentFlags().StringVar(&registryFlag, "registry-config", "", "Read registry credentials from this Docker config file or directory (env KROCTL_REGISTRY_CONFIG)")
+	cmd.PersistentFlags().StringVar(&metricsFlag, "metrics-file", "", "Write durations, bytes transferred, and retries of the run to a JSON file (env KROCTL_METRICS_FILE)")
 	return cmd
 }
 
 func setCobraUsageTemplate() {
-	cobra.AddTemplateFunc("StyleHeading", color.RGB(50, 108, 229).SprintFunc())
+	cobra.AddTemplateFunc("StyleHeading", view.Accent.SprintFunc())
 	usageTemplate := rootCmd.UsageTemplate()
 	usageTemplate = strings.NewReplacer(
 		`Usage:`, `{{StyleHeading "Usage:"}}`,
@@ -91,54 +116,158 @@ func Execute() {
 	// things like the output format (view type) and writer upfront.
 	_ = rootCmd.ParseFlags(os.Args[1:])
 
-	// Disable color output if NO_COLOR is set in the environment
-	if _, exists := os.LookupEnv("NO_COLOR"); exists {
-		color.NoColor = true
-	} else {
-		color.NoColor = false
+	// Style the help with what is known so far, the color flags are
+	// validated and applied again once all flags are parsed
+	if err := setColorMode(); err == nil {
+		rootCmd.Short, rootCmd.Long = rootHelp()
 	}
 
-	// Set up the view type based on the `--json` flag
-	viewType := view.ViewHuman
-	if jsonFlag {
-		viewType = view.ViewJSON
+	// Create a new CLI instance, which is a global context that each command
+	// can use to access, useful for view rendering, etc.
+	cli := NewCLI(viewType(), os.Stdout, logLevel())
+	cli.AuditLog = os.Getenv("KROCTL_AUDIT_LOG")
+	cli.Notify = notifyURLs(os.Getenv("KROCTL_NOTIFY"))
+	cli.MetricsFile = os.Getenv("KROCTL_METRICS_FILE")
+	cli.GitHubActions = os.Getenv("GITHUB_ACTIONS") == "true"
+	if cli.GitHubActions {
+		cli.GitHubOutput = os.Getenv("GITHUB_OUTPUT")
 	}
+	oci.SetLogger(cli.Logger())
 
-	logLevel := view.LogLevelSilent
-	logEnv := os.Getenv("KROCTL_LOG")
-	switch strings.ToLower(logEnv) {
-	case "debug":
-		logLevel = view.LogLevelDebug
-	case "info":
-		logLevel = view.LogLevelInfo
-	default:
-		// Unknown value: keep default (silent)
-	}
-	if debugFlag {
-		logLevel = view.LogLevelDebug
-	}
+	// The early parse stops at the first flag of a subcommand, so global
+	// flags that must be honored wherever they appear are applied once
+	// cobra has parsed all flags.
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Output must not depend on the order of the flags
+		cli.Viewer = view.NewViewer(viewType(), cli.Stream, logLevel())
+		oci.SetLogger(cli.Logger())
+		cli.Format = formatFlag
+		if err := setColorMode(); err != nil {
+			return err
+		}
 
-	// Create a new CLI instance, which is a global context that each command
-	// can use to access, useful for view rendering, etc.
-	cli := NewCLI(viewType, os.Stdout, logLevel)
+		// Bandwidth limit for blob transfers, the flag overrides the environment
+		limitRate := os.Getenv("KROCTL_LIMIT_RATE")
+		if limitRateFlag != "" {
+			limitRate = limitRateFlag
+		}
+		if limitRate != "" {
+			rate, err := oci.ParseRate(limitRate)
+			if err != nil {
+				return err
+			}
+			oci.SetRateLimit(rate)
+			cli.Logger().Debug("Limiting transfer rate", "bytes_per_second", rate)
+		}
+
+		// Credentials for hermetic CI runs, the flag overrides the
+		// environment. They must not silently fall back to the user's own.
+		registryConfig := os.Getenv("KROCTL_REGISTRY_CONFIG")
+		if registryFlag != "" {
+			registryConfig = registryFlag
+		}
+		return oci.SetRegistryConfig(registryConfig)
+	}
 
 	// Add all subcommands to the root command
 	AddCommands(rootCmd, cli)
 
 	// Walk and execute the resolved command with flags.
-	if err := rootCmd.Execute(); err != nil {
-		cli.Println(err.Error())
+	started := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	// Read after execution, the flag may follow the subcommand's own flags
+	if metricsFlag != "" {
+		cli.MetricsFile = metricsFlag
+	}
+	if merr := cli.writeMetrics(cmd.CommandPath(), started, err == nil); merr != nil {
+		cli.warn("%s", merr)
+	}
+	if err != nil {
+		cli.Logger().Debug("Command failed", "error", err.Error())
+		cli.Println(oci.ExplainError(err).Error())
 		os.Exit(1)
 	}
 
 	os.Exit(0)
 }
 
+// viewType returns the view type set with the --json and --yaml flags.
+func viewType() view.ViewType {
+	switch {
+	case jsonFlag:
+		return view.ViewJSON
+	case yamlFlag:
+		return view.ViewYAML
+	}
+	return view.ViewHuman
+}
+
+// logLevel returns the log level set with KROCTL_LOG, or with --debug.
+func logLevel() view.LogLevel {
+	if debugFlag {
+		return view.LogLevelDebug
+	}
+	switch strings.ToLower(os.Getenv("KROCTL_LOG")) {
+	case "debug":
+		return view.LogLevelDebug
+	case "info":
+		return view.LogLevelInfo
+	default:
+		// Unknown value: keep default (silent)
+		return view.LogLevelSilent
+	}
+}
+
+// isNoInput reports whether kroctl must not prompt. CI logs are scraped
+// line by line, keep them free of prompts and color.
+func isNoInput() bool {
+	return noInputFlag || view.IsCI(os.Getenv)
+}
+
+// setColorMode applies --color. Color is off for pipes, when NO_COLOR is
+// set, and without input, unless forced.
+func setColorMode() error {
+	colorMode, err := view.ParseColorMode(colorFlag)
+	if err != nil {
+		return err
+	}
+	_, noColor := os.LookupEnv("NO_COLOR")
+	view.SetColorMode(colorMode, noColor || isNoInput())
+	return nil
+}
+
 // AddCommands registers all subcommands to the root command.
 func AddCommands(root *cobra.Command, cli *CLI) {
 	root.AddCommand(
 		newVersionCommand(cli),
 		NewPushCommand(cli),
 		NewInspectCommand(cli),
+		NewExportCommand(cli),
+		NewGenerateCommand(cli),
+		NewFmtCommand(cli),
+		NewValidateCommand(cli),
+		NewDiffCommand(cli),
+		NewMergeCommand(cli),
+		NewSplitCommand(cli),
+		NewSchemaCommand(cli),
+		NewWhoamiCommand(cli),
+		NewDoctorCommand(cli),
+		NewApproveCommand(cli),
+		NewMigrateArtifactCommand(cli),
+		NewVerifyLayoutCommand(cli),
+		NewSizeCommand(cli),
+		NewBumpCommand(cli),
+		NewChangelogCommand(cli),
+		NewBreakingCommand(cli),
+		NewDeprecateCommand(cli),
+		NewOwnersCommand(cli),
+		NewVendorCommand(cli),
+		NewOutdatedCommand(cli),
+		NewUpdateCommand(cli),
+		NewImagesCommand(cli),
+		NewRepoCommand(cli),
+		NewAttestCommand(cli),
+		NewScanCommand(cli),
+		NewConflictsCommand(cli),
 	)
 }
@@ -0,0 +1,231 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/scan"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// scanPredicateType identifies the scan reports attached with scan --attest.
+const scanPredicateType = "https://kro.run/attestation/vulnerability-scan/v1"
+
+type ScanOptions struct {
+	Reference string
+	Filenames []string
+	Scanner   string
+	Reports   []string
+	Attest    bool
+}
+
+func NewScanCommand(cli *CLI) *cobra.Command {
+	opts := ScanOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "scan [reference]",
+		Short: "Scan the container images RGDs deploy for vulnerabilities",
+		Long: "Scan the container images RGDs deploy for vulnerabilities.\n\n" +
+			"Lists the images referenced in the resource templates of a stack,\n" +
+			"or of local files with -f, scans each with Trivy or Grype, and\n" +
+			"summarizes the findings by severity. The scanner must be\n" +
+			"installed. Reports of earlier scans, in the JSON format of either\n" +
+			"scanner, can be passed with --report; images they cover are not\n" +
+			"scanned again.\n\n" +
+			"With --attest, the report is attached to the stack as an in-toto\n" +
+			"attestation, see kroctl attest.\n\n" +
+			"Examples:\n" +
+			"  kroctl scan ghcr.io/acme/kro-stack:v1.2.0\n\n" +
+			"  kroctl scan -f ./rgds/ --scanner grype\n\n" +
+			"  kroctl scan ghcr.io/acme/kro-stack:v1.2.0 --report nginx.json --attest\n",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				opts.Reference = args[0]
+			}
+			return RunScan(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
+		[]string{}, "RGD files or directories to scan the images of")
+	cmd.Flags().StringVar(&opts.Scanner, "scanner", "trivy", "Scanner to run: trivy or grype")
+	cmd.Flags().StringArrayVar(&opts.Reports, "report", nil, "JSON report of an earlier Trivy or Grype scan to use, can be repeated")
+	cmd.Flags().BoolVar(&opts.Attest, "attest", false, "Attach the report to the stack as an attestation")
+
+	return cmd
+}
+
+func RunScan(ctx context.Context, cli *CLI, opts *ScanOptions) error {
+	if (opts.Reference == "") == (len(opts.Filenames) == 0) {
+		return fmt.Errorf("pass either a reference or files with -f")
+	}
+	if opts.Attest && opts.Reference == "" {
+		return fmt.Errorf("--attest attaches the report to a stack, pass a reference instead of -f")
+	}
+
+	var files []stackFile
+	var err error
+	if opts.Reference != "" {
+		files, err = loadStackFiles(ctx, cli, opts.Reference)
+	} else {
+		files, err = readStackFiles(opts.Filenames)
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := scanStackImages(ctx, cli, files, opts.Scanner, opts.Reports)
+	if err != nil {
+		return err
+	}
+	report.Reference = opts.Reference
+
+	var attested string
+	if opts.Attest {
+		attested, err = attestScan(ctx, cli, opts.Reference, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := render(cli, report, printScanReport); err != nil {
+		return err
+	}
+	if attested != "" {
+		cli.auditChange("attest", opts.Reference, attested)
+	}
+	return nil
+}
+
+// scanStackImages scans every distinct image the files deploy. Images
+// covered by one of the given reports are not scanned again.
+func scanStackImages(ctx context.Context, cli *CLI, files []stackFile, scanner string, reportFiles []string) (*api.ScanReport, error) {
+	rgds, err := parseStackFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	ingested := map[string]*scan.Report{}
+	for _, path := range reportFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report: %w", err)
+		}
+		report, err := scan.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ingested[imageKey(report.Image)] = report
+	}
+
+	result := &api.ScanReport{
+		TypeMeta: api.NewTypeMeta(api.KindScanReport),
+		Scanner:  scanner,
+		Images:   []api.ImageScan{},
+	}
+	for _, image := range rgd.Summarize(rgds).Images {
+		report, ok := ingested[imageKey(image)]
+		if ok {
+			cli.Logger().Debug("Using scan report", "image", image)
+		} else {
+			cli.Logger().Info("Scanning image", "image", image, "scanner", scanner)
+			if report, err = scan.Run(ctx, scanner, image); err != nil {
+				return nil, err
+			}
+		}
+
+		imageScan := api.ImageScan{Image: image, Findings: []api.Vulnerability{}}
+		for _, f := range report.Findings {
+			imageScan.Findings = append(imageScan.Findings, api.Vulnerability{
+				ID:       f.ID,
+				Package:  f.Package,
+				Version:  f.Version,
+				Severity: f.Severity.String(),
+			})
+		}
+		imageScan.Counts = severityCounts(report.Counts())
+		addCounts(&result.Totals, imageScan.Counts)
+		result.Images = append(result.Images, imageScan)
+	}
+	return result, nil
+}
+
+// imageKey identifies an image regardless of how it is spelled, so nginx
+// in a stack matches docker.io/library/nginx:latest in a report.
+func imageKey(image string) string {
+	if ref, err := oci.NormalizeImage(image); err == nil {
+		return ref.String()
+	}
+	return image
+}
+
+func severityCounts(counts map[scan.Severity]int) api.SeverityCounts {
+	return api.SeverityCounts{
+		Critical: counts[scan.SeverityCritical],
+		High:     counts[scan.SeverityHigh],
+		Medium:   counts[scan.SeverityMedium],
+		Low:      counts[scan.SeverityLow],
+		Unknown:  counts[scan.SeverityUnknown],
+	}
+}
+
+func addCounts(total *api.SeverityCounts, c api.SeverityCounts) {
+	total.Critical += c.Critical
+	total.High += c.High
+	total.Medium += c.Medium
+	total.Low += c.Low
+	total.Unknown += c.Unknown
+}
+
+// attestScan attaches the scan report to the stack at reference.
+func attestScan(ctx context.Context, cli *CLI, reference string, report *api.ScanReport) (string, error) {
+	predicate, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode scan report: %w", err)
+	}
+
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		return "", err
+	}
+	desc, _, err := oci.FetchManifest(ctx, repo, reference, "")
+	if err != nil {
+		return "", err
+	}
+
+	name := repo.Reference.Registry + "/" + repo.Reference.Repository
+	attestation, err := oci.Attest(ctx, repo, name, desc, scanPredicateType, predicate)
+	if err != nil {
+		return "", err
+	}
+	cli.Logger().Info("Attached scan report", "reference", reference, "attestation", attestation.Digest.String())
+	return desc.Digest.String(), nil
+}
+
+func printScanReport(s *view.Stream, report *api.ScanReport) error {
+	if len(report.Images) == 0 {
+		s.Println("No images to scan")
+		return nil
+	}
+
+	s.Printf("Scanned %d image(s) with %s\n\n", len(report.Images), report.Scanner)
+	w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Image\tCritical\tHigh\tMedium\tLow\tUnknown\n")
+	row := func(name string, c api.SeverityCounts) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", name, c.Critical, c.High, c.Medium, c.Low, c.Unknown)
+	}
+	for _, image := range report.Images {
+		row(image.Image, image.Counts)
+	}
+	row("Total", report.Totals)
+	return w.Flush()
+}
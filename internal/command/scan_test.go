@@ -0,0 +1,70 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/scan"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+const scanStack = `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: web
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: Web
+  resources:
+    - id: deployment
+      template:
+        apiVersion: apps/v1
+        kind: Deployment
+        spec:
+          template:
+            spec:
+              containers:
+                - name: web
+                  image: nginx:1.25
+`
+
+func TestScanStackImages_Reports(t *testing.T) {
+	cli := NewCLI(view.ViewHuman, &bytes.Buffer{}, view.LogLevelSilent)
+	// The report spells the image the way Trivy normalizes it
+	report := `{"ArtifactName": "docker.io/library/nginx:1.25", "Results": [{"Vulnerabilities": [
+  {"VulnerabilityID": "CVE-2024-1", "PkgName": "openssl", "Severity": "CRITICAL"},
+  {"VulnerabilityID": "CVE-2024-2", "PkgName": "zlib", "Severity": "MEDIUM"}
+]}]}`
+	path := filepath.Join(t.TempDir(), "nginx.json")
+	require.NoError(t, os.WriteFile(path, []byte(report), 0o644))
+
+	files := []stackFile{{Name: "web.yaml", Content: []byte(scanStack)}}
+	result, err := scanStackImages(context.Background(), cli, files, "trivy", []string{path})
+	require.NoError(t, err)
+
+	require.Len(t, result.Images, 1)
+	assert.Equal(t, "nginx:1.25", result.Images[0].Image)
+	assert.Len(t, result.Images[0].Findings, 2)
+	assert.Equal(t, 1, result.Totals.Critical)
+	assert.Equal(t, 1, result.Totals.Medium)
+}
+
+func TestCountAtLeast(t *testing.T) {
+	findings := []api.Vulnerability{
+		{ID: "CVE-1", Severity: "CRITICAL"},
+		{ID: "CVE-2", Severity: "HIGH"},
+		{ID: "CVE-3", Severity: "LOW"},
+		{ID: "CVE-4", Severity: "UNKNOWN"},
+	}
+	assert.Equal(t, 1, countAtLeast(findings, scan.SeverityCritical))
+	assert.Equal(t, 2, countAtLeast(findings, scan.SeverityHigh))
+	assert.Equal(t, 4, countAtLeast(findings, scan.SeverityUnknown))
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+)
+
+type SchemaOptions struct {
+	Kind string
+}
+
+func NewSchemaCommand(cli *CLI) *cobra.Command {
+	opts := SchemaOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "schema [kind]",
+		Short: "Print the JSON Schema of machine-readable output",
+		Long: "Print the JSON Schema of machine-readable output.\n\n" +
+			"Commands run with --json print results with a kind and\n" +
+			"apiVersion. This prints the JSON Schema of a kind so downstream\n" +
+			"tooling can validate the output. Without a kind, the available\n" +
+			"kinds are listed.\n\n" +
+			"Kinds: " + strings.Join(api.Kinds(), ", ") + "\n\n" +
+			"Examples:\n" +
+			"  kroctl schema\n\n" +
+			"  kroctl schema InspectResult > inspect.schema.json\n",
+		Args: MaxArgsWithUsage(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Kind = args[0]
+			}
+			return RunSchema(cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunSchema(cli *CLI, opts *SchemaOptions) error {
+	if opts.Kind == "" {
+		for _, kind := range api.Kinds() {
+			cli.Println(kind)
+		}
+		return nil
+	}
+
+	schema, err := api.Schema(opts.Kind)
+	if err != nil {
+		return err
+	}
+	cli.Println(string(schema))
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// semverTagPattern matches tags such as v1.2.3 and 1.2.3-rc.1, capturing
+// the prefix, the version numbers, and the prerelease.
+var semverTagPattern = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// semver is a semantic version read from a tag.
+type semver struct {
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// parseSemver reads a semantic version from a tag. It reports false when
+// the tag is not one.
+func parseSemver(tag string) (semver, bool) {
+	m := semverTagPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return semver{}, false
+	}
+	v := semver{Prefix: m[1], Prerelease: m[5]}
+	var err error
+	for i, n := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		if *n, err = strconv.Atoi(m[i+2]); err != nil {
+			return semver{}, false
+		}
+	}
+	return v, true
+}
+
+func (v semver) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// compare orders versions by precedence. Prereleases come before the
+// release and are compared as strings, which is enough for rc.1 and rc.2.
+func (v semver) compare(o semver) int {
+	if c := cmp.Compare(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	}
+	return cmp.Compare(v.Prerelease, o.Prerelease)
+}
+
+// latestSemver returns the highest semantic version among tags. Prereleases
+// are skipped unless includePrerelease is set.
+func latestSemver(tags []string, includePrerelease bool) (semver, bool) {
+	var latest semver
+	var found bool
+	for _, tag := range tags {
+		v, ok := parseSemver(tag)
+		if !ok || (v.Prerelease != "" && !includePrerelease) {
+			continue
+		}
+		if !found || v.compare(latest) > 0 {
+			latest, found = v, true
+		}
+	}
+	return latest, found
+}
+
+// compareTags orders version tags by precedence, ahead of other tags, which
+// are ordered by name.
+func compareTags(a, b string) int {
+	va, aok := parseSemver(a)
+	vb, bok := parseSemver(b)
+	switch {
+	case aok && bok:
+		return va.compare(vb)
+	case aok:
+		return -1
+	case bok:
+		return 1
+	}
+	return cmp.Compare(a, b)
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSemver(t *testing.T) {
+	v, ok := parseSemver("v1.2.3-rc.1+build.5")
+	require.True(t, ok)
+	assert.Equal(t, semver{Prefix: "v", Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1"}, v)
+	assert.Equal(t, "v1.2.3-rc.1", v.String())
+
+	for _, tag := range []string{"latest", "v1.2", "1.2.3.4", "sha256-abc"} {
+		_, ok := parseSemver(tag)
+		assert.False(t, ok, tag)
+	}
+}
+
+func TestLatestSemver(t *testing.T) {
+	tags := []string{"latest", "v1.9.0", "v1.10.0", "v2.0.0-rc.1", "v1.10.0-rc.2"}
+
+	latest, ok := latestSemver(tags, false)
+	require.True(t, ok)
+	assert.Equal(t, "v1.10.0", latest.String())
+
+	latest, ok = latestSemver(tags, true)
+	require.True(t, ok)
+	assert.Equal(t, "v2.0.0-rc.1", latest.String())
+
+	_, ok = latestSemver([]string{"latest"}, false)
+	assert.False(t, ok)
+}
+
+func TestNextVersion(t *testing.T) {
+	v := semver{Prefix: "v", Major: 1, Minor: 2, Patch: 3}
+	for level, want := range map[string]string{
+		BumpPatch: "v1.2.4",
+		BumpMinor: "v1.3.0",
+		BumpMajor: "v2.0.0",
+	} {
+		next, err := nextVersion(v, level)
+		require.NoError(t, err)
+		assert.Equal(t, want, next.String(), level)
+	}
+
+	_, err := nextVersion(v, "huge")
+	assert.Error(t, err)
+}
+
+func TestNewestMatching(t *testing.T) {
+	tags := []string{"latest", "v1.0.0", "v1.1.0", "v2.0.0-rc.1", "3.0.0"}
+
+	latest, ok := newestMatching(semver{Prefix: "v", Major: 1}, tags)
+	require.True(t, ok)
+	assert.Equal(t, "v1.1.0", latest.String())
+
+	latest, ok = newestMatching(semver{Prefix: "v", Major: 2, Prerelease: "rc.0"}, tags)
+	require.True(t, ok)
+	assert.Equal(t, "v2.0.0-rc.1", latest.String())
+
+	latest, ok = newestMatching(semver{Major: 1}, tags)
+	require.True(t, ok)
+	assert.Equal(t, "3.0.0", latest.String())
+}
+
+func TestCompareTags(t *testing.T) {
+	tags := []string{"latest", "v1.10.0", "v1.2.0", "main", "v1.2.0-rc.1"}
+	slices.SortStableFunc(tags, compareTags)
+	assert.Equal(t, []string{"v1.2.0-rc.1", "v1.2.0", "v1.10.0", "latest", "main"}, tags)
+}
@@ -0,0 +1,152 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type SizeOptions struct {
+	Repository string
+}
+
+func NewSizeCommand(cli *CLI) *cobra.Command {
+	opts := SizeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "size <repository>",
+		Short: "Report the storage used by the tags of a repository",
+		Long: "Report the storage used by the tags of a repository.\n\n" +
+			"Walks every tag and adds up the manifests, configs, and layers it\n" +
+			"references, including the manifests of indexes. Blobs shared\n" +
+			"between tags are stored once, so the report shows the size of each\n" +
+			"tag, the unique storage of the repository, and the ratio between\n" +
+			"the two, to help plan retention for large catalogs.\n\n" +
+			"Examples:\n" +
+			"  kroctl size ghcr.io/acme/kro-stack\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Repository = args[0]
+			return RunSize(cmd.Context(), cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunSize(ctx context.Context, cli *CLI, opts *SizeOptions) error {
+	repo, err := oci.SetupRepository(opts.Repository)
+	if err != nil {
+		return err
+	}
+	if repo.Reference.Reference != "" {
+		return fmt.Errorf("expected a repository without a tag or digest, got %s", opts.Repository)
+	}
+
+	tags, err := oci.ListTags(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	report := &api.SizeReport{
+		TypeMeta:   api.NewTypeMeta(api.KindSizeReport),
+		Repository: opts.Repository,
+		Tags:       make([]api.TagSize, 0, len(tags)),
+	}
+
+	walker := &blobWalker{fetcher: repo, successors: map[digest.Digest][]v1.Descriptor{}}
+	unique := map[digest.Digest]int64{}
+	for _, tag := range tags {
+		desc, err := oci.Resolve(ctx, repo, tag)
+		if err != nil {
+			return err
+		}
+		blobs := map[digest.Digest]int64{}
+		if err := walker.walk(ctx, desc, blobs); err != nil {
+			return err
+		}
+
+		var size int64
+		for d, s := range blobs {
+			size += s
+			unique[d] = s
+		}
+		report.Referenced += size
+		report.Tags = append(report.Tags, api.TagSize{Tag: tag, Size: size, Digest: desc.Digest.String()})
+	}
+
+	report.Blobs = len(unique)
+	for _, s := range unique {
+		report.Stored += s
+	}
+
+	return render(cli, report, printSize)
+}
+
+// printSize prints the size of each tag, followed by the totals of the
+// repository and how much its tags share.
+func printSize(s *view.Stream, report *api.SizeReport) error {
+	if len(report.Tags) == 0 {
+		s.Printf("%s has no tags\n", report.Repository)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(s.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Tag\tSize\tDigest\n")
+	for _, t := range report.Tags {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.Tag, formatSize(t.Size), t.Digest)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	s.Printf("\nTags:         %d\n", len(report.Tags))
+	s.Printf("Blobs:        %d\n", report.Blobs)
+	s.Printf("Stored:       %s\n", formatSize(report.Stored))
+	s.Printf("Referenced:   %s\n", formatSize(report.Referenced))
+	if report.Stored > 0 {
+		s.Printf("Duplication:  %.1fx\n", float64(report.Referenced)/float64(report.Stored))
+	}
+	return nil
+}
+
+// blobWalker collects the blobs a descriptor references, caching the
+// children of manifests and indexes shared between tags.
+type blobWalker struct {
+	fetcher    content.Fetcher
+	successors map[digest.Digest][]v1.Descriptor
+}
+
+func (b *blobWalker) walk(ctx context.Context, desc v1.Descriptor, blobs map[digest.Digest]int64) error {
+	if _, ok := blobs[desc.Digest]; ok {
+		return nil
+	}
+	blobs[desc.Digest] = desc.Size
+
+	children, ok := b.successors[desc.Digest]
+	if !ok {
+		var err error
+		children, err = content.Successors(ctx, b.fetcher, desc)
+		if err != nil {
+			return fmt.Errorf("failed to walk %s: %w", desc.Digest, err)
+		}
+		b.successors[desc.Digest] = children
+	}
+
+	for _, child := range children {
+		// Subjects belong to the referrer, not the other way around
+		if err := b.walk(ctx, child, blobs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestBlobWalker(t *testing.T) {
+	ctx := context.Background()
+	store, desc := packTestStack(t, "app.yaml", testRGD("app"))
+	manifest := testManifest(t, store, desc)
+
+	walker := &blobWalker{fetcher: store, successors: map[digest.Digest][]v1.Descriptor{}}
+	blobs := map[digest.Digest]int64{}
+	require.NoError(t, walker.walk(ctx, desc, blobs))
+
+	assert.Equal(t, map[digest.Digest]int64{
+		desc.Digest:               desc.Size,
+		manifest.Config.Digest:    manifest.Config.Size,
+		manifest.Layers[0].Digest: manifest.Layers[0].Size,
+	}, blobs)
+
+	// Another tag of the same manifest is walked from the cache, without
+	// fetching it again
+	walker.fetcher = memory.New()
+	again := map[digest.Digest]int64{}
+	require.NoError(t, walker.walk(ctx, desc, again))
+	assert.Equal(t, blobs, again)
+}
@@ -0,0 +1,208 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type SplitOptions struct {
+	Reference string
+	Tag       string
+}
+
+func NewSplitCommand(cli *CLI) *cobra.Command {
+	opts := SplitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "split <reference>",
+		Short: "Publish each RGD of a stack as its own artifact",
+		Long: "Publish each RGD of a stack as its own artifact.\n\n" +
+			"Every ResourceGraphDefinition in the artifact is pushed to\n" +
+			"<repository>/<rgd-name>:<tag> in the same registry, so consumers\n" +
+			"can depend on individual definitions. The tag defaults to the\n" +
+			"tag of the reference. Annotations are preserved.\n\n" +
+			"A file holding several ResourceGraphDefinitions is split into\n" +
+			"one artifact per document, each file titled <rgd-name>.yaml.\n\n" +
+			"Examples:\n" +
+			"  kroctl split ghcr.io/acme/platform:v1.0.0\n\n" +
+			"  kroctl split ghcr.io/acme/platform@sha256:... --tag v1.0.0\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunSplit(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Tag, "tag", "", "Tag for the published artifacts, defaults to the tag of the reference")
+
+	return cmd
+}
+
+func RunSplit(ctx context.Context, cli *CLI, opts *SplitOptions) error {
+	cli.Logger().Info("Splitting artifact", "reference", opts.Reference)
+
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	tag := opts.Tag
+	if tag == "" {
+		if err := repo.Reference.ValidateReferenceAsTag(); err != nil {
+			return fmt.Errorf("%s is not tagged, use --tag to name the published artifacts", opts.Reference)
+		}
+		tag = repo.Reference.Reference
+	}
+
+	_, manifest, err := oci.FetchManifest(ctx, repo, opts.Reference, "")
+	if err != nil {
+		return err
+	}
+	if !oci.IsStack(manifest) {
+		return fmt.Errorf("%s is not a kro RGD stack", opts.Reference)
+	}
+
+	annotations := map[string]string{}
+	for k, v := range manifest.Annotations {
+		if k != v1.AnnotationCreated {
+			annotations[k] = v
+		}
+	}
+
+	result := &api.SplitResult{
+		TypeMeta:  api.NewTypeMeta(api.KindSplitResult),
+		Reference: opts.Reference,
+		Artifacts: []api.SplitArtifact{},
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != oci.LayerMediaType {
+			continue
+		}
+
+		data, err := oci.FetchLayer(ctx, repo, layer)
+		if err != nil {
+			return err
+		}
+		parts, err := splitLayer(layer, data)
+		if err != nil {
+			return err
+		}
+
+		for _, part := range parts {
+			target := fmt.Sprintf("%s/%s/%s:%s",
+				repo.Reference.Registry, repo.Reference.Repository, part.name, tag)
+			digest, err := pushLayer(ctx, part.layer, part.data, annotations, target)
+			if err != nil {
+				return err
+			}
+
+			cli.Logger().Debug("Pushed artifact", "reference", target, "digest", digest)
+			result.Artifacts = append(result.Artifacts, api.SplitArtifact{
+				File:      oci.LayerTitle(part.layer),
+				Reference: target,
+				Digest:    digest,
+			})
+		}
+	}
+
+	if len(result.Artifacts) == 0 {
+		return fmt.Errorf("no ResourceGraphDefinitions found in %s", opts.Reference)
+	}
+
+	err = render(cli, result, func(s *view.Stream, result *api.SplitResult) error {
+		for _, a := range result.Artifacts {
+			s.Printf("Pushed %s to %s\n", a.File, a.Reference)
+		}
+		s.Printf("Successfully split %s into %d artifact(s)\n", result.Reference, len(result.Artifacts))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, a := range result.Artifacts {
+		cli.auditChange("split", a.Reference, a.Digest)
+	}
+	return nil
+}
+
+// splitPart is a single RGD of a stack layer, published on its own.
+type splitPart struct {
+	name  string
+	layer v1.Descriptor
+	data  []byte
+}
+
+// splitLayer returns the RGDs of a layer. A layer holding a single RGD is
+// kept as is. Each document of a layer holding several becomes a layer of
+// its own, titled after the RGD it holds.
+func splitLayer(layer v1.Descriptor, data []byte) ([]splitPart, error) {
+	title := oci.LayerTitle(layer)
+	rgds, err := rgd.Parse(title, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", title, err)
+	}
+	if len(rgds) == 1 {
+		return []splitPart{{name: rgds[0].Metadata.Name, layer: layer, data: data}}, nil
+	}
+
+	var parts []splitPart
+	for _, doc := range rgd.Documents(data) {
+		// The layer parsed as a whole, so each document holds at most one RGD
+		rgds, err := rgd.Parse(title, doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", title, err)
+		}
+		if len(rgds) == 0 {
+			continue
+		}
+
+		name := rgds[0].Metadata.Name
+		desc := content.NewDescriptorFromBytes(oci.LayerMediaType, doc)
+		desc.Annotations = map[string]string{v1.AnnotationTitle: name + ".yaml"}
+		parts = append(parts, splitPart{name: name, layer: desc, data: doc})
+	}
+	return parts, nil
+}
+
+// pushLayer publishes a single layer as a stack artifact at reference.
+func pushLayer(ctx context.Context, layer v1.Descriptor, data []byte, annotations map[string]string, reference string) (string, error) {
+	store := memory.New()
+	if err := store.Push(ctx, layer, bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", oci.LayerTitle(layer), err)
+	}
+
+	layers := []v1.Descriptor{layer}
+	config, err := oci.NewStackConfig(reference, layers, [][]byte{data})
+	if err != nil {
+		return "", err
+	}
+	manifestDesc, err := oci.PackStack(ctx, store, config, layers, annotations, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := store.Tag(ctx, manifestDesc, reference); err != nil {
+		return "", fmt.Errorf("failed to tag manifest: %w", err)
+	}
+
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		return "", err
+	}
+	if _, err := oras.Copy(ctx, store, reference, repo, reference, oras.DefaultCopyOptions); err != nil {
+		return "", fmt.Errorf("failed to push %s: %w", reference, err)
+	}
+
+	return manifestDesc.Digest.String(), nil
+}
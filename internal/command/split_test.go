@@ -0,0 +1,60 @@
+package command
+
+import (
+	"slices"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func stackLayer(title string, data []byte) v1.Descriptor {
+	desc := content.NewDescriptorFromBytes(oci.LayerMediaType, data)
+	desc.Annotations = map[string]string{v1.AnnotationTitle: title}
+	return desc
+}
+
+func TestSplitLayer_Single(t *testing.T) {
+	data := testRGD("network")
+	layer := stackLayer("platform.yaml", data)
+
+	parts, err := splitLayer(layer, data)
+	require.NoError(t, err)
+
+	// The layer is published unchanged, so the registry can reuse the blob
+	require.Len(t, parts, 1)
+	assert.Equal(t, "network", parts[0].name)
+	assert.Equal(t, layer, parts[0].layer)
+	assert.Equal(t, data, parts[0].data)
+}
+
+func TestSplitLayer_MultipleDocuments(t *testing.T) {
+	network, app := testRGD("network"), testRGD("app")
+	data := slices.Concat(network, []byte("---\n"), app, []byte("---\n"))
+
+	parts, err := splitLayer(stackLayer("platform.yaml", data), data)
+	require.NoError(t, err)
+
+	require.Len(t, parts, 2)
+	for i, want := range []struct {
+		name string
+		data []byte
+	}{{"network", network}, {"app", app}} {
+		assert.Equal(t, want.name, parts[i].name)
+		assert.Equal(t, want.data, parts[i].data)
+		assert.Equal(t, want.name+".yaml", oci.LayerTitle(parts[i].layer))
+		assert.Equal(t, oci.LayerMediaType, parts[i].layer.MediaType)
+		assert.Equal(t, content.NewDescriptorFromBytes(oci.LayerMediaType, want.data).Digest, parts[i].layer.Digest)
+	}
+}
+
+func TestSplitLayer_Invalid(t *testing.T) {
+	data := []byte("apiVersion: v1\nkind: ConfigMap\n")
+
+	_, err := splitLayer(stackLayer("platform.yaml", data), data)
+	require.ErrorContains(t, err, "failed to parse platform.yaml")
+}
@@ -0,0 +1,221 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+// stackFile is a single RGD manifest of a stack, read either from an
+// artifact layer or from a local file.
+type stackFile struct {
+	Name    string
+	Content []byte
+}
+
+// downloadConcurrency bounds the number of layers downloaded at once.
+const downloadConcurrency = 4
+
+// pullOptions control how the files of a stack are pulled.
+type pullOptions struct {
+	// Variant picks the manifest when the reference is an index
+	Variant string
+	// VerifyChecksums checks the files against the SHA256SUMS layer
+	VerifyChecksums bool
+	// RequireApproval refuses stacks that have no approval attached
+	RequireApproval bool
+	// RefuseDeprecated fails on deprecated stacks instead of warning
+	RefuseDeprecated bool
+	// AnyArtifactType reads the RGD layers of artifacts that are not typed
+	// as a kro RGD stack
+	AnyArtifactType bool
+}
+
+// pullStackFiles downloads the RGD layers of the artifact at reference, and
+// returns them with the manifest they were read from. Layers of other media
+// types are skipped.
+func pullStackFiles(ctx context.Context, cli *CLI, repo *remote.Repository, reference string, opts pullOptions) (*v1.Manifest, []stackFile, error) {
+	defer cli.phase("download")()
+
+	desc, manifest, err := oci.FetchManifest(ctx, repo, reference, opts.Variant)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !oci.IsStack(manifest) {
+		if !opts.AnyArtifactType {
+			return nil, nil, errNotStack(reference, manifest)
+		}
+		cli.Logger().Warn("Artifact is not a kro RGD stack, reading its RGD layers anyway",
+			"reference", reference,
+			"artifactType", oci.TypeOf(manifest))
+	}
+
+	if opts.RequireApproval {
+		approvals, err := oci.Approvals(ctx, repo, desc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(approvals) == 0 {
+			return nil, nil, fmt.Errorf("%s@%s is not approved, approve it with kroctl approve", reference, desc.Digest)
+		}
+		for _, a := range approvals {
+			cli.Logger().Debug("Found approval", "by", a.By, "ticket", a.Ticket, "created", a.Created)
+		}
+	}
+
+	deprecations, err := oci.Deprecations(ctx, repo, desc)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range deprecations {
+		if opts.RefuseDeprecated {
+			return nil, nil, fmt.Errorf("%s@%s is deprecated: %s, use --allow-deprecated to use it anyway", reference, desc.Digest, d.Message)
+		}
+		cli.warn("%s is deprecated: %s", reference, d.Message)
+	}
+
+	var layers []v1.Descriptor
+	var checksumsLayer *v1.Descriptor
+	for i, layer := range manifest.Layers {
+		if layer.MediaType == oci.ChecksumsMediaType && opts.VerifyChecksums {
+			checksumsLayer = &manifest.Layers[i]
+			continue
+		}
+		if layer.MediaType != oci.LayerMediaType {
+			cli.Logger().Debug("Skipping non-RGD layer",
+				"digest", layer.Digest.String(),
+				"mediaType", layer.MediaType)
+			continue
+		}
+		layers = append(layers, layer)
+	}
+	if checksumsLayer != nil {
+		layers = append(layers, *checksumsLayer)
+	}
+
+	contents, err := fetchLayers(ctx, repo, layers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var checksums []byte
+	if checksumsLayer != nil {
+		checksums = contents[len(contents)-1]
+		contents = contents[:len(contents)-1]
+		layers = layers[:len(layers)-1]
+	}
+
+	files := make([]stackFile, 0, len(layers))
+	for i, layer := range layers {
+		// Titles come from the registry, never let them escape a directory
+		name := filepath.Base(filepath.Clean("/" + oci.LayerTitle(layer)))
+		files = append(files, stackFile{Name: name, Content: contents[i]})
+	}
+
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no ResourceGraphDefinitions found in %s", reference)
+	}
+
+	if opts.VerifyChecksums {
+		if checksums == nil {
+			return nil, nil, fmt.Errorf("%s has no %s layer to verify against", reference, checksumsFile)
+		}
+		if err := verifyChecksums(files, checksums); err != nil {
+			return nil, nil, err
+		}
+		cli.Logger().Debug("Verified checksums", "files", len(files))
+	}
+
+	return manifest, files, nil
+}
+
+// errNotStack describes an artifact that is not typed as a kro RGD stack,
+// for commands that read it anyway with --any-artifact-type.
+func errNotStack(reference string, manifest *v1.Manifest) error {
+	return fmt.Errorf("%s is a %s, not a kro RGD stack (%s), use --any-artifact-type to read it anyway",
+		reference, describeType(oci.TypeOf(manifest)), oci.ArtifactType)
+}
+
+// fetchLayers downloads the given layers with up to downloadConcurrency
+// requests in flight. Contents are returned in the order of layers; every
+// failed download is reported, not just the first.
+func fetchLayers(ctx context.Context, repo *remote.Repository, layers []v1.Descriptor) ([][]byte, error) {
+	contents := make([][]byte, len(layers))
+	errs := make([]error, len(layers))
+
+	sem := make(chan struct{}, downloadConcurrency)
+	var wg sync.WaitGroup
+	for i, layer := range layers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			contents[i], errs[i] = oci.FetchLayer(ctx, repo, layer)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// readStackFiles reads the YAML files in the given files and directories,
+// naming them by their base name as push does for layer titles.
+func readStackFiles(filenames []string) ([]stackFile, error) {
+	paths, err := collectYAMLFiles(filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]stackFile, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		files = append(files, stackFile{Name: filepath.Base(path), Content: data})
+	}
+	return files, nil
+}
+
+// loadStackFiles reads a stack from a local file or directory when source
+// exists on disk, and pulls it from the registry otherwise.
+func loadStackFiles(ctx context.Context, cli *CLI, source string) ([]stackFile, error) {
+	if _, err := os.Stat(source); err == nil {
+		cli.Logger().Debug("Reading stack from disk", "path", source)
+		return readStackFiles([]string{source})
+	}
+
+	repo, err := oci.SetupRepository(source)
+	if err != nil {
+		return nil, err
+	}
+	_, files, err := pullStackFiles(ctx, cli, repo, source, pullOptions{})
+	return files, err
+}
+
+// parseStackFiles parses the ResourceGraphDefinitions of every file.
+func parseStackFiles(files []stackFile) ([]*rgd.ResourceGraphDefinition, error) {
+	var rgds []*rgd.ResourceGraphDefinition
+	for _, file := range files {
+		parsed, err := rgd.Parse(file.Name, file.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name, err)
+		}
+		rgds = append(rgds, parsed...)
+	}
+	return rgds, nil
+}
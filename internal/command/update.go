@@ -0,0 +1,158 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type UpdateOptions struct {
+	VendorDir string
+	Write     bool
+}
+
+func NewUpdateCommand(cli *CLI) *cobra.Command {
+	opts := UpdateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update vendored stacks to their newest versions",
+		Long: "Update vendored stacks to their newest versions.\n\n" +
+			"Finds the vendored stacks with a newer version, as kroctl\n" +
+			"outdated reports them, and lists the API changes between the\n" +
+			"vendored copy and the newest version, as kroctl changelog does.\n\n" +
+			"Without --write nothing is changed. With --write the newest\n" +
+			"versions are vendored in place of the old ones and vendor.yaml is\n" +
+			"updated.\n\n" +
+			"With --json, the summary of old and new versions, digests, and\n" +
+			"changes is meant for bots that turn updates into pull requests.\n\n" +
+			"Examples:\n" +
+			"  kroctl update\n\n" +
+			"  kroctl update --write --json\n",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunUpdate(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.VendorDir, "vendor-dir", filepath.Join("vendor", "kro"), "Vendor directory to update")
+	cmd.Flags().BoolVar(&opts.Write, "write", false, "Vendor the newest versions and update vendor.yaml")
+
+	return cmd
+}
+
+func RunUpdate(ctx context.Context, cli *CLI, opts *UpdateOptions) error {
+	manifestPath := filepath.Join(opts.VendorDir, vendorManifestFile)
+	manifest, err := readVendorManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if len(manifest.Stacks) == 0 {
+		return fmt.Errorf("no stacks found in %s, vendor stacks with kroctl vendor first", manifestPath)
+	}
+
+	references := make([]string, 0, len(manifest.Stacks))
+	for _, stack := range manifest.Stacks {
+		references = append(references, stack.Reference)
+	}
+	outdated, err := checkOutdated(ctx, cli, references)
+	if err != nil {
+		return err
+	}
+
+	report := &api.UpdateReport{
+		TypeMeta: api.NewTypeMeta(api.KindUpdateReport),
+		Written:  opts.Write,
+		Updates:  []api.StackUpdate{},
+	}
+	// Stacks line up with the manifest, checkOutdated keeps the order
+	vendored := manifest.Stacks
+	for i, stack := range outdated.Stacks {
+		if stack.Status != OutdatedStatusAvailable {
+			continue
+		}
+		old := vendored[i]
+
+		ref, err := registry.ParseReference(stack.Reference)
+		if err != nil {
+			return fmt.Errorf("invalid reference %s: %w", stack.Reference, err)
+		}
+		ref.Reference = stack.Latest
+
+		next, files, err := pullVendoredStack(ctx, cli, ref.String())
+		if err != nil {
+			return err
+		}
+		oldFiles, err := readStackFiles([]string{filepath.Join(opts.VendorDir, filepath.FromSlash(old.Path))})
+		if err != nil {
+			return err
+		}
+		changes, err := compareAPIs(oldFiles, files)
+		if err != nil {
+			return err
+		}
+
+		update := api.StackUpdate{
+			Reference:    old.Reference,
+			From:         stack.Current,
+			To:           stack.Latest,
+			NewReference: next.Reference,
+			OldDigest:    old.Digest,
+			NewDigest:    next.Digest,
+			Changes:      make([]string, 0, len(changes)),
+		}
+		for _, c := range changes {
+			update.Changes = append(update.Changes, c.String())
+			update.Breaking = update.Breaking || c.Breaking
+		}
+		report.Updates = append(report.Updates, update)
+
+		if !opts.Write {
+			continue
+		}
+		if err := writeVendoredStack(opts.VendorDir, next, files); err != nil {
+			return err
+		}
+		if err := removeVendoredStack(opts.VendorDir, old); err != nil {
+			return err
+		}
+		manifest.remove(old)
+		manifest.add(next)
+		cli.Logger().Debug("Updated vendored stack", "from", old.Path, "to", next.Path)
+	}
+
+	if opts.Write && len(report.Updates) > 0 {
+		if err := writeYAML(manifestPath, manifest); err != nil {
+			return err
+		}
+	}
+
+	return render(cli, report, func(s *view.Stream, report *api.UpdateReport) error {
+		if len(report.Updates) == 0 {
+			s.Println("All vendored stacks are up to date")
+			return nil
+		}
+		for _, u := range report.Updates {
+			line := fmt.Sprintf("%s: %s -> %s", u.Reference, u.From, u.To)
+			if u.Breaking {
+				line += " (breaking)"
+			}
+			s.Println(highlight("%s", line))
+			for _, c := range u.Changes {
+				s.Printf("  %s\n", c)
+			}
+		}
+		if report.Written {
+			s.Printf("\nUpdated %d stack(s) in %s\n", len(report.Updates), opts.VendorDir)
+		} else {
+			s.Printf("\nRun with --write to update %d stack(s) in %s\n", len(report.Updates), opts.VendorDir)
+		}
+		return nil
+	})
+}
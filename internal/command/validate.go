@@ -0,0 +1,151 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type ValidateOptions struct {
+	Filenames         []string
+	KubernetesVersion string
+}
+
+func NewValidateCommand(cli *CLI) *cobra.Command {
+	opts := ValidateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate ResourceGraphDefinition files",
+		Long: "Validate ResourceGraphDefinition files.\n\n" +
+			"Strictly decodes each file and reports problems with their file\n" +
+			"and line: tab indentation, duplicate keys, fields that are not\n" +
+			"part of a ResourceGraphDefinition, and objects of another kind.\n\n" +
+			"The simpleSchema of the instance API is linted as well: unknown\n" +
+			"types, defaults that do not match their type, misspelled markers,\n" +
+			"and status fields that reference resources missing from the graph.\n\n" +
+			"References between resources are checked too: expressions must\n" +
+			"point at declared resource IDs and schema fields, and resources\n" +
+			"must not depend on each other in a cycle.\n\n" +
+			"Resource templates using deprecated Kubernetes APIs produce a\n" +
+			"warning. Use --kubernetes-version to turn APIs that are removed\n" +
+			"in the targeted cluster version into errors.\n" +
+			"The same checks run before push, so broken YAML never ends up\n" +
+			"in an artifact.\n\n" +
+			"In GitHub Actions, findings are reported as workflow annotations\n" +
+			"on the offending lines.\n\n" +
+			"Examples:\n" +
+			"  kroctl validate -f ./rgds/\n\n" +
+			"  kroctl validate -f stack.yaml -f vpc.yaml\n\n" +
+			"  kroctl validate -f ./rgds/ --kubernetes-version 1.29\n",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunValidate(cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.Filenames, "filenames", "f",
+		[]string{}, "RGD files or directories to validate (required)")
+	cmd.Flags().StringVar(&opts.KubernetesVersion, "kubernetes-version", "",
+		"Kubernetes version the RGDs target, e.g. 1.29")
+	_ = cmd.MarkFlagRequired("filenames")
+
+	return cmd
+}
+
+func RunValidate(cli *CLI, opts *ValidateOptions) error {
+	files, err := collectYAMLFiles(opts.Filenames)
+	if err != nil {
+		return err
+	}
+
+	lintOpts := rgd.LintOptions{}
+	if opts.KubernetesVersion != "" {
+		lintOpts.KubernetesVersion, err = rgd.ParseKubernetesVersion(opts.KubernetesVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	diags, err := validateFiles(cli, files, lintOpts)
+	if err != nil {
+		return err
+	}
+
+	report := &api.LintReport{
+		TypeMeta:    api.NewTypeMeta(api.KindLintReport),
+		Files:       len(files),
+		Diagnostics: make([]api.Diagnostic, 0, len(diags)),
+	}
+	for _, diag := range diags {
+		if diag.Severity == rgd.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+		report.Diagnostics = append(report.Diagnostics, api.Diagnostic{
+			File:     diag.File,
+			Line:     diag.Line,
+			Severity: diag.Severity.String(),
+			Message:  diag.Message,
+		})
+	}
+
+	err = render(cli, report, func(s *view.Stream, report *api.LintReport) error {
+		for _, diag := range diags {
+			if cli.GitHubActions {
+				s.Println(workflowCommand(diag))
+				continue
+			}
+			s.Println(diag.String())
+		}
+		if report.Errors == 0 {
+			s.Printf("%d file(s) are valid\n", report.Files)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("validation failed with %d error(s)", report.Errors)
+	}
+
+	return nil
+}
+
+// validateFiles parses every file and collects the diagnostics of all of
+// them, so a single run reports every problem instead of only the first.
+func validateFiles(cli *CLI, files []string, opts rgd.LintOptions) (rgd.Diagnostics, error) {
+	var all rgd.Diagnostics
+	for _, filename := range files {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+
+		rgds, err := rgd.Parse(filename, data)
+		var diags rgd.Diagnostics
+		if errors.As(err, &diags) {
+			all = append(all, diags...)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range rgds {
+			all = append(all, rgd.Lint(filename, r, opts)...)
+		}
+
+		cli.Logger().Debug("Validated file", "file", filename, "rgds", len(rgds))
+	}
+	return all, nil
+}
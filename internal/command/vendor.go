@@ -0,0 +1,232 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+// vendorManifestFile lists what was vendored, in the vendor directory.
+const vendorManifestFile = "vendor.yaml"
+
+type VendorOptions struct {
+	References []string
+	Output     string
+}
+
+// vendorManifest records the exact stacks in a vendor directory, so a
+// review can check them against the registry.
+type vendorManifest struct {
+	Stacks []vendoredStack `yaml:"stacks"`
+}
+
+type vendoredStack struct {
+	Reference string `yaml:"reference"`
+	// Digest is what the reference resolved to when it was vendored
+	Digest string `yaml:"digest"`
+	// Path is the directory of the stack, relative to the vendor directory
+	Path  string         `yaml:"path"`
+	Files []vendoredFile `yaml:"files"`
+}
+
+type vendoredFile struct {
+	Name   string `yaml:"name"`
+	Digest string `yaml:"digest"`
+}
+
+func NewVendorCommand(cli *CLI) *cobra.Command {
+	opts := VendorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "vendor <reference>...",
+		Short: "Copy RGD stacks into a vendor directory",
+		Long: "Copy RGD stacks into a vendor directory.\n\n" +
+			"Pulls the ResourceGraphDefinitions of each stack into\n" +
+			"<output>/<name>/<tag>, so repositories can commit exact copies\n" +
+			"for air-gapped or review-required workflows. Stacks referenced by\n" +
+			"digest are stored under the digest instead of a tag.\n\n" +
+			"The vendor.yaml in the output directory records the digest every\n" +
+			"reference resolved to and the digest of every file. Vendoring a\n" +
+			"stack again replaces its directory and its entry, other entries\n" +
+			"are kept.\n\n" +
+			"Examples:\n" +
+			"  kroctl vendor ghcr.io/acme/network:v1.2.0 ghcr.io/acme/database:v2.0.1\n\n" +
+			"  kroctl vendor ghcr.io/acme/network:v1.2.0 -o third_party/kro\n",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.References = args
+			return RunVendor(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", filepath.Join("vendor", "kro"), "Vendor directory")
+
+	return cmd
+}
+
+func RunVendor(ctx context.Context, cli *CLI, opts *VendorOptions) error {
+	manifestPath := filepath.Join(opts.Output, vendorManifestFile)
+	manifest, err := readVendorManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	result := &api.VendorResult{
+		TypeMeta: api.NewTypeMeta(api.KindVendorResult),
+		Output:   opts.Output,
+		Stacks:   make([]api.VendoredStack, 0, len(opts.References)),
+	}
+	for _, reference := range opts.References {
+		stack, files, err := pullVendoredStack(ctx, cli, reference)
+		if err != nil {
+			return err
+		}
+		if err := writeVendoredStack(opts.Output, stack, files); err != nil {
+			return err
+		}
+		manifest.add(stack)
+
+		vendored := api.VendoredStack{
+			Reference: stack.Reference,
+			Digest:    stack.Digest,
+			Path:      stack.Path,
+			Files:     make([]string, 0, len(stack.Files)),
+		}
+		for _, f := range stack.Files {
+			vendored.Files = append(vendored.Files, f.Name)
+		}
+		result.Stacks = append(result.Stacks, vendored)
+	}
+
+	if err := writeYAML(manifestPath, manifest); err != nil {
+		return err
+	}
+
+	return render(cli, result, func(s *view.Stream, result *api.VendorResult) error {
+		for _, stack := range result.Stacks {
+			s.Printf("Vendored %s to %s\n", stack.Reference, filepath.Join(result.Output, filepath.FromSlash(stack.Path)))
+		}
+		return nil
+	})
+}
+
+// pullVendoredStack pulls the stack at reference and describes it for the
+// vendor manifest.
+func pullVendoredStack(ctx context.Context, cli *CLI, reference string) (vendoredStack, []stackFile, error) {
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		return vendoredStack{}, nil, err
+	}
+
+	// Pull by digest, so the files match the digest that is recorded
+	desc, err := oci.Resolve(ctx, repo, reference)
+	if err != nil {
+		return vendoredStack{}, nil, err
+	}
+	pinned := repo.Reference
+	pinned.Reference = desc.Digest.String()
+
+	_, files, err := pullStackFiles(ctx, cli, repo, pinned.String(), pullOptions{})
+	if err != nil {
+		return vendoredStack{}, nil, err
+	}
+
+	stack := vendoredStack{
+		Reference: reference,
+		Digest:    desc.Digest.String(),
+		Path:      vendorPath(repo.Reference, desc.Digest),
+	}
+	for _, f := range files {
+		stack.Files = append(stack.Files, vendoredFile{Name: f.Name, Digest: digest.FromBytes(f.Content).String()})
+	}
+	return stack, files, nil
+}
+
+// writeVendoredStack writes the files of a stack to its directory below dir.
+func writeVendoredStack(dir string, stack vendoredStack, files []stackFile) error {
+	stackDir := filepath.Join(dir, filepath.FromSlash(stack.Path))
+	// Files dropped from the stack must not linger in the copy
+	if err := os.RemoveAll(stackDir); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", stackDir, err)
+	}
+	if err := os.MkdirAll(stackDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", stackDir, err)
+	}
+	for _, f := range files {
+		if err := writeFile(filepath.Join(stackDir, f.Name), f.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeVendoredStack deletes the directory of a stack below dir, and the
+// directory of its repository once no other version is left in it.
+func removeVendoredStack(dir string, stack vendoredStack) error {
+	stackDir := filepath.Join(dir, filepath.FromSlash(stack.Path))
+	if err := os.RemoveAll(stackDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", stackDir, err)
+	}
+	// Fails harmlessly while other versions remain
+	_ = os.Remove(filepath.Dir(stackDir))
+	return nil
+}
+
+// vendorPath returns the directory of a stack in the vendor directory: the
+// last element of the repository and the tag, or the digest for references
+// without a tag.
+func vendorPath(ref registry.Reference, dgst digest.Digest) string {
+	version := ref.Reference
+	if ref.ValidateReferenceAsTag() != nil {
+		version = dgst.Algorithm().String() + "-" + dgst.Encoded()
+	}
+	return path.Join(path.Base(ref.Repository), version)
+}
+
+// readVendorManifest reads the manifest of a vendor directory, which is
+// empty before the first vendor run.
+func readVendorManifest(filename string) (*vendorManifest, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return &vendorManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	var manifest vendorManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return &manifest, nil
+}
+
+// remove drops the entry of a vendored stack.
+func (m *vendorManifest) remove(stack vendoredStack) {
+	m.Stacks = slices.DeleteFunc(m.Stacks, func(s vendoredStack) bool {
+		return s.Path == stack.Path
+	})
+}
+
+// add records a vendored stack, replacing the entry of the same directory.
+// Entries are kept sorted by path, so the manifest diffs cleanly.
+func (m *vendorManifest) add(stack vendoredStack) {
+	m.remove(stack)
+	m.Stacks = append(m.Stacks, stack)
+	slices.SortFunc(m.Stacks, func(a, b vendoredStack) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+}
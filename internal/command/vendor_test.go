@@ -0,0 +1,58 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestVendorPath(t *testing.T) {
+	dgst := digest.FromString("stack")
+
+	tagged, err := registry.ParseReference("ghcr.io/acme/platform/network:v1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "network/v1.2.0", vendorPath(tagged, dgst))
+
+	pinned, err := registry.ParseReference("ghcr.io/acme/network@" + dgst.String())
+	require.NoError(t, err)
+	assert.Equal(t, "network/sha256-"+dgst.Encoded(), vendorPath(pinned, dgst))
+}
+
+func TestVendorManifest(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), vendorManifestFile)
+
+	manifest, err := readVendorManifest(filename)
+	require.NoError(t, err)
+	assert.Empty(t, manifest.Stacks)
+
+	manifest.add(vendoredStack{Reference: "r/network:v1", Path: "network/v1", Digest: "sha256:a"})
+	manifest.add(vendoredStack{Reference: "r/database:v2", Path: "database/v2"})
+	manifest.add(vendoredStack{Reference: "r/network:v1", Path: "network/v1", Digest: "sha256:b"})
+	require.NoError(t, writeYAML(filename, manifest))
+
+	read, err := readVendorManifest(filename)
+	require.NoError(t, err)
+	require.Len(t, read.Stacks, 2)
+	assert.Equal(t, "database/v2", read.Stacks[0].Path)
+	assert.Equal(t, "sha256:b", read.Stacks[1].Digest)
+}
+
+func TestRemoveVendoredStack(t *testing.T) {
+	dir := t.TempDir()
+	files := []stackFile{{Name: "app.yaml", Content: testRGD("app")}}
+	v1 := vendoredStack{Path: "app/v1"}
+	v2 := vendoredStack{Path: "app/v2"}
+	require.NoError(t, writeVendoredStack(dir, v1, files))
+	require.NoError(t, writeVendoredStack(dir, v2, files))
+
+	require.NoError(t, removeVendoredStack(dir, v1))
+	assert.NoDirExists(t, filepath.Join(dir, "app", "v1"))
+	assert.FileExists(t, filepath.Join(dir, "app", "v2", "app.yaml"))
+
+	require.NoError(t, removeVendoredStack(dir, v2))
+	assert.NoDirExists(t, filepath.Join(dir, "app"))
+}
@@ -0,0 +1,168 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type VerifyLayoutOptions struct {
+	Reference string
+	Variant   string
+}
+
+// knownLayerTypes are the layer media types kroctl writes to a stack.
+var knownLayerTypes = []string{
+	oci.LayerMediaType,
+	oci.ChecksumsMediaType,
+	oci.DocsMediaType,
+	oci.ExamplesMediaType,
+	oci.ArchiveMediaType,
+}
+
+func NewVerifyLayoutCommand(cli *CLI) *cobra.Command {
+	opts := VerifyLayoutOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-layout <reference>",
+		Short: "Check that an artifact is a well-formed RGD stack",
+		Long: "Check that an artifact is a well-formed RGD stack.\n\n" +
+			"Verifies the layout kroctl pushes: the artifact type is a kro RGD\n" +
+			"stack, every layer has a known media type and a unique title, RGD\n" +
+			"layers parse as ResourceGraphDefinitions, and the SHA256SUMS layer\n" +
+			"and the stack config, when present, match the layers.\n\n" +
+			"Use it to audit a registry for malformed uploads; the command fails\n" +
+			"when any check does.\n\n" +
+			"Examples:\n" +
+			"  kroctl verify-layout ghcr.io/acme/kro-stack:v1.0.0\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Reference = args[0]
+			return RunVerifyLayout(cmd.Context(), cli, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Variant, "variant", "", "Variant to verify when the reference is an index of variants")
+
+	return cmd
+}
+
+func RunVerifyLayout(ctx context.Context, cli *CLI, opts *VerifyLayoutOptions) error {
+	repo, err := oci.SetupRepository(opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	desc, manifest, err := oci.FetchManifest(ctx, repo, opts.Reference, opts.Variant)
+	if err != nil {
+		return err
+	}
+
+	problems, err := verifyLayout(ctx, repo, manifest)
+	if err != nil {
+		return err
+	}
+
+	report := &api.LayoutReport{
+		TypeMeta:  api.NewTypeMeta(api.KindLayoutReport),
+		Reference: opts.Reference,
+		Digest:    desc.Digest.String(),
+		Problems:  problems,
+	}
+	err = render(cli, report, func(s *view.Stream, report *api.LayoutReport) error {
+		if len(report.Problems) == 0 {
+			s.Printf("%s@%s matches the kroctl layout\n", report.Reference, report.Digest)
+			return nil
+		}
+		s.Printf("%s@%s does not match the kroctl layout:\n", report.Reference, report.Digest)
+		for _, p := range report.Problems {
+			s.Printf("  - %s\n", p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d layout problem(s)", len(problems))
+	}
+	return nil
+}
+
+// verifyLayout returns the ways a manifest deviates from the layout kroctl
+// pushes. Errors are reserved for failures to download content.
+func verifyLayout(ctx context.Context, fetcher content.Fetcher, manifest *v1.Manifest) ([]string, error) {
+	problems := []string{}
+	if !oci.IsStack(manifest) {
+		problems = append(problems, fmt.Sprintf("artifact type is %s, expected %s", orDash(oci.TypeOf(manifest)), oci.ArtifactType))
+	}
+
+	var files []stackFile
+	var checksums []byte
+	titles := map[string]bool{}
+	for i, layer := range manifest.Layers {
+		title, ok := layer.Annotations[v1.AnnotationTitle]
+		switch {
+		case !ok || title == "":
+			problems = append(problems, fmt.Sprintf("layer %d (%s) has no title", i, layer.Digest))
+		case titles[title]:
+			problems = append(problems, fmt.Sprintf("title %s is used by more than one layer", title))
+		}
+		titles[title] = true
+
+		if !slices.Contains(knownLayerTypes, layer.MediaType) {
+			problems = append(problems, fmt.Sprintf("layer %s has unknown media type %s", oci.LayerTitle(layer), layer.MediaType))
+			continue
+		}
+		if layer.MediaType != oci.LayerMediaType && layer.MediaType != oci.ChecksumsMediaType {
+			continue
+		}
+
+		data, err := oci.FetchLayer(ctx, fetcher, layer)
+		if err != nil {
+			return nil, err
+		}
+		if layer.MediaType == oci.ChecksumsMediaType {
+			checksums = data
+			continue
+		}
+		if _, err := rgd.Parse(oci.LayerTitle(layer), data); err != nil {
+			problems = append(problems, fmt.Sprintf("layer %s is not valid: %v", oci.LayerTitle(layer), err))
+		}
+		files = append(files, stackFile{Name: oci.LayerTitle(layer), Content: data})
+	}
+
+	if checksums != nil {
+		if err := verifyChecksums(files, checksums); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	config, err := oci.FetchConfig(ctx, fetcher, manifest)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if config != nil {
+		digests := map[string]bool{}
+		for _, layer := range manifest.Layers {
+			digests[layer.Digest.String()] = true
+		}
+		for _, r := range config.RGDs {
+			if !digests[r.Digest] {
+				problems = append(problems, fmt.Sprintf("config lists %s in %s, which is not a layer", r.Name, r.File))
+			}
+		}
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestVerifyLayout(t *testing.T) {
+	app := testRGD("app")
+	sum := sha256.Sum256(app)
+	appSum := hex.EncodeToString(sum[:])
+	badSum := hex.EncodeToString(make([]byte, sha256.Size))
+	other := testRGD("other")
+	appDigest := content.NewDescriptorFromBytes(oci.LayerMediaType, app).Digest
+
+	tests := []struct {
+		name string
+		// edit changes the stack manifest, pushing any blobs it adds to store
+		edit func(t *testing.T, store *memory.Store, manifest *v1.Manifest)
+		want []string
+	}{
+		{
+			name: "valid",
+			edit: func(t *testing.T, store *memory.Store, manifest *v1.Manifest) {},
+			want: []string{},
+		},
+		{
+			name: "missing title",
+			edit: func(t *testing.T, store *memory.Store, manifest *v1.Manifest) {
+				manifest.Layers[0].Annotations = nil
+			},
+			want: []string{fmt.Sprintf("layer 0 (%s) has no title", appDigest)},
+		},
+		{
+			name: "duplicate title",
+			edit: func(t *testing.T, store *memory.Store, manifest *v1.Manifest) {
+				manifest.Layers = append(manifest.Layers, manifest.Layers[0])
+			},
+			want: []string{"title app.yaml is used by more than one layer"},
+		},
+		{
+			name: "unknown media type",
+			edit: func(t *testing.T, store *memory.Store, manifest *v1.Manifest) {
+				manifest.Layers[0].MediaType = "application/x-yaml"
+			},
+			want: []string{"layer app.yaml has unknown media type application/x-yaml"},
+		},
+		{
+			name: "checksum mismatch",
+			edit: func(t *testing.T, store *memory.Store, manifest *v1.Manifest) {
+				data := []byte(badSum + "  app.yaml\n")
+				desc := content.NewDescriptorFromBytes(oci.ChecksumsMediaType, data)
+				desc.Annotations = map[string]string{v1.AnnotationTitle: checksumsFile}
+				require.NoError(t, store.Push(context.Background(), desc, bytes.NewReader(data)))
+				manifest.Layers = append(manifest.Layers, desc)
+			},
+			want: []string{fmt.Sprintf("checksum verification failed:\napp.yaml has checksum %s, expected %s", appSum, badSum)},
+		},
+		{
+			name: "config lists a non-layer",
+			edit: func(t *testing.T, store *memory.Store, manifest *v1.Manifest) {
+				desc := content.NewDescriptorFromBytes(oci.LayerMediaType, other)
+				desc.Annotations = map[string]string{v1.AnnotationTitle: "other.yaml"}
+				require.NoError(t, store.Push(context.Background(), desc, bytes.NewReader(other)))
+				manifest.Layers = []v1.Descriptor{desc}
+			},
+			want: []string{"config lists app in app.yaml, which is not a layer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, desc := packTestStack(t, "app.yaml", app)
+			manifest := testManifest(t, store, desc)
+			tt.edit(t, store, manifest)
+
+			problems, err := verifyLayout(context.Background(), store, manifest)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, problems)
+		})
+	}
+}
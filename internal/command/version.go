@@ -2,11 +2,16 @@ package command
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+	"github.com/bschaatsbergen/kroctl/version"
 )
 
 // VersionOptions holds the options for the version command.
 type VersionOptions struct {
-	Path string
+	Path  string
+	Short bool
 }
 
 func newVersionCommand(cli *CLI) *cobra.Command {
@@ -18,18 +23,44 @@ func newVersionCommand(cli *CLI) *cobra.Command {
 		Use:   "version",
 		Short: "Show version information",
 		Long: highlight("kroctl version") + "\n\n" +
-			"Display the current version of kroctl.\n\n" +
+			"Display the current version of kroctl, together with the git\n" +
+			"commit and date it was built from, and the Go and oras-go\n" +
+			"versions it was built with.\n\n" +
 			"This information is useful for bug reports, ensuring team\n" +
 			"consistency, and verifying compatibility with documentation\n" +
 			"and automation scripts.\n",
 		Args: MaxArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				opts.Path = args[0]
 			}
 
-			cli.PrintVersion()
+			return RunVersion(cli, &opts)
 		},
 	}
+
+	cmd.Flags().BoolVar(&opts.Short, "short", false, "Print only the version number")
+
 	return cmd
 }
+
+func RunVersion(cli *CLI, opts *VersionOptions) error {
+	if opts.Short {
+		cli.Println(version.Version)
+		return nil
+	}
+
+	info := version.Get()
+	return render(cli, &api.VersionInfo{
+		TypeMeta:    api.NewTypeMeta(api.KindVersionInfo),
+		Version:     info.Version,
+		Commit:      info.Commit,
+		BuildDate:   info.BuildDate,
+		GoVersion:   info.GoVersion,
+		OrasVersion: info.OrasVersion,
+		Platform:    info.Platform,
+	}, func(s *view.Stream, _ *api.VersionInfo) error {
+		s.PrintVersion()
+		return nil
+	})
+}
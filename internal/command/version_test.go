@@ -2,9 +2,13 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
+	"runtime"
 	"testing"
 
+	"github.com/bschaatsbergen/kroctl/api"
 	"github.com/bschaatsbergen/kroctl/internal/view"
+	"github.com/bschaatsbergen/kroctl/version"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,3 +52,29 @@ func TestVersionCommand_TooManyArgs(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "expected at most 1")
 }
+
+func TestVersionCommand_Short(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cli := NewCLI(view.ViewHuman, buf, view.LogLevelSilent)
+	cmd := newVersionCommand(cli)
+	cmd.SetArgs([]string{"--short"})
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, version.Version+"\n", buf.String())
+}
+
+func TestVersionCommand_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cli := NewCLI(view.ViewJSON, buf, view.LogLevelSilent)
+	cmd := newVersionCommand(cli)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+
+	var info api.VersionInfo
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &info))
+	assert.Equal(t, api.KindVersionInfo, info.Kind)
+	assert.Equal(t, version.Version, info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bschaatsbergen/kroctl/api"
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type WhoamiOptions struct {
+	Registry string
+}
+
+func NewWhoamiCommand(cli *CLI) *cobra.Command {
+	opts := WhoamiOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "whoami <registry>",
+		Short: "Show the identity used for a registry",
+		Long: "Show the identity used for a registry.\n\n" +
+			"Reports where kroctl looks up credentials for the registry, the\n" +
+			"identity they belong to, and whether the registry accepts them.\n" +
+			"Credentials are read from the Docker config file and the\n" +
+			"credential helpers it configures, as docker login stores them.\n" +
+			"These are the only credentials kroctl uses: environment variables\n" +
+			"and cloud provider credentials are not consulted, by whoami or\n" +
+			"any other command. Use a credential helper such as\n" +
+			"docker-credential-ecr-login for cloud registries.\n\n" +
+			"Examples:\n" +
+			"  kroctl whoami ghcr.io\n\n" +
+			"  kroctl whoami localhost:5001\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Registry = args[0]
+			return RunWhoami(cmd.Context(), cli, &opts)
+		},
+	}
+
+	return cmd
+}
+
+func RunWhoami(ctx context.Context, cli *CLI, opts *WhoamiOptions) error {
+	cred, source, err := oci.LookupCredential(ctx, opts.Registry)
+	if err != nil {
+		// Still show where the lookup went, a missing helper is a common cause
+		if source.ConfigPath != "" && !cli.IsStructured() {
+			cli.Printf("Registry:     %s\n", opts.Registry)
+			cli.Printf("Credentials:  %s\n", source)
+		}
+		return err
+	}
+
+	identity := "anonymous"
+	switch {
+	case cred.Username != "" && cred.Password != "":
+		identity = cred.Username
+	case cred.RefreshToken != "":
+		// Identity tokens, e.g. from cloud provider helpers, carry no username
+		identity = "identity token"
+		if cred.Username != "" && cred.Username != "<token>" {
+			identity = fmt.Sprintf("%s (identity token)", cred.Username)
+		}
+	case cred.AccessToken != "":
+		identity = "access token"
+	}
+
+	result := &api.WhoamiResult{
+		TypeMeta:    api.NewTypeMeta(api.KindWhoamiResult),
+		Registry:    opts.Registry,
+		Credentials: source.String(),
+		Identity:    identity,
+		Access:      "ok",
+	}
+
+	reg, err := oci.SetupRegistry(opts.Registry)
+	if err != nil {
+		return err
+	}
+	pingErr := reg.Ping(ctx)
+	if pingErr != nil {
+		result.Access = "denied"
+	}
+
+	err = render(cli, result, func(s *view.Stream, result *api.WhoamiResult) error {
+		s.Printf("Registry:     %s\n", result.Registry)
+		s.Printf("Credentials:  %s\n", result.Credentials)
+		s.Printf("Identity:     %s\n", result.Identity)
+		s.Printf("Access:       %s\n", result.Access)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if pingErr != nil {
+		return fmt.Errorf("failed to authenticate with %s: %w", opts.Registry, pingErr)
+	}
+	return nil
+}
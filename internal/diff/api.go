@@ -0,0 +1,313 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+// Subjects of API changes.
+const (
+	SubjectRGD         = "ResourceGraphDefinition"
+	SubjectAPI         = "API"
+	SubjectSpecField   = "spec field"
+	SubjectStatusField = "status field"
+	SubjectResource    = "resource"
+)
+
+// APIChange is a change to a ResourceGraphDefinition as its consumers see
+// it: the instance API it defines and the resources it creates.
+type APIChange struct {
+	// RGD is the name of the ResourceGraphDefinition
+	RGD     string
+	Kind    ChangeKind
+	Subject string
+	// Path is the field path or resource ID, empty for the RGD itself
+	Path   string
+	Detail string
+	// Breaking is set when existing instances or their consumers may stop
+	// working
+	Breaking bool
+}
+
+// Message describes the change, formatting the path with quote.
+func (c APIChange) Message(quote func(string) string) string {
+	msg := string(c.Kind) + " " + c.Subject
+	if c.Path != "" {
+		msg += " " + quote(c.Path)
+	}
+	if c.Detail != "" {
+		msg += ": " + c.Detail
+	}
+	return msg
+}
+
+// String formats the change as a single line, prefixed with +, - or ~.
+func (c APIChange) String() string {
+	prefix := map[ChangeKind]string{Added: "+", Removed: "-", Modified: "~"}[c.Kind]
+	s := fmt.Sprintf("%s %s: %s", prefix, c.RGD, c.Message(func(s string) string { return s }))
+	if c.Breaking {
+		s += " (breaking)"
+	}
+	return s
+}
+
+// APIs compares the ResourceGraphDefinitions of two stacks by name. Removed
+// RGDs, removed fields, type changes, and new required fields without a
+// default are breaking. Renamed fields show up as a removal and an addition.
+func APIs(old, new []*rgd.ResourceGraphDefinition) []APIChange {
+	oldByName := map[string]*rgd.ResourceGraphDefinition{}
+	for _, r := range old {
+		oldByName[r.Metadata.Name] = r
+	}
+	newByName := map[string]*rgd.ResourceGraphDefinition{}
+	for _, r := range new {
+		newByName[r.Metadata.Name] = r
+	}
+
+	var changes []APIChange
+	for _, r := range old {
+		name := r.Metadata.Name
+		if other, ok := newByName[name]; ok {
+			changes = append(changes, compareRGD(name, r, other)...)
+			continue
+		}
+		changes = append(changes, APIChange{RGD: name, Kind: Removed, Subject: SubjectRGD, Breaking: true})
+	}
+	for _, r := range new {
+		if _, ok := oldByName[r.Metadata.Name]; !ok {
+			changes = append(changes, APIChange{RGD: r.Metadata.Name, Kind: Added, Subject: SubjectRGD})
+		}
+	}
+	return changes
+}
+
+// Schemas compares the instance APIs of two ResourceGraphDefinitions that
+// define the same kind under different names, such as copies in two
+// stacks. Changes are reported against the name of new; resources are not
+// compared.
+func Schemas(old, new *rgd.ResourceGraphDefinition) []APIChange {
+	name := new.Metadata.Name
+	oldSchema, newSchema := schemaOf(old), schemaOf(new)
+
+	var changes []APIChange
+	if a, b := apiName(oldSchema), apiName(newSchema); a != b {
+		changes = append(changes, APIChange{
+			RGD: name, Kind: Modified, Subject: SubjectAPI,
+			Detail: a + " -> " + b, Breaking: true,
+		})
+	}
+	changes = append(changes, compareSpecFields(name, oldSchema.SpecFields(), newSchema.SpecFields())...)
+	changes = append(changes, compareStatusFields(name, oldSchema.StatusFields(), newSchema.StatusFields())...)
+	return changes
+}
+
+func compareRGD(name string, old, new *rgd.ResourceGraphDefinition) []APIChange {
+	changes := Schemas(old, new)
+	return append(changes, compareResources(name, old.Spec.Resources, new.Spec.Resources)...)
+}
+
+func schemaOf(r *rgd.ResourceGraphDefinition) *rgd.Schema {
+	if r.Spec.Schema == nil {
+		return &rgd.Schema{}
+	}
+	return r.Spec.Schema
+}
+
+// apiName qualifies the kind of a schema with its group and version, such
+// as WebApp.kro.run/v1alpha1.
+func apiName(s *rgd.Schema) string {
+	group := s.Group
+	if group == "" {
+		group = rgd.Group
+	}
+	return s.Kind + "." + group + "/" + s.APIVersion
+}
+
+func compareSpecFields(name string, old, new []rgd.Field) []APIChange {
+	oldByPath := map[string]rgd.Field{}
+	for _, f := range old {
+		oldByPath[f.Path] = f
+	}
+	newByPath := map[string]rgd.Field{}
+	for _, f := range new {
+		newByPath[f.Path] = f
+	}
+
+	var changes []APIChange
+	for _, f := range old {
+		if _, ok := newByPath[f.Path]; !ok {
+			changes = append(changes, APIChange{
+				RGD: name, Kind: Removed, Subject: SubjectSpecField,
+				Path: "spec." + f.Path, Breaking: true,
+			})
+		}
+	}
+	for _, f := range new {
+		path := "spec." + f.Path
+		prev, ok := oldByPath[f.Path]
+		if !ok {
+			change := APIChange{RGD: name, Kind: Added, Subject: SubjectSpecField, Path: path, Detail: f.Type}
+			if mandatory(f) {
+				change.Detail += ", required"
+				change.Breaking = true
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		var details []string
+		var breaking bool
+		if prev.Type != f.Type {
+			details = append(details, "type "+prev.Type+" -> "+f.Type)
+			breaking = true
+		}
+		nowRequired := mandatory(f) && !mandatory(prev)
+		if nowRequired {
+			details = append(details, "now required")
+			breaking = true
+		}
+		for _, marker := range changedMarkers(prev.Markers, f.Markers) {
+			if marker == "required" && nowRequired {
+				continue
+			}
+			details = append(details, marker+" "+markerValue(prev.Markers, marker)+" -> "+markerValue(f.Markers, marker))
+		}
+		if len(details) > 0 {
+			changes = append(changes, APIChange{
+				RGD: name, Kind: Modified, Subject: SubjectSpecField, Path: path,
+				Detail: strings.Join(details, ", "), Breaking: breaking,
+			})
+		}
+	}
+	return changes
+}
+
+// mandatory reports whether instances must set the field.
+func mandatory(f rgd.Field) bool {
+	_, hasDefault := f.Default()
+	return f.Required() && !hasDefault
+}
+
+// changedMarkers returns the names of the markers that differ, sorted.
+func changedMarkers(old, new map[string]string) []string {
+	var names []string
+	for name, v := range old {
+		if w, ok := new[name]; !ok || v != w {
+			names = append(names, name)
+		}
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func markerValue(markers map[string]string, name string) string {
+	if v, ok := markers[name]; ok {
+		return v
+	}
+	return "unset"
+}
+
+func compareStatusFields(name string, old, new []rgd.StatusField) []APIChange {
+	oldByPath := map[string]rgd.StatusField{}
+	for _, f := range old {
+		oldByPath[f.Path] = f
+	}
+	newByPath := map[string]rgd.StatusField{}
+	for _, f := range new {
+		newByPath[f.Path] = f
+	}
+
+	var changes []APIChange
+	for _, f := range old {
+		if _, ok := newByPath[f.Path]; !ok {
+			// Consumers reading the field break just like writers of spec fields
+			changes = append(changes, APIChange{
+				RGD: name, Kind: Removed, Subject: SubjectStatusField,
+				Path: "status." + f.Path, Breaking: true,
+			})
+		}
+	}
+	for _, f := range new {
+		prev, ok := oldByPath[f.Path]
+		switch {
+		case !ok:
+			changes = append(changes, APIChange{RGD: name, Kind: Added, Subject: SubjectStatusField, Path: "status." + f.Path})
+		case prev.Expression != f.Expression:
+			changes = append(changes, APIChange{
+				RGD: name, Kind: Modified, Subject: SubjectStatusField, Path: "status." + f.Path,
+				Detail: prev.Expression + " -> " + f.Expression,
+			})
+		}
+	}
+	return changes
+}
+
+// maxResourcePaths bounds the changed paths listed for a resource.
+const maxResourcePaths = 3
+
+func compareResources(name string, old, new []*rgd.Resource) []APIChange {
+	oldByID := map[string]*rgd.Resource{}
+	for _, r := range old {
+		oldByID[r.ID] = r
+	}
+	newByID := map[string]*rgd.Resource{}
+	for _, r := range new {
+		newByID[r.ID] = r
+	}
+
+	var changes []APIChange
+	for _, r := range old {
+		if _, ok := newByID[r.ID]; !ok {
+			changes = append(changes, APIChange{RGD: name, Kind: Removed, Subject: SubjectResource, Path: r.ID})
+		}
+	}
+	for _, r := range new {
+		prev, ok := oldByID[r.ID]
+		if !ok {
+			changes = append(changes, APIChange{RGD: name, Kind: Added, Subject: SubjectResource, Path: r.ID})
+			continue
+		}
+
+		var fieldChanges []Change
+		compare("", resourceValue(prev), resourceValue(r), &fieldChanges)
+		if len(fieldChanges) == 0 {
+			continue
+		}
+		paths := make([]string, 0, maxResourcePaths)
+		for _, c := range fieldChanges[:min(len(fieldChanges), maxResourcePaths)] {
+			paths = append(paths, c.Path)
+		}
+		if len(fieldChanges) > maxResourcePaths {
+			paths = append(paths, fmt.Sprintf("and %d more", len(fieldChanges)-maxResourcePaths))
+		}
+		changes = append(changes, APIChange{
+			RGD: name, Kind: Modified, Subject: SubjectResource, Path: r.ID,
+			Detail: strings.Join(paths, ", "),
+		})
+	}
+	return changes
+}
+
+// resourceValue decodes a resource into plain values, so it compares
+// regardless of key order.
+func resourceValue(r *rgd.Resource) any {
+	out, err := yaml.Marshal(r)
+	if err != nil {
+		return nil
+	}
+	var v any
+	if err := yaml.Unmarshal(out, &v); err != nil {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,150 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/diff"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+const oldStack = `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: app
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: App
+    spec:
+      name: string
+      size: string | default=small
+      replicas: string
+    status:
+      url: ${service.status.loadBalancer.ingress[0].hostname}
+  resources:
+    - id: deployment
+      template:
+        apiVersion: apps/v1
+        kind: Deployment
+        spec:
+          replicas: 1
+    - id: service
+      template:
+        apiVersion: v1
+        kind: Service
+    - id: settings
+      externalRef:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: settings
+---
+apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: legacy
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: Legacy
+`
+
+const newStack = `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: app
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: App
+    spec:
+      name: string | required=true
+      replicas: integer
+      region: string | required=true
+      zone: string | required=true default=a
+      size: string | default=medium
+  resources:
+    - id: service
+      template:
+        kind: Service
+        apiVersion: v1
+    - id: deployment
+      template:
+        apiVersion: apps/v1
+        kind: Deployment
+        spec:
+          replicas: 2
+    - id: settings
+      externalRef:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: settings
+---
+apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: network
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: Network
+`
+
+func TestAPIs(t *testing.T) {
+	old, err := rgd.Parse("old.yaml", []byte(oldStack))
+	require.NoError(t, err)
+	new, err := rgd.Parse("new.yaml", []byte(newStack))
+	require.NoError(t, err)
+
+	var lines []string
+	for _, c := range diff.APIs(old, new) {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		"~ app: modified spec field spec.name: now required (breaking)",
+		"~ app: modified spec field spec.replicas: type string -> integer (breaking)",
+		"+ app: added spec field spec.region: string, required (breaking)",
+		"+ app: added spec field spec.zone: string",
+		"~ app: modified spec field spec.size: default small -> medium",
+		"- app: removed status field status.url (breaking)",
+		"~ app: modified resource deployment: template.spec.replicas",
+		"- legacy: removed ResourceGraphDefinition (breaking)",
+		"+ network: added ResourceGraphDefinition",
+	}, lines)
+}
+
+func TestAPIs_KindChange(t *testing.T) {
+	old, err := rgd.Parse("old.yaml", []byte(oldStack))
+	require.NoError(t, err)
+	renamed := *old[1]
+	schema := *renamed.Spec.Schema
+	schema.Group = "acme.io"
+	renamed.Spec.Schema = &schema
+
+	changes := diff.APIs(old[1:], []*rgd.ResourceGraphDefinition{&renamed})
+	require.Len(t, changes, 1)
+	assert.Equal(t, "~ legacy: modified API: Legacy.kro.run/v1alpha1 -> Legacy.acme.io/v1alpha1 (breaking)", changes[0].String())
+	assert.Equal(t, "modified API", changes[0].Message(func(s string) string { return "`" + s + "`" })[:12])
+}
+
+func TestSchemas(t *testing.T) {
+	old, err := rgd.Parse("old.yaml", []byte(oldStack))
+	require.NoError(t, err)
+	new, err := rgd.Parse("new.yaml", []byte(newStack))
+	require.NoError(t, err)
+
+	copied := *new[0]
+	copied.Metadata.Name = "app-copy"
+	changes := diff.Schemas(old[0], &copied)
+	require.NotEmpty(t, changes)
+	for _, c := range changes {
+		assert.Equal(t, "app-copy", c.RGD)
+		assert.NotEqual(t, diff.SubjectResource, c.Subject)
+	}
+
+	assert.Empty(t, diff.Schemas(old[0], old[0]))
+}
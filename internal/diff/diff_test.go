@@ -0,0 +1,112 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/diff"
+)
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\n"
+	b := "one\n2\nthree\nfour\n"
+
+	expected := "--- a\n+++ b\n" +
+		"@@ -1,3 +1,4 @@\n" +
+		" one\n" +
+		"-two\n" +
+		"+2\n" +
+		" three\n" +
+		"+four\n"
+	assert.Equal(t, expected, diff.Unified("a", "b", a, b))
+}
+
+func TestUnified_Equal(t *testing.T) {
+	assert.Empty(t, diff.Unified("a", "b", "same\n", "same\n"))
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n"
+
+	expected := "--- a\n+++ b\n" +
+		"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+		"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n"
+	assert.Equal(t, expected, diff.Unified("a", "b", a, b))
+}
+
+func TestUnified_NewFile(t *testing.T) {
+	expected := "--- /dev/null\n+++ b\n@@ -0,0 +1,1 @@\n+new\n"
+	assert.Equal(t, expected, diff.Unified("/dev/null", "b", "", "new\n"))
+}
+
+func TestSemantic_IgnoresOrderAndComments(t *testing.T) {
+	a := []byte("kind: ResourceGraphDefinition\nmetadata:\n  name: network\n")
+	b := []byte("# the network stack\nmetadata: {name: network}\nkind: ResourceGraphDefinition\n")
+
+	changes, err := diff.Semantic(a, b)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestSemantic_FieldChanges(t *testing.T) {
+	a := []byte(`spec:
+  schema:
+    kind: Network
+  resources:
+    - id: vpc
+      template:
+        kind: VPC
+        spec:
+          cidr: 10.0.0.0/16
+    - id: subnet
+      template:
+        kind: Subnet
+`)
+	b := []byte(`spec:
+  schema:
+    kind: Network
+    group: acme.io
+  resources:
+    - id: subnet
+      template:
+        kind: Subnet
+    - id: vpc
+      template:
+        kind: VPC
+        spec:
+          cidr: 10.1.0.0/16
+    - id: gateway
+      template:
+        kind: InternetGateway
+`)
+
+	changes, err := diff.Semantic(a, b)
+	require.NoError(t, err)
+
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		"~ spec.resources[vpc].template.spec.cidr: 10.0.0.0/16 -> 10.1.0.0/16",
+		"+ spec.resources[gateway]: {id: gateway, template: {kind: InternetGateway}}",
+		"+ spec.schema.group: acme.io",
+	}, lines)
+}
+
+func TestSemantic_ListsByIndex(t *testing.T) {
+	changes, err := diff.Semantic([]byte("items: [a, b]\n"), []byte("items: [a, c, d]\n"))
+	require.NoError(t, err)
+
+	require.Len(t, changes, 2)
+	assert.Equal(t, diff.Change{Kind: diff.Modified, Path: "items[1]", Old: "b", New: "c"}, changes[0])
+	assert.Equal(t, diff.Change{Kind: diff.Added, Path: "items[2]", New: "d"}, changes[1])
+}
+
+func TestSemantic_InvalidYAML(t *testing.T) {
+	_, err := diff.Semantic([]byte("a: [\n"), []byte("a: 1\n"))
+	assert.Error(t, err)
+}
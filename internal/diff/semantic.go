@@ -0,0 +1,237 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeKind tells how a field differs between two documents.
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Change is a single field-level difference between two documents.
+type Change struct {
+	Kind ChangeKind
+	// Path locates the field, e.g. spec.resources[vpc].template.kind
+	Path string
+	Old  any
+	New  any
+}
+
+// String formats the change as a single line, prefixed with +, - or ~.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatValue(c.Old), formatValue(c.New))
+	}
+}
+
+// identityKeys are the keys used to match list items between documents, so
+// reordering a list of resources is not reported as a change to every item.
+var identityKeys = []string{"id", "name"}
+
+// Semantic compares two YAML texts structurally. Key order, comments, and
+// formatting are ignored; list items that carry an id or name are matched
+// by it rather than by position.
+func Semantic(a, b []byte) ([]Change, error) {
+	docsA, err := decodeAll(a)
+	if err != nil {
+		return nil, err
+	}
+	docsB, err := decodeAll(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	if len(docsA) == 1 && len(docsB) == 1 {
+		compare("", docsA[0], docsB[0], &changes)
+	} else {
+		compare("", docsA, docsB, &changes)
+	}
+	return changes, nil
+}
+
+func decodeAll(data []byte) ([]any, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var docs []any
+	for {
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+func compare(path string, a, b any, changes *[]Change) {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			compareMaps(path, av, bv, changes)
+			return
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			compareLists(path, av, bv, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Kind: Modified, Path: path, Old: a, New: b})
+	}
+}
+
+func compareMaps(path string, a, b map[string]any, changes *[]Change) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		av, inA := a[k]
+		bv, inB := b[k]
+		p := joinKey(path, k)
+		switch {
+		case !inA:
+			*changes = append(*changes, Change{Kind: Added, Path: p, New: bv})
+		case !inB:
+			*changes = append(*changes, Change{Kind: Removed, Path: p, Old: av})
+		default:
+			compare(p, av, bv, changes)
+		}
+	}
+}
+
+func compareLists(path string, a, b []any, changes *[]Change) {
+	key := identityKey(a, b)
+	if key == "" {
+		for i := 0; i < max(len(a), len(b)); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(a):
+				*changes = append(*changes, Change{Kind: Added, Path: p, New: b[i]})
+			case i >= len(b):
+				*changes = append(*changes, Change{Kind: Removed, Path: p, Old: a[i]})
+			default:
+				compare(p, a[i], b[i], changes)
+			}
+		}
+		return
+	}
+
+	indexB := map[string]any{}
+	for _, item := range b {
+		indexB[fmt.Sprint(item.(map[string]any)[key])] = item
+	}
+
+	seen := map[string]bool{}
+	for _, item := range a {
+		id := fmt.Sprint(item.(map[string]any)[key])
+		seen[id] = true
+		p := fmt.Sprintf("%s[%s]", path, id)
+		if other, ok := indexB[id]; ok {
+			compare(p, item, other, changes)
+		} else {
+			*changes = append(*changes, Change{Kind: Removed, Path: p, Old: item})
+		}
+	}
+	for _, item := range b {
+		id := fmt.Sprint(item.(map[string]any)[key])
+		if !seen[id] {
+			*changes = append(*changes, Change{Kind: Added, Path: fmt.Sprintf("%s[%s]", path, id), New: item})
+		}
+	}
+}
+
+// identityKey returns the key that uniquely identifies every item of both
+// lists, or an empty string when items must be matched by position.
+func identityKey(a, b []any) string {
+	for _, key := range identityKeys {
+		if uniqueBy(key, a) && uniqueBy(key, b) {
+			return key
+		}
+	}
+	return ""
+}
+
+func uniqueBy(key string, items []any) bool {
+	seen := map[string]bool{}
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return false
+		}
+		v, ok := m[key]
+		if !ok {
+			return false
+		}
+		id := fmt.Sprint(v)
+		if seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// formatValue renders a value on a single line using YAML flow style.
+func formatValue(v any) string {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := strings.TrimSpace(string(out))
+	if strings.Contains(s, "\n") {
+		var n yaml.Node
+		if err := n.Encode(v); err == nil {
+			setFlowStyle(&n)
+			if flow, err := yaml.Marshal(&n); err == nil {
+				return strings.TrimSpace(string(flow))
+			}
+		}
+	}
+	return s
+}
+
+func setFlowStyle(n *yaml.Node) {
+	n.Style |= yaml.FlowStyle
+	for _, c := range n.Content {
+		setFlowStyle(c)
+	}
+}
@@ -0,0 +1,154 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// opKind is the kind of a line in an edit script.
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// Unified returns a unified diff between two texts, labelled with the given
+// names, or an empty string when the texts are equal.
+func Unified(nameA, nameB, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := lineOps(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+
+	// Group the edit script into hunks with surrounding context
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := max(i-contextLines, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			// Close the hunk once a run of equal lines is too long to bridge
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				end = min(end+contextLines, len(ops))
+				break
+			}
+			end = run
+		}
+
+		writeHunk(&sb, ops, start, end)
+		i = end
+	}
+
+	return sb.String()
+}
+
+// writeHunk writes ops[start:end] as a single hunk with its header.
+func writeHunk(sb *strings.Builder, ops []op, start, end int) {
+	lineA, lineB := 1, 1
+	for _, o := range ops[:start] {
+		if o.kind != opInsert {
+			lineA++
+		}
+		if o.kind != opDelete {
+			lineB++
+		}
+	}
+
+	countA, countB := 0, 0
+	for _, o := range ops[start:end] {
+		if o.kind != opInsert {
+			countA++
+		}
+		if o.kind != opDelete {
+			countB++
+		}
+	}
+
+	// Empty ranges point at the line before the hunk, as in GNU diff
+	if countA == 0 {
+		lineA--
+	}
+	if countB == 0 {
+		lineB--
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+	for _, o := range ops[start:end] {
+		fmt.Fprintf(sb, "%c%s\n", o.kind, o.line)
+	}
+}
+
+// lineOps computes a minimal edit script between two lists of lines using
+// the longest common subsequence.
+func lineOps(a, b []string) []op {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
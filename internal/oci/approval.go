@@ -0,0 +1,88 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// ApprovalArtifactType identifies an approval attached to a stack
+	ApprovalArtifactType = "application/vnd.kro.rgd.approval.v1"
+	// AnnotationApprovedBy names who approved a stack
+	AnnotationApprovedBy = "run.kro.approval.by"
+	// AnnotationApprovalTicket references the change ticket of an approval
+	AnnotationApprovalTicket = "run.kro.approval.ticket"
+)
+
+// Approval records that someone approved a stack for installation.
+type Approval struct {
+	Digest  string
+	By      string
+	Ticket  string
+	Created string
+}
+
+// Approve attaches an approval to the manifest described by subject, as a
+// referrer artifact without layers.
+func Approve(ctx context.Context, pusher content.Pusher, subject v1.Descriptor, by, ticket string) (v1.Descriptor, error) {
+	annotations := map[string]string{
+		AnnotationApprovedBy: by,
+		v1.AnnotationCreated: time.Now().UTC().Format(time.RFC3339),
+	}
+	if ticket != "" {
+		annotations[AnnotationApprovalTicket] = ticket
+	}
+
+	desc, err := oras.PackManifest(ctx, pusher, oras.PackManifestVersion1_1, ApprovalArtifactType, oras.PackManifestOptions{
+		Subject:             &subject,
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to push approval: %w", err)
+	}
+	return desc, nil
+}
+
+// Approvals returns the approvals attached to the manifest described by
+// subject.
+func Approvals(ctx context.Context, store content.ReadOnlyGraphStorage, subject v1.Descriptor) ([]Approval, error) {
+	referrers, err := registry.Referrers(ctx, store, subject, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approvals: %w", err)
+	}
+
+	var approvals []Approval
+	for _, r := range referrers {
+		if !isApproval(r) {
+			continue
+		}
+		approvals = append(approvals, Approval{
+			Digest:  r.Digest.String(),
+			By:      r.Annotations[AnnotationApprovedBy],
+			Ticket:  r.Annotations[AnnotationApprovalTicket],
+			Created: r.Annotations[v1.AnnotationCreated],
+		})
+	}
+	return approvals, nil
+}
+
+// isApproval reports whether a referrer is an approval. Approvals gate
+// installs, so other artifacts never count, but some registries report the
+// empty config media type, or nothing, as the artifact type of referrers.
+// Those are trusted on the annotation alone.
+func isApproval(r v1.Descriptor) bool {
+	if _, ok := r.Annotations[AnnotationApprovedBy]; !ok {
+		return false
+	}
+	switch r.ArtifactType {
+	case ApprovalArtifactType, "", v1.MediaTypeEmptyJSON:
+		return true
+	}
+	return false
+}
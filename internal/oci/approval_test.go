@@ -0,0 +1,89 @@
+package oci_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestApprovals(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	stack, err := oci.PackStack(ctx, store, &oci.StackConfig{Name: "stack"}, nil, nil, nil)
+	require.NoError(t, err)
+
+	approvals, err := oci.Approvals(ctx, store, stack)
+	require.NoError(t, err)
+	assert.Empty(t, approvals)
+
+	desc, err := oci.Approve(ctx, store, stack, "alice", "CHG-42")
+	require.NoError(t, err)
+
+	approvals, err = oci.Approvals(ctx, store, stack)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	assert.Equal(t, desc.Digest.String(), approvals[0].Digest)
+	assert.Equal(t, "alice", approvals[0].By)
+	assert.Equal(t, "CHG-42", approvals[0].Ticket)
+	assert.NotEmpty(t, approvals[0].Created)
+}
+
+func TestApprovals_OtherArtifactType(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	stack, err := oci.PackStack(ctx, store, &oci.StackConfig{Name: "stack"}, nil, nil, nil)
+	require.NoError(t, err)
+
+	// Anyone who can push a referrer can set the annotation
+	_, err = oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.acme.note", oras.PackManifestOptions{
+		Subject:             &stack,
+		ManifestAnnotations: map[string]string{oci.AnnotationApprovedBy: "mallory"},
+	})
+	require.NoError(t, err)
+	_, err = oci.Deprecate(ctx, store, stack, "use v2 stacks")
+	require.NoError(t, err)
+
+	approvals, err := oci.Approvals(ctx, store, stack)
+	require.NoError(t, err)
+	assert.Empty(t, approvals)
+}
+
+func TestApprovals_MisreportedArtifactType(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	stack, err := oci.PackStack(ctx, store, &oci.StackConfig{Name: "stack"}, nil, nil, nil)
+	require.NoError(t, err)
+
+	// Registries without artifact type support list the empty config
+	// media type instead, as for this manifest without an artifact type.
+	// The stack pushed the empty config already
+	manifest, err := json.Marshal(v1.Manifest{
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   v1.MediaTypeImageManifest,
+		Config:      v1.DescriptorEmptyJSON,
+		Layers:      []v1.Descriptor{},
+		Subject:     &stack,
+		Annotations: map[string]string{oci.AnnotationApprovedBy: "alice"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Push(ctx, content.NewDescriptorFromBytes(v1.MediaTypeImageManifest, manifest), bytes.NewReader(manifest)))
+
+	approvals, err := oci.Approvals(ctx, store, stack)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	assert.Equal(t, "alice", approvals[0].By)
+}
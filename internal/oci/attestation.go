@@ -0,0 +1,143 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// AttestationArtifactType identifies an in-toto attestation attached to
+	// a stack, and is the media type of its statement layer
+	AttestationArtifactType = "application/vnd.in-toto+json"
+	// StatementType is the in-toto statement version kroctl writes
+	StatementType = "https://in-toto.io/Statement/v1"
+	// AnnotationPredicateType lets attestations be listed without fetching
+	// their statements
+	AnnotationPredicateType = "run.kro.attestation.predicateType"
+)
+
+// Statement is an in-toto statement about a stack.
+type Statement struct {
+	Type          string             `json:"_type"`
+	Subject       []StatementSubject `json:"subject"`
+	PredicateType string             `json:"predicateType"`
+	Predicate     json.RawMessage    `json:"predicate"`
+}
+
+// StatementSubject is the artifact a statement is about.
+type StatementSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Attestation is an in-toto attestation attached to a stack.
+type Attestation struct {
+	Digest        string
+	PredicateType string
+	Created       string
+	// Manifest describes the referrer, to fetch the statement with
+	Manifest v1.Descriptor
+}
+
+// Attest attaches an unsigned in-toto statement with the given predicate to
+// the manifest described by subject. The predicate must be a JSON document;
+// name identifies the subject in the statement, usually its repository.
+func Attest(ctx context.Context, pusher content.Pusher, name string, subject v1.Descriptor, predicateType string, predicate []byte) (v1.Descriptor, error) {
+	if predicateType == "" {
+		return v1.Descriptor{}, fmt.Errorf("an attestation needs a predicate type")
+	}
+	if !json.Valid(predicate) {
+		return v1.Descriptor{}, fmt.Errorf("the predicate is not valid JSON")
+	}
+
+	statement, err := json.Marshal(Statement{
+		Type: StatementType,
+		Subject: []StatementSubject{{
+			Name:   name,
+			Digest: map[string]string{subject.Digest.Algorithm().String(): subject.Digest.Encoded()},
+		}},
+		PredicateType: predicateType,
+		Predicate:     predicate,
+	})
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to encode statement: %w", err)
+	}
+
+	layer := content.NewDescriptorFromBytes(AttestationArtifactType, statement)
+	if err := pusher.Push(ctx, layer, bytes.NewReader(statement)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return v1.Descriptor{}, fmt.Errorf("failed to push statement: %w", err)
+	}
+
+	desc, err := oras.PackManifest(ctx, pusher, oras.PackManifestVersion1_1, AttestationArtifactType, oras.PackManifestOptions{
+		Subject: &subject,
+		Layers:  []v1.Descriptor{layer},
+		ManifestAnnotations: map[string]string{
+			AnnotationPredicateType: predicateType,
+			v1.AnnotationCreated:    time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to push attestation: %w", err)
+	}
+	return desc, nil
+}
+
+// Attestations returns the attestations attached to the manifest described
+// by subject, oldest first as the registry lists them.
+func Attestations(ctx context.Context, store content.ReadOnlyGraphStorage, subject v1.Descriptor) ([]Attestation, error) {
+	// Filter on the annotation for the same reason as Approvals
+	referrers, err := registry.Referrers(ctx, store, subject, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attestations: %w", err)
+	}
+
+	var attestations []Attestation
+	for _, r := range referrers {
+		predicateType, ok := r.Annotations[AnnotationPredicateType]
+		if !ok {
+			continue
+		}
+		attestations = append(attestations, Attestation{
+			Digest:        r.Digest.String(),
+			PredicateType: predicateType,
+			Created:       r.Annotations[v1.AnnotationCreated],
+			Manifest:      r,
+		})
+	}
+	return attestations, nil
+}
+
+// FetchStatement downloads the in-toto statement of an attestation.
+func FetchStatement(ctx context.Context, fetcher content.Fetcher, attestation Attestation) (*Statement, error) {
+	data, err := content.FetchAll(ctx, fetcher, attestation.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attestation: %w", err)
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse attestation: %w", err)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != AttestationArtifactType {
+		return nil, fmt.Errorf("attestation %s has no statement layer", attestation.Digest)
+	}
+
+	data, err = content.FetchAll(ctx, fetcher, manifest.Layers[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch statement: %w", err)
+	}
+	var statement Statement
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return nil, fmt.Errorf("failed to parse statement: %w", err)
+	}
+	return &statement, nil
+}
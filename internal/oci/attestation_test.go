@@ -0,0 +1,45 @@
+package oci_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestAttestations(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	stack, err := oci.PackStack(ctx, store, &oci.StackConfig{Name: "stack"}, nil, nil, nil)
+	require.NoError(t, err)
+
+	_, err = oci.Attest(ctx, store, "ghcr.io/acme/stack", stack, "https://example.com/scan/v1", []byte("not json"))
+	assert.Error(t, err)
+
+	// Other referrers, such as deprecations, must not count
+	_, err = oci.Deprecate(ctx, store, stack, "use v2 stacks")
+	require.NoError(t, err)
+	desc, err := oci.Attest(ctx, store, "ghcr.io/acme/stack", stack, "https://example.com/scan/v1", []byte(`{"critical": 0}`))
+	require.NoError(t, err)
+
+	attestations, err := oci.Attestations(ctx, store, stack)
+	require.NoError(t, err)
+	require.Len(t, attestations, 1)
+	assert.Equal(t, desc.Digest.String(), attestations[0].Digest)
+	assert.Equal(t, "https://example.com/scan/v1", attestations[0].PredicateType)
+	assert.NotEmpty(t, attestations[0].Created)
+
+	statement, err := oci.FetchStatement(ctx, store, attestations[0])
+	require.NoError(t, err)
+	assert.Equal(t, oci.StatementType, statement.Type)
+	assert.Equal(t, "https://example.com/scan/v1", statement.PredicateType)
+	assert.JSONEq(t, `{"critical": 0}`, string(statement.Predicate))
+	require.Len(t, statement.Subject, 1)
+	assert.Equal(t, "ghcr.io/acme/stack", statement.Subject[0].Name)
+	assert.Equal(t, stack.Digest.Encoded(), statement.Subject[0].Digest["sha256"])
+}
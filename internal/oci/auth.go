@@ -0,0 +1,207 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// CredentialSource describes where the credentials for a registry are
+// looked up.
+type CredentialSource struct {
+	// ConfigPath is the Docker config file that configures the lookup.
+	ConfigPath string
+	// Helper is the credential helper the credentials are read from. It is
+	// empty when they are stored in the config file itself.
+	Helper string
+}
+
+// String describes the source for humans.
+func (s CredentialSource) String() string {
+	if s.Helper != "" {
+		return fmt.Sprintf("docker-credential-%s (configured in %s)", s.Helper, s.ConfigPath)
+	}
+	return s.ConfigPath
+}
+
+// dockerConfig is the part of the Docker config file that selects the
+// credential helper.
+type dockerConfig struct {
+	Auths       map[string]json.RawMessage `json:"auths"`
+	CredsStore  string                     `json:"credsStore"`
+	CredHelpers map[string]string          `json:"credHelpers"`
+}
+
+// DockerConfig describes the Docker config file kroctl reads credentials
+// from.
+type DockerConfig struct {
+	Path string
+	// Exists is false when there is no config file, and so no credentials
+	Exists bool
+	// Helpers are the credential helpers the config file uses
+	Helpers []string
+	// Registries are the registries the config file has credentials or a
+	// credential helper for
+	Registries []string
+}
+
+// ReadDockerConfig reads and validates the Docker config file.
+func ReadDockerConfig() (*DockerConfig, error) {
+	store, err := newCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &DockerConfig{Path: store.ConfigPath()}
+	cfg, err := readDockerConfig(info.Path)
+	if err != nil || cfg == nil {
+		return info, err
+	}
+	info.Exists = true
+
+	if cfg.CredsStore != "" {
+		info.Helpers = append(info.Helpers, cfg.CredsStore)
+	}
+	for _, helper := range cfg.CredHelpers {
+		if !slices.Contains(info.Helpers, helper) {
+			info.Helpers = append(info.Helpers, helper)
+		}
+	}
+	for host := range cfg.Auths {
+		info.Registries = append(info.Registries, host)
+	}
+	for host := range cfg.CredHelpers {
+		if !slices.Contains(info.Registries, host) {
+			info.Registries = append(info.Registries, host)
+		}
+	}
+	slices.Sort(info.Helpers)
+	slices.Sort(info.Registries)
+	return info, nil
+}
+
+// readDockerConfig parses the Docker config file at path. It returns nil
+// when the file does not exist.
+func readDockerConfig(path string) (*dockerConfig, error) {
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg dockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// registryConfig is the Docker config file credentials are read from
+// instead of the default one, empty to use the default.
+var registryConfig string
+
+// SetRegistryConfig reads credentials from the Docker config file at path
+// instead of ~/.docker/config.json. A directory is taken to hold a
+// config.json, as with docker --config. Empty restores the default.
+func SetRegistryConfig(path string) error {
+	if path == "" {
+		registryConfig = ""
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to access registry config: %w", err)
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, "config.json")
+	}
+	registryConfig = path
+	return nil
+}
+
+func newCredentialStore() (*credentials.DynamicStore, error) {
+	var store *credentials.DynamicStore
+	var err error
+	if registryConfig != "" {
+		store, err = credentials.NewStore(registryConfig, credentials.StoreOptions{})
+	} else {
+		store, err = credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential store: %w", err)
+	}
+	return store, nil
+}
+
+// newAuthClient returns a client that authenticates with the credentials
+// from the Docker credential store, or the config set with
+// SetRegistryConfig.
+func newAuthClient() (*auth.Client, error) {
+	// TODO: uses Docker credentials for now.. support more methods later
+	credStore, err := newCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Client{
+		Client:     newRetryClient(),
+		Credential: credentials.Credential(credStore),
+	}, nil
+}
+
+// SetupRegistry creates a client for the registry at host, configured like
+// the repositories returned by SetupRepository.
+func SetupRegistry(host string) (*remote.Registry, error) {
+	reg, err := remote.NewRegistry(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry %s: %w", host, err)
+	}
+	reg.PlainHTTP = IsLocalRegistry(reg.Reference.Host())
+
+	client, err := newAuthClient()
+	if err != nil {
+		return nil, err
+	}
+	reg.Client = client
+
+	return reg, nil
+}
+
+// LookupCredential returns the credential kroctl uses for the registry at
+// host, and where it was looked up. The credential is empty when none is
+// configured.
+func LookupCredential(ctx context.Context, host string) (auth.Credential, CredentialSource, error) {
+	store, err := newCredentialStore()
+	if err != nil {
+		return auth.EmptyCredential, CredentialSource{}, err
+	}
+
+	source := CredentialSource{ConfigPath: store.ConfigPath()}
+	cfg, err := readDockerConfig(source.ConfigPath)
+	if err != nil {
+		return auth.EmptyCredential, source, err
+	}
+	// Without a config file there are no credentials
+	if cfg != nil {
+		source.Helper = cfg.CredsStore
+		if helper, ok := cfg.CredHelpers[host]; ok {
+			source.Helper = helper
+		}
+	}
+
+	cred, err := store.Get(ctx, credentials.ServerAddressFromRegistry(host))
+	if err != nil {
+		return auth.EmptyCredential, source, fmt.Errorf("failed to get credentials for %s: %w", host, err)
+	}
+	return cred, source, nil
+}
@@ -0,0 +1,87 @@
+package oci_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestReadDockerConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+
+	config, err := oci.ReadDockerConfig()
+	require.NoError(t, err)
+	assert.False(t, config.Exists)
+
+	data := `{
+  "auths": {"ghcr.io": {"auth": "dXNlcjpwYXNz"}},
+  "credsStore": "desktop",
+  "credHelpers": {"123456789012.dkr.ecr.us-east-1.amazonaws.com": "ecr-login"}
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0o600))
+
+	config, err = oci.ReadDockerConfig()
+	require.NoError(t, err)
+	assert.True(t, config.Exists)
+	assert.Equal(t, []string{"desktop", "ecr-login"}, config.Helpers)
+	assert.Equal(t, []string{"123456789012.dkr.ecr.us-east-1.amazonaws.com", "ghcr.io"}, config.Registries)
+}
+
+func TestReadDockerConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte("{"), 0o600))
+
+	_, err := oci.ReadDockerConfig()
+	assert.Error(t, err)
+}
+
+func TestSetRegistryConfig(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	t.Cleanup(func() { _ = oci.SetRegistryConfig("") })
+
+	dir := t.TempDir()
+	data := `{"auths": {"ghcr.io": {"auth": "dXNlcjpwYXNz"}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0o600))
+
+	for _, path := range []string{dir, filepath.Join(dir, "config.json")} {
+		require.NoError(t, oci.SetRegistryConfig(path))
+		cred, source, err := oci.LookupCredential(context.Background(), "ghcr.io")
+		require.NoError(t, err)
+		assert.Equal(t, "user", cred.Username)
+		assert.Equal(t, filepath.Join(dir, "config.json"), source.ConfigPath)
+	}
+
+	assert.Error(t, oci.SetRegistryConfig(filepath.Join(dir, "missing.json")))
+}
+
+func TestLookupCredential(t *testing.T) {
+	dir := t.TempDir()
+	data := `{
+  "auths": {"ghcr.io": {"auth": "dXNlcjpwYXNz"}},
+  "credHelpers": {"123456789012.dkr.ecr.us-east-1.amazonaws.com": "ecr-login"}
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0o600))
+	require.NoError(t, oci.SetRegistryConfig(dir))
+	t.Cleanup(func() { _ = oci.SetRegistryConfig("") })
+
+	cred, source, err := oci.LookupCredential(context.Background(), "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "user", cred.Username)
+	assert.Equal(t, "pass", cred.Password)
+	assert.Equal(t, filepath.Join(dir, "config.json"), source.ConfigPath)
+	assert.Empty(t, source.Helper)
+
+	// Registries without credentials are accessed anonymously
+	cred, _, err = oci.LookupCredential(context.Background(), "quay.io")
+	require.NoError(t, err)
+	assert.Empty(t, cred.Username)
+	assert.Empty(t, cred.Password)
+}
@@ -0,0 +1,149 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+// StackConfig is the config blob of a stack. It describes the stack so
+// tools can show what it holds without downloading the layers.
+type StackConfig struct {
+	// Name is the name of the stack, the last element of its repository
+	Name string `json:"name"`
+	// Version is the tag the stack was pushed with
+	Version string `json:"version,omitempty"`
+	// RGDs index the ResourceGraphDefinitions of the stack
+	RGDs []StackRGD `json:"rgds"`
+	// Dependencies are the custom APIs the stack uses without defining
+	// them, such as VPC.ec2.services.k8s.aws
+	Dependencies  []string      `json:"dependencies,omitempty"`
+	Compatibility Compatibility `json:"compatibility"`
+}
+
+// StackRGD is a ResourceGraphDefinition in the index of a StackConfig.
+type StackRGD struct {
+	Name string `json:"name"`
+	// API is the kind the RGD defines, qualified by its group
+	API string `json:"api,omitempty"`
+	// File is the title of the layer holding the RGD
+	File   string `json:"file"`
+	Digest string `json:"digest"`
+}
+
+// Compatibility constrains the clusters a stack can be installed in.
+type Compatibility struct {
+	// KroAPIVersions are the kro API versions of the RGDs, such as
+	// kro.run/v1alpha1, which kro in the cluster must serve
+	KroAPIVersions []string `json:"kroAPIVersions"`
+	// KubernetesBefore is the first Kubernetes version that removes an API
+	// the stack uses, empty when it uses none
+	KubernetesBefore string `json:"kubernetesBefore,omitempty"`
+}
+
+// NewStackConfig describes the stack pushed to reference from its layers.
+// contents holds the content of each layer; layers that are not RGD files
+// are skipped.
+func NewStackConfig(reference string, layers []v1.Descriptor, contents [][]byte) (*StackConfig, error) {
+	config := &StackConfig{
+		RGDs:          []StackRGD{},
+		Compatibility: Compatibility{KroAPIVersions: []string{}},
+	}
+	if layout, ok, err := ParseLayoutReference(reference); ok && err == nil {
+		config.Name = filepath.Base(layout.Path)
+		config.Version = layout.Tag
+	} else if ref, err := registry.ParseReference(reference); err == nil {
+		config.Name = path.Base(ref.Repository)
+		if ref.ValidateReferenceAsTag() == nil {
+			config.Version = ref.Reference
+		}
+	}
+
+	var all []*rgd.ResourceGraphDefinition
+	for i, layer := range layers {
+		if layer.MediaType != LayerMediaType {
+			continue
+		}
+		title := LayerTitle(layer)
+		rgds, err := rgd.Parse(title, contents[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", title, err)
+		}
+		for _, r := range rgds {
+			entry := StackRGD{
+				Name:   r.Metadata.Name,
+				File:   title,
+				Digest: layer.Digest.String(),
+			}
+			if apis := rgd.Summarize([]*rgd.ResourceGraphDefinition{r}).APIs; len(apis) > 0 {
+				entry.API = apis[0]
+			}
+			config.RGDs = append(config.RGDs, entry)
+
+			if !slices.Contains(config.Compatibility.KroAPIVersions, r.APIVersion) {
+				config.Compatibility.KroAPIVersions = append(config.Compatibility.KroAPIVersions, r.APIVersion)
+			}
+		}
+		all = append(all, rgds...)
+	}
+	config.Dependencies = rgd.Dependencies(all)
+	config.Compatibility.KubernetesBefore = rgd.KubernetesRemoval(all)
+	slices.Sort(config.Compatibility.KroAPIVersions)
+
+	return config, nil
+}
+
+// PackStack pushes config and a stack manifest referencing it and layers to
+// pusher. The layers must already be pushed. A non-nil subject relates the
+// stack to the version it supersedes.
+func PackStack(ctx context.Context, pusher content.Pusher, config *StackConfig, layers []v1.Descriptor, annotations map[string]string, subject *v1.Descriptor) (v1.Descriptor, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to encode stack config: %w", err)
+	}
+	configDesc := content.NewDescriptorFromBytes(ConfigMediaType, data)
+	if err := pusher.Push(ctx, configDesc, bytes.NewReader(data)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return v1.Descriptor{}, fmt.Errorf("failed to push stack config: %w", err)
+	}
+
+	desc, err := oras.PackManifest(ctx, pusher, oras.PackManifestVersion1_1, ArtifactType, oras.PackManifestOptions{
+		ConfigDescriptor:    &configDesc,
+		Layers:              layers,
+		ManifestAnnotations: annotations,
+		Subject:             subject,
+	})
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to pack manifest: %w", err)
+	}
+	return desc, nil
+}
+
+// FetchConfig downloads the config of a stack. It returns nil for stacks
+// pushed before stacks carried a config.
+func FetchConfig(ctx context.Context, fetcher content.Fetcher, manifest *v1.Manifest) (*StackConfig, error) {
+	if manifest.Config.MediaType != ConfigMediaType {
+		return nil, nil
+	}
+	data, err := content.FetchAll(ctx, fetcher, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stack config: %w", err)
+	}
+	var config StackConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse stack config: %w", err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,98 @@
+package oci_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+const networkRGD = `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: network
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: Network
+  resources:
+    - id: vpc
+      template:
+        apiVersion: ec2.services.k8s.aws/v1alpha1
+        kind: VPC
+`
+
+func layer(mediaType, title string, data []byte) v1.Descriptor {
+	desc := content.NewDescriptorFromBytes(mediaType, data)
+	desc.Annotations = map[string]string{v1.AnnotationTitle: title}
+	return desc
+}
+
+func TestNewStackConfig(t *testing.T) {
+	rgdLayer := layer(oci.LayerMediaType, "network.yaml", []byte(networkRGD))
+	sumsLayer := layer(oci.ChecksumsMediaType, "SHA256SUMS", []byte("abc  network.yaml\n"))
+
+	config, err := oci.NewStackConfig("ghcr.io/acme/kro-stack:v1.2.0", []v1.Descriptor{rgdLayer, sumsLayer}, [][]byte{[]byte(networkRGD), nil})
+	require.NoError(t, err)
+
+	assert.Equal(t, &oci.StackConfig{
+		Name:    "kro-stack",
+		Version: "v1.2.0",
+		RGDs: []oci.StackRGD{
+			{Name: "network", API: "Network.kro.run", File: "network.yaml", Digest: rgdLayer.Digest.String()},
+		},
+		Dependencies: []string{"VPC.ec2.services.k8s.aws"},
+		Compatibility: oci.Compatibility{
+			KroAPIVersions: []string{"kro.run/v1alpha1"},
+		},
+	}, config)
+}
+
+func TestNewStackConfig_Digest(t *testing.T) {
+	config, err := oci.NewStackConfig("ghcr.io/acme/kro-stack@sha256:"+strings.Repeat("a", 64), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "kro-stack", config.Name)
+	assert.Empty(t, config.Version)
+}
+
+func TestNewStackConfig_InvalidRGD(t *testing.T) {
+	data := []byte("kind: ConfigMap\n")
+	_, err := oci.NewStackConfig("ghcr.io/acme/kro-stack:v1", []v1.Descriptor{layer(oci.LayerMediaType, "cm.yaml", data)}, [][]byte{data})
+	assert.ErrorContains(t, err, "failed to parse cm.yaml")
+}
+
+func TestPackStack(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	config := &oci.StackConfig{Name: "kro-stack", RGDs: []oci.StackRGD{}}
+	desc, err := oci.PackStack(ctx, store, config, nil, map[string]string{"team": "platform"}, nil)
+	require.NoError(t, err)
+
+	data, err := content.FetchAll(ctx, store, desc)
+	require.NoError(t, err)
+	var manifest v1.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	assert.Equal(t, oci.ArtifactType, manifest.ArtifactType)
+	assert.Equal(t, oci.ConfigMediaType, manifest.Config.MediaType)
+	assert.True(t, oci.IsStack(&manifest))
+	assert.Equal(t, "platform", manifest.Annotations["team"])
+
+	// Packing the same config again must not fail on the existing blob
+	_, err = oci.PackStack(ctx, store, config, nil, nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestIsStack_V1(t *testing.T) {
+	assert.True(t, oci.IsStack(&v1.Manifest{ArtifactType: oci.ArtifactTypeV1}))
+	assert.False(t, oci.IsStack(&v1.Manifest{ArtifactType: "application/vnd.cncf.flux.config.v1+json"}))
+}
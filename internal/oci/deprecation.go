@@ -0,0 +1,67 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// DeprecationArtifactType identifies a deprecation attached to a stack
+	DeprecationArtifactType = "application/vnd.kro.rgd.deprecation.v1"
+	// AnnotationDeprecationMessage tells consumers of a deprecated stack
+	// what to use instead
+	AnnotationDeprecationMessage = "run.kro.deprecation.message"
+)
+
+// Deprecation records that a stack should no longer be installed.
+type Deprecation struct {
+	Digest  string
+	Message string
+	Created string
+}
+
+// Deprecate attaches a deprecation to the manifest described by subject, as
+// a referrer artifact without layers.
+func Deprecate(ctx context.Context, pusher content.Pusher, subject v1.Descriptor, message string) (v1.Descriptor, error) {
+	desc, err := oras.PackManifest(ctx, pusher, oras.PackManifestVersion1_1, DeprecationArtifactType, oras.PackManifestOptions{
+		Subject: &subject,
+		ManifestAnnotations: map[string]string{
+			AnnotationDeprecationMessage: message,
+			v1.AnnotationCreated:         time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to push deprecation: %w", err)
+	}
+	return desc, nil
+}
+
+// Deprecations returns the deprecations attached to the manifest described
+// by subject, oldest first as the registry lists them.
+func Deprecations(ctx context.Context, store content.ReadOnlyGraphStorage, subject v1.Descriptor) ([]Deprecation, error) {
+	// Filter on the annotation for the same reason as Approvals
+	referrers, err := registry.Referrers(ctx, store, subject, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deprecations: %w", err)
+	}
+
+	var deprecations []Deprecation
+	for _, r := range referrers {
+		message, ok := r.Annotations[AnnotationDeprecationMessage]
+		if !ok {
+			continue
+		}
+		deprecations = append(deprecations, Deprecation{
+			Digest:  r.Digest.String(),
+			Message: message,
+			Created: r.Annotations[v1.AnnotationCreated],
+		})
+	}
+	return deprecations, nil
+}
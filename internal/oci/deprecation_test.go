@@ -0,0 +1,38 @@
+package oci_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestDeprecations(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	stack, err := oci.PackStack(ctx, store, &oci.StackConfig{Name: "stack"}, nil, nil, nil)
+	require.NoError(t, err)
+
+	deprecations, err := oci.Deprecations(ctx, store, stack)
+	require.NoError(t, err)
+	assert.Empty(t, deprecations)
+
+	// Other referrers, such as approvals, must not count
+	_, err = oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, oci.ApprovalArtifactType, oras.PackManifestOptions{Subject: &stack})
+	require.NoError(t, err)
+	desc, err := oci.Deprecate(ctx, store, stack, "use v2 stacks")
+	require.NoError(t, err)
+
+	deprecations, err = oci.Deprecations(ctx, store, stack)
+	require.NoError(t, err)
+	require.Len(t, deprecations, 1)
+	assert.Equal(t, desc.Digest.String(), deprecations[0].Digest)
+	assert.Equal(t, "use v2 stacks", deprecations[0].Message)
+	assert.NotEmpty(t, deprecations[0].Created)
+}
@@ -0,0 +1,115 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// RegistryError is a registry response that kroctl can explain, with a
+// hint on how to resolve it.
+type RegistryError struct {
+	Message string
+	Hint    string
+	Err     error
+}
+
+func (e *RegistryError) Error() string {
+	if e.Hint == "" {
+		return e.Message
+	}
+	return e.Message + "\nhint: " + e.Hint
+}
+
+func (e *RegistryError) Unwrap() error {
+	return e.Err
+}
+
+// ExplainError replaces authentication and permission errors returned by a
+// registry with a RegistryError that says what went wrong and how to fix
+// it. Other errors are returned unchanged.
+func ExplainError(err error) error {
+	var resp *errcode.ErrorResponse
+	if !errors.As(err, &resp) || resp.URL == nil {
+		return err
+	}
+
+	host := resp.URL.Host
+	repo := repositoryFromPath(resp.URL.Path)
+	target := host
+	if repo != "" {
+		target = host + "/" + repo
+	}
+	push := resp.Method != http.MethodGet && resp.Method != http.MethodHead
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return &RegistryError{
+			Message: fmt.Sprintf("not logged in to %s, or the registry rejected the credentials", host),
+			Hint: fmt.Sprintf("run `docker login %s`, kroctl uses the Docker credential store; "+
+				"`kroctl whoami %s` shows the credentials in use", host, host),
+			Err: err,
+		}
+	case http.StatusForbidden:
+		action := "pull from"
+		if push {
+			action = "push to"
+		}
+		return &RegistryError{
+			Message: fmt.Sprintf("the credentials for %s are not allowed to %s %s", host, action, target),
+			Hint:    permissionHint(host, push),
+			Err:     err,
+		}
+	case http.StatusTooManyRequests:
+		return &RegistryError{
+			Message: fmt.Sprintf("rate limited by %s, and retrying did not help", host),
+			Hint: "wait before trying again; logging in usually raises the limit, " +
+				"and KROCTL_LOG=debug shows the remaining budget the registry reports",
+			Err: err,
+		}
+	default:
+		return err
+	}
+}
+
+// permissionHint names the permission a registry requires, for the
+// registries where it is well known.
+func permissionHint(host string, push bool) string {
+	switch {
+	case host == "ghcr.io":
+		if push {
+			return "the token needs the write:packages scope, and access to the package if it belongs to an organization"
+		}
+		return "the token needs the read:packages scope"
+	case host == "docker.io" || strings.HasSuffix(host, ".docker.io"):
+		if push {
+			return "the access token needs Read & Write permissions"
+		}
+		return "the access token needs Read permissions, and the repository must exist"
+	case strings.HasSuffix(host, ".amazonaws.com"):
+		if push {
+			return "the IAM identity needs ecr:InitiateLayerUpload, ecr:UploadLayerPart, ecr:CompleteLayerUpload, and ecr:PutImage"
+		}
+		return "the IAM identity needs ecr:BatchGetImage and ecr:GetDownloadUrlForLayer"
+	default:
+		return "ask the registry administrator for access to the repository"
+	}
+}
+
+// repositoryFromPath extracts the repository from a registry API path such
+// as /v2/acme/stack/manifests/v1.
+func repositoryFromPath(path string) string {
+	path, ok := strings.CutPrefix(path, "/v2/")
+	if !ok {
+		return ""
+	}
+	for _, sep := range []string{"/manifests/", "/blobs/", "/tags/", "/referrers/"} {
+		if i := strings.Index(path, sep); i >= 0 {
+			return path[:i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package oci_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func registryResponse(method, rawURL string, status int) error {
+	u, _ := url.Parse(rawURL)
+	resp := &errcode.ErrorResponse{Method: method, URL: u, StatusCode: status}
+	return fmt.Errorf("failed to push artifact: %w", resp)
+}
+
+func TestExplainError_Unauthorized(t *testing.T) {
+	err := oci.ExplainError(registryResponse(http.MethodGet, "https://ghcr.io/v2/acme/stack/manifests/v1", http.StatusUnauthorized))
+
+	var regErr *oci.RegistryError
+	require.ErrorAs(t, err, &regErr)
+	assert.Equal(t, "not logged in to ghcr.io, or the registry rejected the credentials", regErr.Message)
+	assert.Contains(t, err.Error(), "hint: run `docker login ghcr.io`")
+}
+
+func TestExplainError_ForbiddenPush(t *testing.T) {
+	err := oci.ExplainError(registryResponse(http.MethodPut, "https://ghcr.io/v2/acme/stack/manifests/v1", http.StatusForbidden))
+
+	assert.EqualError(t, err, "the credentials for ghcr.io are not allowed to push to ghcr.io/acme/stack\n"+
+		"hint: the token needs the write:packages scope, and access to the package if it belongs to an organization")
+
+	var resp *errcode.ErrorResponse
+	assert.ErrorAs(t, err, &resp, "the registry response stays available")
+}
+
+func TestExplainError_ForbiddenPull(t *testing.T) {
+	err := oci.ExplainError(registryResponse(http.MethodHead, "https://registry.example.com/v2/team/app/blobs/sha256:abc", http.StatusForbidden))
+
+	assert.EqualError(t, err, "the credentials for registry.example.com are not allowed to pull from registry.example.com/team/app\n"+
+		"hint: ask the registry administrator for access to the repository")
+}
+
+func TestExplainError_TooManyRequests(t *testing.T) {
+	err := oci.ExplainError(registryResponse(http.MethodGet, "https://registry-1.docker.io/v2/acme/stack/manifests/v1", http.StatusTooManyRequests))
+
+	var regErr *oci.RegistryError
+	require.ErrorAs(t, err, &regErr)
+	assert.Equal(t, "rate limited by registry-1.docker.io, and retrying did not help", regErr.Message)
+}
+
+func TestExplainError_Unchanged(t *testing.T) {
+	notFound := registryResponse(http.MethodGet, "https://ghcr.io/v2/acme/stack/manifests/v1", http.StatusNotFound)
+	assert.Equal(t, notFound, oci.ExplainError(notFound))
+
+	other := errors.New("boom")
+	assert.Equal(t, other, oci.ExplainError(other))
+}
@@ -0,0 +1,138 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// mediaTypeDockerManifestList is the Docker equivalent of an OCI image index.
+const mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+// IsIndex reports whether a media type is an OCI image index or a Docker
+// manifest list.
+func IsIndex(mediaType string) bool {
+	return mediaType == v1.MediaTypeImageIndex || mediaType == mediaTypeDockerManifestList
+}
+
+// Resolve returns the descriptor the reference points at. When a tag does
+// not exist, similar tags of the repository are suggested.
+func Resolve(ctx context.Context, repo *remote.Repository, reference string) (v1.Descriptor, error) {
+	desc, err := repo.Resolve(ctx, reference)
+	if err != nil {
+		err = explainNotFound(ctx, repo, reference, err)
+		if _, ok := err.(*TagNotFoundError); ok {
+			return v1.Descriptor{}, err
+		}
+		return v1.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", reference, err)
+	}
+	return desc, nil
+}
+
+// fetch resolves the reference and reads the content it points at.
+func fetch(ctx context.Context, repo *remote.Repository, reference string) (v1.Descriptor, []byte, error) {
+	desc, rc, err := repo.FetchReference(ctx, reference)
+	if err != nil {
+		err = explainNotFound(ctx, repo, reference, err)
+		if _, ok := err.(*TagNotFoundError); ok {
+			return v1.Descriptor{}, nil, err
+		}
+		return v1.Descriptor{}, nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return v1.Descriptor{}, nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return desc, data, nil
+}
+
+// FetchIndex returns the index the reference points at.
+func FetchIndex(ctx context.Context, repo *remote.Repository, reference string) (v1.Descriptor, *v1.Index, error) {
+	desc, data, err := fetch(ctx, repo, reference)
+	if err != nil {
+		return v1.Descriptor{}, nil, err
+	}
+	if !IsIndex(desc.MediaType) {
+		return v1.Descriptor{}, nil, fmt.Errorf("%s is not an index", reference)
+	}
+
+	var index v1.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return v1.Descriptor{}, nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	return desc, &index, nil
+}
+
+// FetchManifest resolves the reference and returns the manifest descriptor
+// together with the parsed image manifest. When the reference points at an
+// index, the manifest is picked with SelectManifest.
+func FetchManifest(ctx context.Context, repo *remote.Repository, reference, variant string) (v1.Descriptor, *v1.Manifest, error) {
+	desc, data, err := fetch(ctx, repo, reference)
+	if err != nil {
+		return v1.Descriptor{}, nil, err
+	}
+
+	if IsIndex(desc.MediaType) {
+		var index v1.Index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return v1.Descriptor{}, nil, fmt.Errorf("failed to parse index: %w", err)
+		}
+		selected, err := SelectManifest(&index, variant)
+		if err != nil {
+			return v1.Descriptor{}, nil, fmt.Errorf("failed to select manifest of %s: %w", reference, err)
+		}
+		desc, data, err = fetch(ctx, repo, selected.Digest.String())
+		if err != nil {
+			return v1.Descriptor{}, nil, err
+		}
+	} else if variant != "" {
+		return v1.Descriptor{}, nil, fmt.Errorf("%s is not an index of variants, cannot select variant %q", reference, variant)
+	}
+
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return v1.Descriptor{}, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return desc, &manifest, nil
+}
+
+// TypeOf returns the artifact type of a manifest. Manifests pushed without
+// an artifact type, such as container images, are typed by their config.
+func TypeOf(manifest *v1.Manifest) string {
+	if manifest.ArtifactType != "" {
+		return manifest.ArtifactType
+	}
+	return manifest.Config.MediaType
+}
+
+// IsStack reports whether a manifest describes a kro RGD stack of any
+// version.
+func IsStack(manifest *v1.Manifest) bool {
+	typ := TypeOf(manifest)
+	return typ == ArtifactType || typ == ArtifactTypeV1
+}
+
+// FetchLayer downloads a layer blob and verifies it against its descriptor.
+func FetchLayer(ctx context.Context, fetcher content.Fetcher, desc v1.Descriptor) ([]byte, error) {
+	data, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch layer %s: %w", desc.Digest, err)
+	}
+	return data, nil
+}
+
+// LayerTitle returns the title annotation of a layer, or "unknown" when the
+// layer was pushed without one.
+func LayerTitle(desc v1.Descriptor) string {
+	if title, ok := desc.Annotations[v1.AnnotationTitle]; ok {
+		return title
+	}
+	return "unknown"
+}
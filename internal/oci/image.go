@@ -0,0 +1,122 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+const (
+	// dockerHubDomain is the registry of images named without one
+	dockerHubDomain = "docker.io"
+	// dockerHubRegistry serves the registry API of Docker Hub
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// NormalizeImage expands a container image reference the way container
+// runtimes do: images without a registry come from Docker Hub, official
+// images live in library/, and images without a tag or digest are latest.
+func NormalizeImage(image string) (registry.Reference, error) {
+	name := image
+	if first, rest, ok := strings.Cut(image, "/"); !ok || !strings.ContainsAny(first, ".:") && first != "localhost" {
+		name = dockerHubDomain + "/" + image
+	} else if first == "index."+dockerHubDomain {
+		name = dockerHubDomain + "/" + rest
+	}
+	if repo, ok := strings.CutPrefix(name, dockerHubDomain+"/"); ok && !strings.Contains(repo, "/") {
+		name = dockerHubDomain + "/library/" + repo
+	}
+	if !strings.Contains(name, "@") && !strings.Contains(name[strings.LastIndex(name, "/"):], ":") {
+		name += ":latest"
+	}
+
+	ref, err := registry.ParseReference(name)
+	if err != nil {
+		return registry.Reference{}, fmt.Errorf("invalid image %s: %w", image, err)
+	}
+	return ref, nil
+}
+
+// imageRepository sets up the repository of a container image, with the
+// reference set to the tag or digest of the image.
+func imageRepository(image string) (*remote.Repository, error) {
+	ref, err := NormalizeImage(image)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Registry == dockerHubDomain {
+		ref.Registry = dockerHubRegistry
+	}
+	return SetupRepository(ref.String())
+}
+
+// ResolveImage returns the digest the tag of a container image points at.
+// For multi-platform images that is the digest of the index, which pins
+// every platform.
+func ResolveImage(ctx context.Context, image string) (digest.Digest, error) {
+	repo, err := imageRepository(image)
+	if err != nil {
+		return "", err
+	}
+	desc, err := repo.Resolve(ctx, repo.Reference.Reference)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", image, err)
+	}
+	return desc.Digest, nil
+}
+
+// ImageLabels returns the labels of a container image, such as
+// org.opencontainers.image.licenses. For multi-platform images the labels
+// of linux/amd64 are returned, or of the first platform without it.
+func ImageLabels(ctx context.Context, image string) (map[string]string, error) {
+	repo, err := imageRepository(image)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, data, err := oras.FetchBytes(ctx, repo, repo.Reference.Reference, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", image, err)
+	}
+	if IsIndex(desc.MediaType) {
+		var index v1.Index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("failed to parse index of %s: %w", image, err)
+		}
+		if len(index.Manifests) == 0 {
+			return nil, fmt.Errorf("%s is an empty index", image)
+		}
+		selected := index.Manifests[0]
+		for _, m := range index.Manifests {
+			if m.Platform != nil && m.Platform.OS == "linux" && m.Platform.Architecture == "amd64" {
+				selected = m
+				break
+			}
+		}
+		if data, err = content.FetchAll(ctx, repo, selected); err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", image, err)
+		}
+	}
+
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest of %s: %w", image, err)
+	}
+	data, err = content.FetchAll(ctx, repo, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config of %s: %w", image, err)
+	}
+	var config v1.Image
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config of %s: %w", image, err)
+	}
+	return config.Config.Labels, nil
+}
@@ -0,0 +1,32 @@
+package oci_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestNormalizeImage(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                           "docker.io/library/nginx:latest",
+		"nginx:1.25":                      "docker.io/library/nginx:1.25",
+		"envoyproxy/envoy:v1.30.1":        "docker.io/envoyproxy/envoy:v1.30.1",
+		"index.docker.io/library/redis:7": "docker.io/library/redis:7",
+		"ghcr.io/acme/app":                "ghcr.io/acme/app:latest",
+		"localhost:5001/app:v1":           "localhost:5001/app:v1",
+		"localhost/app":                   "localhost/app:latest",
+		"nginx:1.25@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097":            "docker.io/library/nginx@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097",
+		"registry.k8s.io/pause@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097": "registry.k8s.io/pause@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097",
+	}
+	for image, want := range tests {
+		ref, err := oci.NormalizeImage(image)
+		require.NoError(t, err, image)
+		assert.Equal(t, want, ref.String(), image)
+	}
+
+	_, err := oci.NormalizeImage("ghcr.io/Acme/App:v1")
+	assert.Error(t, err)
+}
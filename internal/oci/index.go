@@ -0,0 +1,98 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// AnnotationVariant names the variant of a stack in an index, such as aws
+// or gcp, so several related stacks can share a tag.
+const AnnotationVariant = "run.kro.stack.variant"
+
+// Variants returns the variant names of the manifests in an index.
+func Variants(index *v1.Index) []string {
+	var variants []string
+	for _, m := range index.Manifests {
+		if v, ok := m.Annotations[AnnotationVariant]; ok {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// SelectManifest picks the manifest of an index to use. With a variant, the
+// manifest annotated with it is returned. Without one, the index must wrap
+// a single manifest.
+func SelectManifest(index *v1.Index, variant string) (v1.Descriptor, error) {
+	if variant != "" {
+		for _, m := range index.Manifests {
+			if m.Annotations[AnnotationVariant] == variant {
+				return m, nil
+			}
+		}
+		return v1.Descriptor{}, fmt.Errorf("variant %q not found, available variants: %v", variant, Variants(index))
+	}
+
+	if len(index.Manifests) != 1 {
+		if variants := Variants(index); len(variants) > 0 {
+			return v1.Descriptor{}, fmt.Errorf("index has %d manifests, select one with --variant: %v",
+				len(index.Manifests), variants)
+		}
+		return v1.Descriptor{}, fmt.Errorf("index has %d manifests, reference one of them by digest",
+			len(index.Manifests))
+	}
+	return index.Manifests[0], nil
+}
+
+// AddVariant tags an index that points at the manifest desc as the given
+// variant. Variants already in the index at tag are kept, except an older
+// manifest of the same variant which is replaced. The target is usually a
+// remote repository, but any target works.
+func AddVariant(ctx context.Context, target oras.Target, tag string, desc v1.Descriptor, variant string) (v1.Descriptor, error) {
+	index := &v1.Index{MediaType: v1.MediaTypeImageIndex}
+	index.SchemaVersion = 2
+
+	current, err := target.Resolve(ctx, tag)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+		// First variant under this tag
+	case err != nil:
+		return v1.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", tag, err)
+	case !IsIndex(current.MediaType):
+		return v1.Descriptor{}, fmt.Errorf("tag %s already holds a stack that is not an index of variants", tag)
+	default:
+		data, err := content.FetchAll(ctx, target, current)
+		if err != nil {
+			return v1.Descriptor{}, fmt.Errorf("failed to fetch index %s: %w", tag, err)
+		}
+		if err := json.Unmarshal(data, index); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("failed to parse index %s: %w", tag, err)
+		}
+	}
+
+	entry := desc
+	entry.Annotations = map[string]string{AnnotationVariant: variant}
+	index.Manifests = slices.DeleteFunc(index.Manifests, func(m v1.Descriptor) bool {
+		return m.Annotations[AnnotationVariant] == variant
+	})
+	index.Manifests = append(index.Manifests, entry)
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to encode index: %w", err)
+	}
+
+	indexDesc, err := oras.TagBytes(ctx, target, v1.MediaTypeImageIndex, data, tag)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to push index: %w", err)
+	}
+	return indexDesc, nil
+}
@@ -0,0 +1,45 @@
+package oci_test
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func variantIndex(variants ...string) *v1.Index {
+	index := &v1.Index{}
+	for _, v := range variants {
+		index.Manifests = append(index.Manifests, v1.Descriptor{
+			Digest:      digest.Digest("sha256:" + v),
+			Annotations: map[string]string{oci.AnnotationVariant: v},
+		})
+	}
+	return index
+}
+
+func TestSelectManifest_Variant(t *testing.T) {
+	desc, err := oci.SelectManifest(variantIndex("aws", "gcp"), "gcp")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:gcp", desc.Digest.String())
+}
+
+func TestSelectManifest_UnknownVariant(t *testing.T) {
+	_, err := oci.SelectManifest(variantIndex("aws", "gcp"), "azure")
+	assert.EqualError(t, err, `variant "azure" not found, available variants: [aws gcp]`)
+}
+
+func TestSelectManifest_SingleManifest(t *testing.T) {
+	desc, err := oci.SelectManifest(variantIndex("aws"), "")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:aws", desc.Digest.String())
+}
+
+func TestSelectManifest_RequiresVariant(t *testing.T) {
+	_, err := oci.SelectManifest(variantIndex("aws", "gcp"), "")
+	assert.EqualError(t, err, "index has 2 manifests, select one with --variant: [aws gcp]")
+}
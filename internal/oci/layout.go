@@ -0,0 +1,52 @@
+package oci
+
+import (
+	"fmt"
+	"strings"
+
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+)
+
+// LayoutPrefix starts references to a tag in a local OCI image layout
+// directory, such as oci-layout:./out:v1.0.0, instead of a registry.
+const LayoutPrefix = "oci-layout:"
+
+// LayoutReference is a tag in an OCI image layout directory.
+type LayoutReference struct {
+	Path string
+	Tag  string
+}
+
+func (r LayoutReference) String() string {
+	return LayoutPrefix + r.Path + ":" + r.Tag
+}
+
+// ParseLayoutReference parses an oci-layout:<path>:<tag> reference. It
+// reports false for references to a registry.
+func ParseLayoutReference(reference string) (LayoutReference, bool, error) {
+	rest, ok := strings.CutPrefix(reference, LayoutPrefix)
+	if !ok {
+		return LayoutReference{}, false, nil
+	}
+
+	i := strings.LastIndex(rest, ":")
+	if i <= 0 || strings.ContainsAny(rest[i+1:], `/\`) {
+		return LayoutReference{}, true, fmt.Errorf("invalid reference %s, expected %s<path>:<tag>", reference, LayoutPrefix)
+	}
+	ref := LayoutReference{Path: rest[:i], Tag: rest[i+1:]}
+	if err := (registry.Reference{Reference: ref.Tag}).ValidateReferenceAsTag(); err != nil {
+		return LayoutReference{}, true, fmt.Errorf("invalid tag in %s: %w", reference, err)
+	}
+	return ref, true, nil
+}
+
+// OpenLayout opens the OCI image layout at path, creating it when it does
+// not exist yet.
+func OpenLayout(path string) (*oci.Store, error) {
+	store, err := oci.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OCI layout %s: %w", path, err)
+	}
+	return store, nil
+}
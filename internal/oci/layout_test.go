@@ -0,0 +1,76 @@
+package oci_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestParseLayoutReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		want      oci.LayoutReference
+		layout    bool
+		wantErr   bool
+	}{
+		{reference: "ghcr.io/acme/platform:v1"},
+		{reference: "oci-layout:./out:v1", want: oci.LayoutReference{Path: "./out", Tag: "v1"}, layout: true},
+		{reference: "oci-layout:/tmp/a:b/out:v1.0.0", want: oci.LayoutReference{Path: "/tmp/a:b/out", Tag: "v1.0.0"}, layout: true},
+		{reference: "oci-layout:./out", layout: true, wantErr: true},
+		{reference: "oci-layout:./out:", layout: true, wantErr: true},
+		{reference: "oci-layout::v1", layout: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			got, ok, err := oci.ParseLayoutReference(tt.reference)
+			assert.Equal(t, tt.layout, ok)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSetupRepository_Layout(t *testing.T) {
+	_, err := oci.SetupRepository("oci-layout:./out:v1.0.0")
+	assert.EqualError(t, err, "oci-layout:./out:v1.0.0 is a local OCI layout, only kroctl push supports oci-layout: references")
+}
+
+func TestOpenLayout(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "out")
+
+	store, err := oci.OpenLayout(path)
+	require.NoError(t, err)
+	desc, err := oci.PackStack(ctx, store, &oci.StackConfig{Name: "out"}, nil, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.Tag(ctx, desc, "v1"))
+
+	_, err = os.Stat(filepath.Join(path, v1.ImageLayoutFile))
+	require.NoError(t, err)
+
+	// The tag survives reopening the directory
+	reopened, err := oci.OpenLayout(path)
+	require.NoError(t, err)
+	resolved, err := reopened.Resolve(ctx, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, desc.Digest, resolved.Digest)
+}
+
+func TestNewStackConfig_Layout(t *testing.T) {
+	config, err := oci.NewStackConfig("oci-layout:./build/kro-stack:v1.2.0", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "kro-stack", config.Name)
+	assert.Equal(t, "v1.2.0", config.Version)
+}
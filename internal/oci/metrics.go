@@ -0,0 +1,69 @@
+package oci
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// Metrics counts the registry traffic of the clients this package creates.
+type Metrics struct {
+	// Requests is the number of HTTP requests sent, retries included
+	Requests int64 `json:"requests"`
+	// Retries is the number of requests that were retried
+	Retries int64 `json:"retries"`
+	// RateLimited is the number of responses that asked to slow down
+	RateLimited int64 `json:"rateLimited"`
+	// BytesSent is the size of the request bodies
+	BytesSent int64 `json:"bytesSent"`
+	// BytesReceived is the size of the response bodies read
+	BytesReceived int64 `json:"bytesReceived"`
+}
+
+var counters struct {
+	requests, retries, rateLimited, sent, received atomic.Int64
+}
+
+// ReadMetrics returns the registry traffic counted so far.
+func ReadMetrics() Metrics {
+	return Metrics{
+		Requests:      counters.requests.Load(),
+		Retries:       counters.retries.Load(),
+		RateLimited:   counters.rateLimited.Load(),
+		BytesSent:     counters.sent.Load(),
+		BytesReceived: counters.received.Load(),
+	}
+}
+
+// countingTransport counts the requests and bytes that go over the wire.
+// It sits below the retry transport, so every attempt is counted.
+type countingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	counters.requests.Add(1)
+	if req.ContentLength > 0 {
+		counters.sent.Add(req.ContentLength)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		counters.rateLimited.Add(1)
+	}
+	resp.Body = &countingReader{ReadCloser: resp.Body}
+	return resp, nil
+}
+
+// countingReader counts the bytes read from a response body.
+type countingReader struct {
+	io.ReadCloser
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	counters.received.Add(int64(n))
+	return n, err
+}
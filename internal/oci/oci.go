@@ -5,20 +5,40 @@ import (
 	"strings"
 
 	"oras.land/oras-go/v2/registry/remote"
-	"oras.land/oras-go/v2/registry/remote/auth"
-	"oras.land/oras-go/v2/registry/remote/credentials"
 )
 
 const (
-	// ArtifactType identifies the OCI artifact as a kro RGD stack
-	ArtifactType = "application/vnd.kro.rgd.stack.v1"
+	// ArtifactType identifies the OCI artifact as a kro RGD stack, with a
+	// StackConfig as its config
+	ArtifactType = "application/vnd.kro.rgd.stack.v2"
+	// ArtifactTypeV1 identifies stacks pushed with an empty config, which
+	// kroctl still reads
+	ArtifactTypeV1 = "application/vnd.kro.rgd.stack.v1"
+	// ConfigMediaType identifies the StackConfig blob of a stack
+	ConfigMediaType = "application/vnd.kro.rgd.stack.config.v1+json"
 	// LayerMediaType identifies individual RGD YAML files
 	LayerMediaType = "application/vnd.kro.rgd.content.v1.yaml"
+	// ChecksumsMediaType identifies the SHA256SUMS layer of a stack
+	ChecksumsMediaType = "application/vnd.kro.rgd.checksums.v1.text"
+	// DocsMediaType identifies a gzipped tarball of documentation shipped
+	// with a stack
+	DocsMediaType = "application/vnd.kro.rgd.docs.v1.tar+gzip"
+	// ExamplesMediaType identifies a gzipped tarball of example instances
+	// shipped with a stack
+	ExamplesMediaType = "application/vnd.kro.rgd.examples.v1.tar+gzip"
+	// ArchiveMediaType identifies a gzipped tarball of all RGD files of a
+	// stack, for consumers such as Flux that read a single layer
+	ArchiveMediaType = "application/vnd.kro.rgd.archive.v1.tar+gzip"
 )
 
 // SetupRepository creates and configures a remote repository with authentication
-// and plain HTTP support for localhost registries.
+// and plain HTTP support for localhost registries. References to an OCI image
+// layout are rejected, only push writes to one.
 func SetupRepository(reference string) (*remote.Repository, error) {
+	if strings.HasPrefix(reference, LayoutPrefix) {
+		return nil, fmt.Errorf("%s is a local OCI layout, only kroctl push supports %s references", reference, LayoutPrefix)
+	}
+
 	repo, err := remote.NewRepository(reference)
 	if err != nil {
 		return nil, fmt.Errorf("invalid reference %s: %w", reference, err)
@@ -26,22 +46,24 @@ func SetupRepository(reference string) (*remote.Repository, error) {
 
 	// Enable plain HTTP for localhost and local registries
 	// TODO: remove this hack when we have proper TLS support
-	host := repo.Reference.Host()
-	if strings.HasPrefix(host, "localhost:") ||
-		strings.HasPrefix(host, "127.0.0.1:") ||
-		strings.HasPrefix(host, "::1:") {
+	if IsLocalRegistry(repo.Reference.Host()) {
 		repo.PlainHTTP = true
 	}
 
 	// Configure authentication
-	// TODO: uses Docker credentials for now.. support more methods later
-	credStore, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	client, err := newAuthClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create credential store: %w", err)
-	}
-	repo.Client = &auth.Client{
-		Credential: credentials.Credential(credStore),
+		return nil, err
 	}
+	repo.Client = client
 
 	return repo, nil
 }
+
+// IsLocalRegistry reports whether the host refers to a registry on the local
+// machine, which kroctl talks to over plain HTTP.
+func IsLocalRegistry(host string) bool {
+	return strings.HasPrefix(host, "localhost:") ||
+		strings.HasPrefix(host, "127.0.0.1:") ||
+		strings.HasPrefix(host, "::1:")
+}
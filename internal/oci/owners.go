@@ -0,0 +1,51 @@
+package oci
+
+const (
+	// AnnotationOwnerTeam names the team that owns a stack
+	AnnotationOwnerTeam = "run.kro.owner.team"
+	// AnnotationOwnerSlack is the Slack channel to ask about a stack
+	AnnotationOwnerSlack = "run.kro.owner.slack"
+	// AnnotationOwnerEscalation is the on-call escalation for a stack, such
+	// as a pager rotation
+	AnnotationOwnerEscalation = "run.kro.owner.escalation"
+)
+
+// Owners records whom consumers of a stack contact when it misbehaves.
+type Owners struct {
+	Team       string
+	Slack      string
+	Escalation string
+}
+
+// OwnersOf reads the owners recorded in the annotations of a manifest.
+func OwnersOf(annotations map[string]string) Owners {
+	return Owners{
+		Team:       annotations[AnnotationOwnerTeam],
+		Slack:      annotations[AnnotationOwnerSlack],
+		Escalation: annotations[AnnotationOwnerEscalation],
+	}
+}
+
+// IsZero reports whether no owners are recorded.
+func (o Owners) IsZero() bool {
+	return o == Owners{}
+}
+
+// Annotations returns the manifest annotations recording the owners, nil
+// when none are set.
+func (o Owners) Annotations() map[string]string {
+	if o.IsZero() {
+		return nil
+	}
+	annotations := map[string]string{}
+	for key, value := range map[string]string{
+		AnnotationOwnerTeam:       o.Team,
+		AnnotationOwnerSlack:      o.Slack,
+		AnnotationOwnerEscalation: o.Escalation,
+	} {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
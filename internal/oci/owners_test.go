@@ -0,0 +1,21 @@
+package oci_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestOwners_Annotations(t *testing.T) {
+	assert.Nil(t, oci.Owners{}.Annotations())
+
+	owners := oci.Owners{Team: "platform", Slack: "#platform-help"}
+	annotations := owners.Annotations()
+	assert.Equal(t, map[string]string{
+		oci.AnnotationOwnerTeam:  "platform",
+		oci.AnnotationOwnerSlack: "#platform-help",
+	}, annotations)
+	assert.Equal(t, owners, oci.OwnersOf(annotations))
+}
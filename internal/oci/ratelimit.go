@@ -0,0 +1,148 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateChunk caps how many bytes a single read may take from the limiter,
+// so waits stay short and the transfer rate smooth.
+const rateChunk = 32 * 1024
+
+var rateLimit *limiter
+
+// SetRateLimit caps the combined transfer rate of blob uploads and
+// downloads at bytesPerSecond. Zero removes the limit.
+func SetRateLimit(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		rateLimit = nil
+		return
+	}
+	rateLimit = &limiter{rate: float64(bytesPerSecond)}
+}
+
+// ParseRate parses a transfer rate in bytes per second, such as 500KiB,
+// 5MiB, or 1M. Units are binary; a trailing B and a "/s" are optional.
+func ParseRate(s string) (int64, error) {
+	value := strings.TrimSuffix(strings.TrimSpace(s), "/s")
+	upper := strings.ToUpper(value)
+	upper = strings.TrimSuffix(strings.TrimSuffix(upper, "B"), "I")
+
+	multiplier := int64(1)
+	if i := strings.IndexAny(upper, "KMG"); i >= 0 && i == len(upper)-1 {
+		multiplier = map[byte]int64{'K': 1 << 10, 'M': 1 << 20, 'G': 1 << 30}[upper[i]]
+		upper = upper[:i]
+	}
+
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate %q, expected a size such as 500KiB or 5MiB", s)
+	}
+	// A rate of zero would turn the limit off instead
+	rate := int64(n * float64(multiplier))
+	if rate < 1 {
+		return 0, fmt.Errorf("invalid rate %q, the rate must be at least 1 byte per second", s)
+	}
+	return rate, nil
+}
+
+// limiter paces byte transfers to a rate shared by all streams.
+type limiter struct {
+	mu   sync.Mutex
+	rate float64
+	next time.Time
+}
+
+// wait blocks until n more bytes may be transferred.
+func (l *limiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(float64(n) / l.rate * float64(time.Second)))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type limitedReader struct {
+	ctx     context.Context
+	r       io.ReadCloser
+	limiter *limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > rateChunk {
+		p = p[:rateChunk]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if werr := r.limiter.wait(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+func (r *limitedReader) Close() error {
+	return r.r.Close()
+}
+
+// limitedTransport applies the rate limit to request and response bodies
+// of blob uploads and downloads. Manifests and API calls are not limited.
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *limiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isBlobRequest(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+		req.Body = &limitedReader{ctx: ctx, r: req.Body, limiter: t.limiter}
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &limitedReader{ctx: ctx, r: resp.Body, limiter: t.limiter}
+	return resp, nil
+}
+
+// isBlobRequest reports whether req belongs to a blob transfer. Registries
+// often redirect blob downloads to object storage, such as presigned S3
+// URLs, so the requests that led to a redirect are checked as well.
+func isBlobRequest(req *http.Request) bool {
+	for req != nil {
+		if strings.Contains(req.URL.Path, "/blobs/") {
+			return true
+		}
+		if req.Response == nil {
+			break
+		}
+		req = req.Response.Request
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]int64{
+		"1024":     1024,
+		"500KiB":   500 << 10,
+		"5MiB":     5 << 20,
+		"5M":       5 << 20,
+		"1.5mb":    3 << 19,
+		"2GiB/s":   2 << 30,
+		" 64k ":    64 << 10,
+		"100B":     100,
+		"0.5KiB/s": 512,
+	}
+	for input, expected := range tests {
+		rate, err := ParseRate(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, rate, input)
+	}
+
+	for _, input := range []string{"", "fast", "0", "-1MiB", "5TiB", "M", "0.5", "0.9B"} {
+		_, err := ParseRate(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	l := &limiter{rate: 100 << 10}
+	r := &limitedReader{ctx: context.Background(), r: io.NopCloser(bytes.NewReader(make([]byte, 50<<10))), limiter: l}
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, r)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50<<10), n)
+	// The first chunk passes immediately, the rest is paced at 100 KiB/s
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestLimitedReader_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	l := &limiter{rate: 1, next: time.Now().Add(time.Hour)}
+	r := &limitedReader{ctx: ctx, r: io.NopCloser(bytes.NewReader([]byte("data"))), limiter: l}
+
+	_, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLimitedTransport_Redirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/stack/blobs/sha256:abc":
+			// As registries that hand out presigned storage URLs do
+			http.Redirect(w, r, "/storage/abc?signature=x", http.StatusTemporaryRedirect)
+		default:
+			_, _ = w.Write([]byte("data"))
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &limitedTransport{base: http.DefaultTransport, limiter: &limiter{rate: 1 << 20}}}
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("/v2/stack/blobs/sha256:abc")
+	assert.Equal(t, "/storage/abc", resp.Request.URL.Path)
+	assert.IsType(t, &limitedReader{}, resp.Body)
+
+	resp = get("/v2/stack/manifests/v1")
+	_, limited := resp.Body.(*limitedReader)
+	assert.False(t, limited)
+
+	resp = get("/storage/abc")
+	_, limited = resp.Body.(*limitedReader)
+	assert.False(t, limited)
+}
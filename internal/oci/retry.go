@@ -0,0 +1,146 @@
+package oci
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+const (
+	// maxRetries is how often a throttled or failed request is retried
+	maxRetries = 5
+	// maxRetryWait caps how long kroctl waits before a single retry, even
+	// if the registry asks for more
+	maxRetryWait = 2 * time.Minute
+)
+
+// rateLimitHeaders are the headers registries use to report the remaining
+// request budget: Docker Hub sends the RateLimit-* headers, GHCR and most
+// others the X-RateLimit-* ones.
+var rateLimitHeaders = []string{
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+}
+
+// Logger receives the retry and rate limit messages of the registry client.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Warn(msg string, args ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Warn(string, ...any)  {}
+
+var logger Logger = nopLogger{}
+
+// SetLogger sets the logger used by the registry clients this package
+// creates.
+func SetLogger(l Logger) {
+	logger = l
+}
+
+// rateLimitPolicy retries throttled requests after the delay the registry
+// asks for in Retry-After, and other transient failures with an exponential
+// backoff.
+type rateLimitPolicy struct{}
+
+func (rateLimitPolicy) Retry(attempt int, resp *http.Response, err error) (time.Duration, error) {
+	if resp != nil {
+		logRateLimit(resp)
+	}
+	if attempt >= maxRetries {
+		return -1, nil
+	}
+	if ok, err := retry.DefaultPredicate(resp, err); err != nil || !ok {
+		return -1, err
+	}
+
+	counters.retries.Add(1)
+	wait := min(retry.DefaultBackoff(attempt, resp), maxRetryWait)
+	if resp == nil {
+		logger.Debug("Retrying request after timeout",
+			"wait", wait.Round(time.Millisecond).String(),
+			"attempt", attempt+1)
+		return wait, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			wait = jitter(after)
+		}
+		wait = min(wait, maxRetryWait)
+		logger.Warn("Rate limited by registry, retrying",
+			"host", resp.Request.URL.Host,
+			"wait", wait.Round(time.Millisecond).String(),
+			"attempt", attempt+1)
+		return wait, nil
+	}
+
+	logger.Debug("Retrying request",
+		"url", resp.Request.URL.String(),
+		"status", resp.StatusCode,
+		"wait", wait.Round(time.Millisecond).String(),
+		"attempt", attempt+1)
+	return wait, nil
+}
+
+// logRateLimit reports the rate limit headers of a response, if any.
+func logRateLimit(resp *http.Response) {
+	var args []any
+	for _, h := range rateLimitHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			args = append(args, h, v)
+		}
+	}
+	if len(args) == 0 || resp.Request == nil {
+		return
+	}
+	logger.Debug("Registry rate limit", append([]any{"host", resp.Request.URL.Host}, args...)...)
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP
+// date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// jitter adds up to 20% to d, so parallel clients throttled at the same
+// moment do not all retry at once.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d + rand.N(d/5+1)
+}
+
+// newRetryClient returns an HTTP client that retries requests according to
+// rateLimitPolicy, applies the rate limit set with SetRateLimit, and counts
+// its traffic for ReadMetrics.
+func newRetryClient() *http.Client {
+	var base http.RoundTripper = &countingTransport{base: http.DefaultTransport}
+	if rateLimit != nil {
+		base = &limitedTransport{base: base, limiter: rateLimit}
+	}
+	return &http.Client{
+		Transport: &retry.Transport{
+			Base:   base,
+			Policy: func() retry.Policy { return rateLimitPolicy{} },
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package oci
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d, ok := retryAfter("30", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	d, ok = retryAfter("Wed, 01 Jan 2025 12:01:00 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, d)
+
+	_, ok = retryAfter("", now)
+	assert.False(t, ok)
+	_, ok = retryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestJitter(t *testing.T) {
+	for range 100 {
+		d := jitter(10 * time.Second)
+		assert.GreaterOrEqual(t, d, 10*time.Second)
+		assert.LessOrEqual(t, d, 12*time.Second)
+	}
+}
+
+func TestRetryClient_TooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := newRetryClient().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRateLimitPolicy_NotRetryable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://ghcr.io/v2/", nil)
+	resp := &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Request: req}
+
+	wait, err := rateLimitPolicy{}.Retry(0, resp, nil)
+	require.NoError(t, err)
+	assert.Negative(t, wait)
+}
+
+func TestRetryClient_Metrics(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	before := ReadMetrics()
+	resp, err := newRetryClient().Get(server.URL)
+	require.NoError(t, err)
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	after := ReadMetrics()
+
+	assert.Equal(t, int64(2), after.Requests-before.Requests)
+	assert.Equal(t, int64(1), after.Retries-before.Retries)
+	assert.Equal(t, int64(1), after.RateLimited-before.RateLimited)
+	assert.Equal(t, int64(5), after.BytesReceived-before.BytesReceived)
+}
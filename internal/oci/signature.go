@@ -0,0 +1,51 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// SignatureArtifactTypes are the artifact types of the signatures Notation
+// and cosign attach to a manifest as referrers.
+var SignatureArtifactTypes = []string{
+	"application/vnd.cncf.notary.signature",
+	"application/vnd.dev.cosign.artifact.sig.v1+json",
+}
+
+// Signed reports whether a signature is attached to subject, either as a
+// referrer or under the sha256-<hex>.sig tag cosign uses on registries
+// without the referrers API.
+func Signed(ctx context.Context, repo *remote.Repository, subject v1.Descriptor) (bool, error) {
+	referrers, err := registry.Referrers(ctx, repo, subject, "")
+	if err != nil {
+		return false, fmt.Errorf("failed to list signatures: %w", err)
+	}
+	for _, r := range referrers {
+		if slices.Contains(SignatureArtifactTypes, r.ArtifactType) {
+			return true, nil
+		}
+	}
+
+	_, err = repo.Resolve(ctx, CosignSignatureTag(subject))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, errdef.ErrNotFound):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to look up signature: %w", err)
+	}
+}
+
+// CosignSignatureTag returns the tag cosign stores the signature of a
+// manifest under, such as sha256-<hex>.sig.
+func CosignSignatureTag(subject v1.Descriptor) string {
+	return subject.Digest.Algorithm().String() + "-" + subject.Digest.Encoded() + ".sig"
+}
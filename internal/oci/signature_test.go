@@ -0,0 +1,14 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCosignSignatureTag(t *testing.T) {
+	desc := v1.Descriptor{Digest: digest.FromString("stack")}
+	assert.Equal(t, "sha256-"+desc.Digest.Encoded()+".sig", CosignSignatureTag(desc))
+}
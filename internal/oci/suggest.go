@@ -0,0 +1,139 @@
+package oci
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// maxSuggestions is the number of similar tags suggested for a missing tag.
+const maxSuggestions = 3
+
+// TagNotFoundError reports a missing tag, with similar tags that exist.
+type TagNotFoundError struct {
+	Tag         string
+	Repository  string
+	Suggestions []string
+}
+
+func (e *TagNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("tag %s not found in %s", e.Tag, e.Repository)
+	}
+	return fmt.Sprintf("tag %s not found in %s, did you mean %s?",
+		e.Tag, e.Repository, strings.Join(e.Suggestions, ", "))
+}
+
+func (e *TagNotFoundError) Unwrap() error {
+	return errdef.ErrNotFound
+}
+
+// explainNotFound adds the most similar existing tags to a not found error
+// for a tag reference. Other errors are returned unchanged.
+func explainNotFound(ctx context.Context, repo *remote.Repository, reference string, err error) error {
+	if !errors.Is(err, errdef.ErrNotFound) {
+		return err
+	}
+	ref, parseErr := repo.ParseReference(reference)
+	if parseErr != nil || ref.ValidateReferenceAsTag() != nil {
+		return err
+	}
+
+	var tags []string
+	if listErr := repo.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); listErr != nil {
+		// Most likely the repository does not exist either
+		return err
+	}
+
+	return &TagNotFoundError{
+		Tag:         ref.Reference,
+		Repository:  ref.Registry + "/" + ref.Repository,
+		Suggestions: SuggestTags(ref.Reference, tags),
+	}
+}
+
+// SuggestTags returns the tags most similar to tag: tags that extend it,
+// such as v1.0.0 for v1.0, followed by tags within a small edit distance.
+func SuggestTags(tag string, tags []string) []string {
+	type candidate struct {
+		tag      string
+		extends  bool
+		distance int
+		prefix   int
+	}
+
+	limit := max(2, len(tag)/3)
+	var candidates []candidate
+	for _, t := range tags {
+		c := candidate{
+			tag:      t,
+			extends:  strings.HasPrefix(t, tag),
+			distance: levenshtein(tag, t),
+			prefix:   commonPrefix(tag, t),
+		}
+		if c.extends || c.distance <= limit {
+			candidates = append(candidates, c)
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.extends != b.extends {
+			if a.extends {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(a.distance, b.distance); c != 0 {
+			return c
+		}
+		// On equal distance, prefer tags that differ later, e.g. v2.0.0
+		// over v1.0.1 for v2.0.1
+		if c := cmp.Compare(b.prefix, a.prefix); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.tag, b.tag)
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, c := range candidates[:min(len(candidates), maxSuggestions)] {
+		suggestions = append(suggestions, c.tag)
+	}
+	return suggestions
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
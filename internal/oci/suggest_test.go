@@ -0,0 +1,29 @@
+package oci_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestSuggestTags(t *testing.T) {
+	tags := []string{"latest", "v1.0.0", "v1.0.1", "v1.1.0", "v2.0.0"}
+
+	assert.Equal(t, []string{"v1.0.0", "v1.0.1", "v1.1.0"}, oci.SuggestTags("v1.0", tags))
+	assert.Equal(t, []string{"v2.0.0", "v1.0.1"}, oci.SuggestTags("v2.0.1", tags)[:2])
+	assert.Equal(t, []string{"latest"}, oci.SuggestTags("lastest", tags))
+	assert.Empty(t, oci.SuggestTags("nightly", tags))
+}
+
+func TestTagNotFoundError(t *testing.T) {
+	err := &oci.TagNotFoundError{Tag: "v1.0", Repository: "ghcr.io/acme/stack", Suggestions: []string{"v1.0.0", "v1.0.1"}}
+	assert.EqualError(t, err, "tag v1.0 not found in ghcr.io/acme/stack, did you mean v1.0.0, v1.0.1?")
+	assert.True(t, errors.Is(err, errdef.ErrNotFound))
+
+	err.Suggestions = nil
+	assert.EqualError(t, err, "tag v1.0 not found in ghcr.io/acme/stack")
+}
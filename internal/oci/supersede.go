@@ -0,0 +1,99 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// maxSupersedes bounds how many earlier versions Supersedes follows, so
+// long histories do not turn inspect into a crawl of the repository.
+const maxSupersedes = 10
+
+// ResolveSuperseded resolves the stack that a new version supersedes. The
+// new manifest names it as its OCI subject, which registries only accept
+// within the same repository, so reference is resolved in target.
+func ResolveSuperseded(ctx context.Context, target oras.ReadOnlyTarget, reference string) (v1.Descriptor, error) {
+	desc, err := target.Resolve(ctx, reference)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to resolve superseded stack %s: %w", reference, err)
+	}
+	if desc.MediaType != v1.MediaTypeImageManifest {
+		return v1.Descriptor{}, fmt.Errorf("superseded stack %s is not a manifest, use the digest of a variant", reference)
+	}
+	manifest, err := fetchManifestContent(ctx, target, desc)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if !IsStack(manifest) {
+		return v1.Descriptor{}, fmt.Errorf("superseded artifact %s is not a kro RGD stack", reference)
+	}
+	// The subject only identifies the manifest, annotations stay behind
+	return v1.Descriptor{MediaType: desc.MediaType, Digest: desc.Digest, Size: desc.Size}, nil
+}
+
+// Supersedes follows the subjects of a stack back through the versions it
+// supersedes, newest first. The descriptors carry the artifact type and
+// annotations of their manifest, like referrers do. The walk stops at the
+// first subject that is not a stack, which is still returned.
+func Supersedes(ctx context.Context, fetcher content.Fetcher, manifest *v1.Manifest) ([]v1.Descriptor, error) {
+	var chain []v1.Descriptor
+	for subject := manifest.Subject; subject != nil && len(chain) < maxSupersedes; {
+		desc := *subject
+		if desc.MediaType != v1.MediaTypeImageManifest {
+			chain = append(chain, desc)
+			break
+		}
+		m, err := fetchManifestContent(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		desc.ArtifactType = TypeOf(m)
+		desc.Annotations = m.Annotations
+		chain = append(chain, desc)
+		if !IsStack(m) {
+			break
+		}
+		subject = m.Subject
+	}
+	return chain, nil
+}
+
+// SupersededBy returns the stacks that name the manifest described by
+// subject as the version they supersede.
+func SupersededBy(ctx context.Context, repo *remote.Repository, subject v1.Descriptor) ([]v1.Descriptor, error) {
+	var newer []v1.Descriptor
+	// Like approvals, filter here because some registries report the config
+	// media type as the artifact type of referrers
+	err := repo.Referrers(ctx, subject, "", func(referrers []v1.Descriptor) error {
+		for _, r := range referrers {
+			if r.ArtifactType != ArtifactType && r.ArtifactType != ConfigMediaType {
+				continue
+			}
+			r.ArtifactType = ArtifactType
+			newer = append(newer, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list superseding stacks: %w", err)
+	}
+	return newer, nil
+}
+
+func fetchManifestContent(ctx context.Context, fetcher content.Fetcher, desc v1.Descriptor) (*v1.Manifest, error) {
+	data, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest %s: %w", desc.Digest, err)
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+	}
+	return &manifest, nil
+}
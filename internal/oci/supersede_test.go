@@ -0,0 +1,62 @@
+package oci_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+)
+
+func TestSupersedes(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	var subject *v1.Descriptor
+	var versions []v1.Descriptor
+	for _, version := range []string{"v1", "v2", "v3"} {
+		desc, err := oci.PackStack(ctx, store, &oci.StackConfig{Name: "stack", Version: version}, nil, nil, subject)
+		require.NoError(t, err)
+		require.NoError(t, store.Tag(ctx, desc, version))
+
+		if version != "v3" {
+			resolved, err := oci.ResolveSuperseded(ctx, store, version)
+			require.NoError(t, err)
+			subject = &resolved
+		}
+		versions = append(versions, desc)
+	}
+
+	_, data, err := oras.FetchBytes(ctx, store, "v3", oras.DefaultFetchBytesOptions)
+	require.NoError(t, err)
+	var manifest v1.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	chain, err := oci.Supersedes(ctx, store, &manifest)
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, versions[1].Digest, chain[0].Digest)
+	assert.Equal(t, versions[0].Digest, chain[1].Digest)
+	assert.Equal(t, oci.ArtifactType, chain[0].ArtifactType)
+}
+
+func TestResolveSuperseded_NotAStack(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.example.sbom", oras.PackManifestOptions{})
+	require.NoError(t, err)
+	require.NoError(t, store.Tag(ctx, desc, "sbom"))
+
+	_, err = oci.ResolveSuperseded(ctx, store, "sbom")
+	assert.ErrorContains(t, err, "is not a kro RGD stack")
+
+	_, err = oci.ResolveSuperseded(ctx, store, "missing")
+	assert.ErrorContains(t, err, "failed to resolve superseded stack missing")
+}
@@ -0,0 +1,152 @@
+package rgd
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// identPattern matches CEL identifiers.
+	identPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+	// macroVarPattern matches the variable bound by a comprehension macro,
+	// e.g. the x in items.map(x, x.name).
+	macroVarPattern = regexp.MustCompile(`\.(?:all|exists|exists_one|map|filter|bind)\(\s*([A-Za-z_][A-Za-z0-9_]*)\s*,`)
+	// namespaceCallPattern matches a call of a namespaced function, such as
+	// the .encode( of base64.encode(x).
+	namespaceCallPattern = regexp.MustCompile(`^\.\s*[A-Za-z_][A-Za-z0-9_]*\s*\(`)
+)
+
+// celNamespaces are the namespaces of the CEL extension functions kro
+// enables, such as base64.encode and math.greatest.
+var celNamespaces = map[string]bool{
+	"base64":   true,
+	"cel":      true,
+	"lists":    true,
+	"math":     true,
+	"optional": true,
+	"random":   true,
+	"sets":     true,
+	"strings":  true,
+}
+
+// Expressions returns the CEL expressions embedded in s using the ${...}
+// syntax, without their delimiters.
+func Expressions(s string) []string {
+	var exprs []string
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			return exprs
+		}
+		end := matchingBrace(s, start+1)
+		if end < 0 {
+			return exprs
+		}
+		exprs = append(exprs, strings.TrimSpace(s[start+2:end]))
+		s = s[end+1:]
+	}
+}
+
+// Selector is a chain of field selections starting at a root identifier,
+// such as vpc.status.vpcID.
+type Selector struct {
+	Root   string
+	Fields []string
+}
+
+// String joins the selector back into its dotted form.
+func (s Selector) String() string {
+	return strings.Join(append([]string{s.Root}, s.Fields...), ".")
+}
+
+// Selectors returns the field selections an expression makes from root
+// identifiers, in order of appearance. Identifiers inside string literals,
+// function names, calls of CEL extension functions such as base64.encode,
+// and variables bound by comprehension macros are skipped.
+// A chain ends at the first index, call, or operator.
+func Selectors(expr string) []Selector {
+	bound := map[string]bool{}
+	for _, m := range macroVarPattern.FindAllStringSubmatch(expr, -1) {
+		bound[m[1]] = true
+	}
+
+	var selectors []Selector
+	code := stripStringLiterals(expr)
+	for _, loc := range identPattern.FindAllStringIndex(code, -1) {
+		start, end := loc[0], loc[1]
+
+		// Skip field selections and identifiers that are part of a number
+		if start > 0 && (code[start-1] == '.' || isDigit(code[start-1])) {
+			continue
+		}
+		// Only identifiers followed by a selection are references
+		rest := strings.TrimLeft(code[end:], " \t")
+		if rest == "" || (rest[0] != '.' && rest[0] != '[') {
+			continue
+		}
+
+		ident := code[start:end]
+		if bound[ident] || (celNamespaces[ident] && namespaceCallPattern.MatchString(rest)) {
+			continue
+		}
+
+		selector := Selector{Root: ident}
+		for strings.HasPrefix(rest, ".") {
+			field := identPattern.FindString(rest[1:])
+			if field == "" || !strings.HasPrefix(rest[1:], field) {
+				break
+			}
+			after := strings.TrimLeft(rest[1+len(field):], " \t")
+			if strings.HasPrefix(after, "(") {
+				// A method call such as .map() or .exists() ends the chain
+				break
+			}
+			selector.Fields = append(selector.Fields, field)
+			rest = after
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors
+}
+
+// References returns the distinct root identifiers an expression selects
+// fields from, such as vpc in vpc.status.vpcID.
+func References(expr string) []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, s := range Selectors(expr) {
+		if !seen[s.Root] {
+			seen[s.Root] = true
+			refs = append(refs, s.Root)
+		}
+	}
+	return refs
+}
+
+// stripStringLiterals blanks out the contents of quoted CEL strings so that
+// identifiers inside them are not mistaken for references.
+func stripStringLiterals(expr string) string {
+	b := []byte(expr)
+	var quote byte
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(b) {
+				b[i], b[i+1] = ' ', ' '
+				i++
+			} else if c == quote {
+				quote = 0
+			} else {
+				b[i] = ' '
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		}
+	}
+	return string(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
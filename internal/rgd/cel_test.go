@@ -0,0 +1,54 @@
+package rgd_test
+
+import (
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpressions(t *testing.T) {
+	assert.Equal(t, []string{"schema.spec.name"}, rgd.Expressions("${schema.spec.name}-vpc"))
+	assert.Equal(t, []string{"a.b", "c"}, rgd.Expressions("x ${ a.b } y ${c}"))
+	assert.Equal(t, []string{"{'k': 'v'}.k"}, rgd.Expressions("${{'k': 'v'}.k}"))
+	assert.Empty(t, rgd.Expressions("plain value"))
+	assert.Empty(t, rgd.Expressions("${unterminated"))
+}
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"vpc.status.vpcID", []string{"vpc"}},
+		{"schema.spec.name + '-' + subnet.metadata.name", []string{"schema", "subnet"}},
+		{"has(vpc.status) ? vpc.status.id : ''", []string{"vpc"}},
+		{"deployments[0].status", []string{"deployments"}},
+		{"'vpc.status' + name", nil},
+		{"pods.map(p, p.metadata.name)", []string{"pods"}},
+		{"size(items) > 1.5", nil},
+		{"base64.encode(bytes(schema.spec.secret))", []string{"schema"}},
+		{"math.greatest(schema.spec.replicas, 1)", []string{"schema"}},
+		{"strings.quote(cm.data.name)", []string{"cm"}},
+		{"lists.range(3).map(i, i * 2)", nil},
+		{"sets.contains(schema.spec.zones, ['a'])", []string{"schema"}},
+		{"cel.bind(n, vpc.status.name, n + '-x')", []string{"vpc"}},
+		{"math.status.ready", []string{"math"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			assert.Equal(t, tt.want, rgd.References(tt.expr))
+		})
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	selectors := rgd.Selectors("vpc.status.ids.map(i, i.name) + schema.spec.name")
+
+	assert.Equal(t, []rgd.Selector{
+		{Root: "vpc", Fields: []string{"status", "ids"}},
+		{Root: "schema", Fields: []string{"spec", "name"}},
+	}, selectors)
+	assert.Equal(t, "vpc.status.ids", selectors[0].String())
+}
@@ -0,0 +1,171 @@
+package rgd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// removedAPI describes a Kubernetes API version that was deprecated and
+// removed from the API server in a later release.
+type removedAPI struct {
+	APIVersion  string
+	Kinds       []string
+	Removed     string
+	Replacement string
+}
+
+// removedAPIs lists the deprecated Kubernetes APIs that resource templates
+// might still use, from the upstream deprecated API migration guide.
+var removedAPIs = []removedAPI{
+	{"extensions/v1beta1", []string{"Deployment", "DaemonSet", "ReplicaSet"}, "1.16", "apps/v1"},
+	{"apps/v1beta1", []string{"Deployment", "StatefulSet", "ReplicaSet"}, "1.16", "apps/v1"},
+	{"apps/v1beta2", []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet"}, "1.16", "apps/v1"},
+	{"extensions/v1beta1", []string{"NetworkPolicy"}, "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", []string{"PodSecurityPolicy"}, "1.16", ""},
+	{"extensions/v1beta1", []string{"Ingress"}, "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", []string{"Ingress", "IngressClass"}, "1.22", "networking.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", []string{"CustomResourceDefinition"}, "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", []string{"APIService"}, "1.22", "apiregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", []string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}, "1.22", "admissionregistration.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", []string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", []string{"PriorityClass"}, "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, "1.22", "storage.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", []string{"CertificateSigningRequest"}, "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", []string{"Lease"}, "1.22", "coordination.k8s.io/v1"},
+	{"batch/v1beta1", []string{"CronJob"}, "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", []string{"EndpointSlice"}, "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", []string{"Event"}, "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", []string{"HorizontalPodAutoscaler"}, "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", []string{"PodDisruptionBudget"}, "1.25", "policy/v1"},
+	{"policy/v1beta1", []string{"PodSecurityPolicy"}, "1.25", ""},
+	{"node.k8s.io/v1beta1", []string{"RuntimeClass"}, "1.25", "node.k8s.io/v1"},
+	{"autoscaling/v2beta2", []string{"HorizontalPodAutoscaler"}, "1.26", "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", []string{"CSIStorageCapacity"}, "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// lookupRemovedAPI returns the removal entry for a template's apiVersion
+// and kind, if the API is deprecated.
+func lookupRemovedAPI(apiVersion, kind string) (removedAPI, bool) {
+	for _, api := range removedAPIs {
+		if api.APIVersion != apiVersion {
+			continue
+		}
+		for _, k := range api.Kinds {
+			if k == kind {
+				return api, true
+			}
+		}
+	}
+	return removedAPI{}, false
+}
+
+// lintDeprecations reports resource templates that use deprecated
+// Kubernetes APIs. APIs that are removed in the target Kubernetes version
+// are errors, all others are warnings. Without a target version every
+// deprecated API is a warning.
+func (l *linter) lintDeprecations(target string) {
+	for _, r := range l.rgd.Spec.Resources {
+		apiVersion := mappingValue(&r.Template, "apiVersion")
+		kind := mappingValue(&r.Template, "kind")
+		if apiVersion == nil || kind == nil {
+			continue
+		}
+
+		api, ok := lookupRemovedAPI(apiVersion.Value, kind.Value)
+		if !ok {
+			continue
+		}
+
+		msg := fmt.Sprintf("resource %s uses %s %s, which is removed in Kubernetes %s",
+			r.ID, api.APIVersion, kind.Value, api.Removed)
+		if api.Replacement != "" {
+			msg += fmt.Sprintf(", use %s instead", api.Replacement)
+		}
+
+		severity := SeverityWarning
+		if target != "" && compareMinorVersions(target, api.Removed) >= 0 {
+			severity = SeverityError
+		}
+		l.diags = append(l.diags, Diagnostic{
+			File:     l.file,
+			Line:     apiVersion.Line,
+			Severity: severity,
+			Message:  msg,
+		})
+	}
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// ParseKubernetesVersion validates a Kubernetes version such as 1.29 or
+// v1.29.3 and returns it as major.minor.
+func ParseKubernetesVersion(version string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", fmt.Errorf("invalid Kubernetes version %q, expected a version like 1.29", version)
+	}
+	for _, p := range parts {
+		if _, err := strconv.Atoi(p); err != nil {
+			return "", fmt.Errorf("invalid Kubernetes version %q, expected a version like 1.29", version)
+		}
+	}
+	return parts[0] + "." + parts[1], nil
+}
+
+// compareMinorVersions compares two major.minor versions numerically.
+func compareMinorVersions(a, b string) int {
+	aMajor, aMinor := splitMinorVersion(a)
+	bMajor, bMinor := splitMinorVersion(b)
+	if aMajor != bMajor {
+		return aMajor - bMajor
+	}
+	return aMinor - bMinor
+}
+
+func splitMinorVersion(v string) (int, int) {
+	major, minor, _ := strings.Cut(v, ".")
+	ma, _ := strconv.Atoi(major)
+	mi, _ := strconv.Atoi(minor)
+	return ma, mi
+}
+
+// KubernetesRemoval returns the earliest Kubernetes version that removes an
+// API the RGDs create resources of, or an empty string when they use no
+// removed APIs.
+func KubernetesRemoval(rgds []*ResourceGraphDefinition) string {
+	var earliest string
+	for _, r := range rgds {
+		for _, res := range r.Spec.Resources {
+			apiVersion := mappingValue(&res.Template, "apiVersion")
+			kind := mappingValue(&res.Template, "kind")
+			if apiVersion == nil || kind == nil {
+				continue
+			}
+			api, ok := lookupRemovedAPI(apiVersion.Value, kind.Value)
+			if !ok {
+				continue
+			}
+			if earliest == "" || compareMinorVersions(api.Removed, earliest) < 0 {
+				earliest = api.Removed
+			}
+		}
+	}
+	return earliest
+}
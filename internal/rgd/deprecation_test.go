@@ -0,0 +1,93 @@
+package rgd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deprecatedRGD = "apiVersion: kro.run/v1alpha1\n" +
+	"kind: ResourceGraphDefinition\n" +
+	"metadata:\n" +
+	"  name: app\n" +
+	"spec:\n" +
+	"  schema:\n" +
+	"    apiVersion: v1alpha1\n" +
+	"    kind: App\n" +
+	"  resources:\n" +
+	"    - id: pdb\n" +
+	"      template:\n" +
+	"        apiVersion: policy/v1beta1\n" +
+	"        kind: PodDisruptionBudget\n" +
+	"    - id: deployment\n" +
+	"      template:\n" +
+	"        apiVersion: apps/v1\n" +
+	"        kind: Deployment\n"
+
+func lintDeprecated(t *testing.T, version string) rgd.Diagnostics {
+	t.Helper()
+	rgds, err := rgd.Parse("rgd.yaml", []byte(deprecatedRGD))
+	require.NoError(t, err)
+	return rgd.Lint("rgd.yaml", rgds[0], rgd.LintOptions{KubernetesVersion: version})
+}
+
+func TestLint_DeprecatedAPIWarning(t *testing.T) {
+	diags := lintDeprecated(t, "")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, rgd.SeverityWarning, diags[0].Severity)
+	assert.Equal(t, 12, diags[0].Line)
+	assert.False(t, diags.HasErrors())
+	assert.Equal(t, "rgd.yaml:12: warning: resource pdb uses policy/v1beta1 PodDisruptionBudget, "+
+		"which is removed in Kubernetes 1.25, use policy/v1 instead", diags[0].String())
+}
+
+func TestLint_DeprecatedAPIBeforeRemoval(t *testing.T) {
+	diags := lintDeprecated(t, "1.24")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, rgd.SeverityWarning, diags[0].Severity)
+}
+
+func TestLint_RemovedAPIError(t *testing.T) {
+	diags := lintDeprecated(t, "1.29")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, rgd.SeverityError, diags[0].Severity)
+	assert.True(t, diags.HasErrors())
+}
+
+func TestParseKubernetesVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.29":    "1.29",
+		"v1.29.3": "1.29",
+		"1.30.0":  "1.30",
+	}
+	for in, want := range tests {
+		got, err := rgd.ParseKubernetesVersion(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+
+	for _, in := range []string{"", "1", "one.two", "1.2.3.4"} {
+		_, err := rgd.ParseKubernetesVersion(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestKubernetesRemoval(t *testing.T) {
+	data := deprecatedRGD + "    - id: ingress\n" +
+		"      template:\n" +
+		"        apiVersion: networking.k8s.io/v1beta1\n" +
+		"        kind: Ingress\n"
+	rgds, err := rgd.Parse("rgd.yaml", []byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "1.22", rgd.KubernetesRemoval(rgds))
+
+	rgds, err = rgd.Parse("rgd.yaml", []byte(strings.Replace(deprecatedRGD, "policy/v1beta1", "policy/v1", 1)))
+	require.NoError(t, err)
+	assert.Empty(t, rgd.KubernetesRemoval(rgds))
+}
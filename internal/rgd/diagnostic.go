@@ -0,0 +1,105 @@
+package rgd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity tells whether a diagnostic must be fixed or is advisory.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// String returns the string representation of the Severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a problem found in an RGD file, positioned at a line.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Severity Severity
+	Message  string
+}
+
+// String formats the diagnostic as file:line: message. Warnings are
+// prefixed so they stand out from errors.
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if d.Severity == SeverityWarning {
+		msg = "warning: " + msg
+	}
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, msg)
+	}
+	return fmt.Sprintf("%s: %s", d.File, msg)
+}
+
+// Diagnostics is a list of problems that can be returned as a single error.
+type Diagnostics []Diagnostic
+
+// HasErrors reports whether any of the diagnostics is an error.
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Error joins the diagnostics, one per line.
+func (d Diagnostics) Error() string {
+	lines := make([]string, len(d))
+	for i, diag := range d {
+		lines[i] = diag.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	yamlLinePattern     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// diagnosticsFromYAML converts a yaml.v3 decoding error into diagnostics,
+// extracting line numbers and rewording messages that mention Go types.
+func diagnosticsFromYAML(filename string, err error) Diagnostics {
+	var messages []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	diags := make(Diagnostics, 0, len(messages))
+	for _, msg := range messages {
+		diag := Diagnostic{File: filename, Message: strings.TrimPrefix(msg, "yaml: ")}
+		if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+			diag.Line, _ = strconv.Atoi(m[1])
+			diag.Message = m[2]
+		}
+		if m := unknownFieldPattern.FindStringSubmatch(diag.Message); m != nil {
+			diag.Message = fmt.Sprintf("unknown field %q", m[1])
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
@@ -0,0 +1,129 @@
+package rgd
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// documentedMarkers are the markers shown in their own column of the
+// reference, the others are listed as constraints.
+var documentedMarkers = []string{"required", "default", "description"}
+
+// Markdown renders a reference document for the instance API the RGD
+// defines: its spec and status fields, validation rules, and the resources
+// an instance creates.
+func Markdown(r *ResourceGraphDefinition) []byte {
+	var b bytes.Buffer
+	s := r.Spec.Schema
+	if s == nil {
+		s = &Schema{}
+	}
+
+	group := s.Group
+	if group == "" {
+		group = Group
+	}
+	fmt.Fprintf(&b, "# %s\n\n", s.Kind)
+	fmt.Fprintf(&b, "`%s/%s`, defined by the ResourceGraphDefinition `%s`.\n", group, s.APIVersion, r.Metadata.Name)
+
+	b.WriteString("\n## Spec\n\n")
+	if fields := s.SpecFields(); len(fields) > 0 {
+		b.WriteString("| Field | Type | Required | Default | Description | Constraints |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, f := range fields {
+			required := ""
+			if f.Required() {
+				required = "yes"
+			}
+			def, _ := f.Default()
+			fmt.Fprintf(&b, "| `spec.%s` | %s | %s | %s | %s | %s |\n",
+				f.Path, f.Type, required, code(def), cell(f.Description()), cell(constraints(f)))
+		}
+	} else {
+		b.WriteString("The API has no spec fields.\n")
+	}
+
+	if fields := s.StatusFields(); len(fields) > 0 {
+		b.WriteString("\n## Status\n\n")
+		b.WriteString("| Field | Expression |\n")
+		b.WriteString("|---|---|\n")
+		for _, f := range fields {
+			fmt.Fprintf(&b, "| `status.%s` | %s |\n", f.Path, code(f.Expression))
+		}
+	}
+
+	if len(s.Validation) > 0 {
+		b.WriteString("\n## Validation\n\n")
+		b.WriteString("| Rule | Message |\n")
+		b.WriteString("|---|---|\n")
+		for _, v := range s.Validation {
+			fmt.Fprintf(&b, "| %s | %s |\n", code(v.Expression), cell(v.Message))
+		}
+	}
+
+	if len(r.Spec.Resources) > 0 {
+		b.WriteString("\n## Resources\n\n")
+		b.WriteString("| ID | API | Notes |\n")
+		b.WriteString("|---|---|---|\n")
+		for _, res := range r.Spec.Resources {
+			api, notes := resourceAPI(res)
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", res.ID, cell(api), cell(notes))
+		}
+	}
+
+	return b.Bytes()
+}
+
+// resourceAPI describes the API of a resource and how it is managed.
+func resourceAPI(res *Resource) (string, string) {
+	var notes []string
+	var apiVersion, kind string
+	if res.ExternalRef != nil {
+		apiVersion, kind = res.ExternalRef.APIVersion, res.ExternalRef.Kind
+		notes = append(notes, "existing object, read only")
+	} else {
+		if n := mappingValue(&res.Template, "apiVersion"); n != nil {
+			apiVersion = n.Value
+		}
+		if n := mappingValue(&res.Template, "kind"); n != nil {
+			kind = n.Value
+		}
+	}
+	if len(res.IncludeWhen) > 0 {
+		notes = append(notes, "created when "+strings.Join(res.IncludeWhen, " and "))
+	}
+	return strings.TrimSpace(apiVersion + " " + kind), strings.Join(notes, "; ")
+}
+
+// constraints lists the markers of a field that have no column of their own.
+func constraints(f Field) string {
+	var names []string
+	for name := range f.Markers {
+		if !slices.Contains(documentedMarkers, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+f.Markers[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// cell escapes text for a markdown table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// code renders text as inline code in a table cell.
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + cell(s) + "`"
+}
@@ -0,0 +1,63 @@
+package rgd_test
+
+import (
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+	data := `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: web-app
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: WebApp
+    spec:
+      name: string | required=true description="Name of the app"
+      replicas: integer | default=3 minimum=1
+      ingress:
+        enabled: boolean | default=false
+    status:
+      ready: ${deployment.status.readyReplicas > 0 || false}
+    validation:
+      - expression: self.replicas <= 10
+        message: at most 10 replicas
+  resources:
+    - id: deployment
+      template:
+        apiVersion: apps/v1
+        kind: Deployment
+    - id: ingress
+      includeWhen:
+        - ${schema.spec.ingress.enabled}
+      template:
+        apiVersion: networking.k8s.io/v1
+        kind: Ingress
+    - id: settings
+      externalRef:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: settings
+`
+	rgds, err := rgd.Parse("app.yaml", []byte(data))
+	require.NoError(t, err)
+
+	doc := string(rgd.Markdown(rgds[0]))
+	assert.Contains(t, doc, "# WebApp\n")
+	assert.Contains(t, doc, "`kro.run/v1alpha1`, defined by the ResourceGraphDefinition `web-app`.")
+	assert.Contains(t, doc, "| `spec.name` | string | yes |  | Name of the app |  |\n")
+	assert.Contains(t, doc, "| `spec.replicas` | integer |  | `3` |  | minimum=1 |\n")
+	assert.Contains(t, doc, "| `spec.ingress` | object |")
+	assert.Contains(t, doc, "| `spec.ingress.enabled` | boolean |  | `false` |")
+	assert.Contains(t, doc, "| `status.ready` | `${deployment.status.readyReplicas > 0 \\|\\| false}` |\n")
+	assert.Contains(t, doc, "| `self.replicas <= 10` | at most 10 replicas |\n")
+	assert.Contains(t, doc, "| `deployment` | apps/v1 Deployment |  |\n")
+	assert.Contains(t, doc, "| `ingress` | networking.k8s.io/v1 Ingress | created when ${schema.spec.ingress.enabled} |\n")
+	assert.Contains(t, doc, "| `settings` | v1 ConfigMap | existing object, read only |\n")
+}
@@ -0,0 +1,182 @@
+package rgd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyOrder lists the well-known keys of a ResourceGraphDefinition in their
+// canonical order. Keys that are not listed keep their relative order and
+// are placed after the well-known ones.
+var keyOrder = map[string][]string{
+	"":                           {"apiVersion", "kind", "metadata", "spec", "status"},
+	"metadata":                   {"name", "namespace", "labels", "annotations"},
+	"spec":                       {"schema", "resources"},
+	"spec.schema":                {"apiVersion", "kind", "group", "spec", "status", "validation", "additionalPrinterColumns"},
+	"spec.resources":             {"id", "readyWhen", "includeWhen", "externalRef", "template"},
+	"spec.resources.template":    {"apiVersion", "kind", "metadata", "spec"},
+	"spec.resources.externalRef": {"apiVersion", "kind", "metadata"},
+}
+
+// Format returns the canonical formatting of one or more YAML documents
+// containing ResourceGraphDefinitions: well-known keys are ordered, the
+// indentation is two spaces, CEL expressions have no padding inside their
+// ${...} delimiters, and the output ends with a single newline.
+func Format(data []byte) ([]byte, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		formatNode(&doc, "")
+
+		if err := enc.Encode(&doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatNode walks the node tree, ordering mapping keys based on the path of
+// the mapping within the document and normalizing CEL expressions in scalars.
+func formatNode(n *yaml.Node, path string) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			formatNode(c, path)
+		}
+	case yaml.SequenceNode:
+		// Sequence items share the path of the sequence itself
+		for _, c := range n.Content {
+			formatNode(c, path)
+		}
+	case yaml.MappingNode:
+		if order, ok := keyOrder[path]; ok {
+			sortMapping(n, order)
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			formatNode(n.Content[i+1], joinPath(path, n.Content[i].Value))
+		}
+	case yaml.ScalarNode:
+		if n.Tag == "!!str" {
+			n.Value = normalizeCEL(n.Value)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortMapping reorders the key/value pairs of a mapping node so the keys in
+// order come first, stably keeping the original order of everything else.
+func sortMapping(n *yaml.Node, order []string) {
+	rank := make(map[string]int, len(order))
+	for i, key := range order {
+		rank[key] = i
+	}
+
+	type pair struct{ key, value *yaml.Node }
+	var known = make([]*pair, len(order))
+	var rest []pair
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		p := pair{n.Content[i], n.Content[i+1]}
+		if r, ok := rank[p.key.Value]; ok && known[r] == nil {
+			known[r] = &p
+			continue
+		}
+		rest = append(rest, p)
+	}
+
+	content := make([]*yaml.Node, 0, len(n.Content))
+	for _, p := range known {
+		if p != nil {
+			content = append(content, p.key, p.value)
+		}
+	}
+	for _, p := range rest {
+		content = append(content, p.key, p.value)
+	}
+	n.Content = content
+}
+
+// normalizeCEL trims the whitespace just inside each ${...} expression in s.
+// Braces nested inside an expression, such as map literals, are balanced so
+// the expression is not cut short.
+func normalizeCEL(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+
+		end := matchingBrace(s, start+1)
+		if end < 0 {
+			// Unterminated expression, leave the rest untouched
+			b.WriteString(s)
+			return b.String()
+		}
+
+		b.WriteString(s[:start])
+		b.WriteString("${")
+		b.WriteString(strings.TrimSpace(s[start+2 : end]))
+		b.WriteString("}")
+		s = s[end+1:]
+	}
+}
+
+// matchingBrace returns the index of the brace closing the one at open,
+// skipping over braces inside quoted CEL string literals.
+func matchingBrace(s string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
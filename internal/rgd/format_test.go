@@ -0,0 +1,84 @@
+package rgd_test
+
+import (
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_OrdersWellKnownKeys(t *testing.T) {
+	in := "spec:\n" +
+		"  resources:\n" +
+		"    - template:\n" +
+		"        spec: {}\n" +
+		"        kind: VPC\n" +
+		"      id: vpc\n" +
+		"  schema:\n" +
+		"    kind: VPCModule\n" +
+		"    apiVersion: v1alpha1\n" +
+		"metadata:\n" +
+		"  name: vpc\n" +
+		"kind: ResourceGraphDefinition\n" +
+		"apiVersion: kro.run/v1alpha1\n"
+
+	out, err := rgd.Format([]byte(in))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: kro.run/v1alpha1\n"+
+		"kind: ResourceGraphDefinition\n"+
+		"metadata:\n"+
+		"  name: vpc\n"+
+		"spec:\n"+
+		"  schema:\n"+
+		"    apiVersion: v1alpha1\n"+
+		"    kind: VPCModule\n"+
+		"  resources:\n"+
+		"    - id: vpc\n"+
+		"      template:\n"+
+		"        kind: VPC\n"+
+		"        spec: {}\n", string(out))
+}
+
+func TestFormat_KeepsUnknownKeyOrder(t *testing.T) {
+	in := "zeta: 1\nkind: A\nalpha: 2\n"
+
+	out, err := rgd.Format([]byte(in))
+	require.NoError(t, err)
+	assert.Equal(t, "kind: A\nzeta: 1\nalpha: 2\n", string(out))
+}
+
+func TestFormat_NormalizesCEL(t *testing.T) {
+	in := "a: ${ schema.spec.name }-vpc\n" +
+		"b: \"${  {'k': 'v}'}.k }\"\n" +
+		"c: \"prefix ${x} and ${ y }\"\n"
+
+	out, err := rgd.Format([]byte(in))
+	require.NoError(t, err)
+	assert.Equal(t, "a: ${schema.spec.name}-vpc\n"+
+		"b: \"${{'k': 'v}'}.k}\"\n"+
+		"c: \"prefix ${x} and ${y}\"\n", string(out))
+}
+
+func TestFormat_Indentation(t *testing.T) {
+	in := "metadata:\n    name: vpc\n    labels:\n        team: net"
+
+	out, err := rgd.Format([]byte(in))
+	require.NoError(t, err)
+	assert.Equal(t, "metadata:\n  name: vpc\n  labels:\n    team: net\n", string(out))
+}
+
+func TestFormat_Idempotent(t *testing.T) {
+	in := "apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\n---\nkind: B\n"
+
+	once, err := rgd.Format([]byte(in))
+	require.NoError(t, err)
+	twice, err := rgd.Format(once)
+	require.NoError(t, err)
+	assert.Equal(t, string(once), string(twice))
+}
+
+func TestFormat_InvalidYAML(t *testing.T) {
+	_, err := rgd.Format([]byte("a: [1, 2"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package rgd
+
+import (
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImageRef is a container image referenced in a resource template.
+type ImageRef struct {
+	// RGD and Resource name the RGD and the resource ID of the template
+	RGD      string
+	Resource string
+	// Node is the scalar holding the image, so it can be rewritten in place
+	Node *yaml.Node
+}
+
+// Image returns the image reference as written in the template.
+func (r ImageRef) Image() string {
+	return r.Node.Value
+}
+
+// Images returns the container images referenced in the resource templates
+// of the RGD, in the order they appear: the value of every image key, as
+// in the containers of a pod template. Images computed by an expression,
+// such as ${schema.spec.image}, are only known per instance and left out.
+func Images(r *ResourceGraphDefinition) []ImageRef {
+	var refs []ImageRef
+	for _, res := range r.Spec.Resources {
+		walkImages(&res.Template, func(n *yaml.Node) {
+			refs = append(refs, ImageRef{RGD: r.Metadata.Name, Resource: res.ID, Node: n})
+		})
+	}
+	return refs
+}
+
+func walkImages(n *yaml.Node, fn func(*yaml.Node)) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Value == "image" && value.Kind == yaml.ScalarNode {
+				if value.Value != "" && !strings.Contains(value.Value, "${") {
+					fn(value)
+				}
+				continue
+			}
+			walkImages(value, fn)
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			walkImages(c, fn)
+		}
+	}
+}
+
+// uniqueImages returns the distinct images of the RGDs, sorted.
+func uniqueImages(rgds []*ResourceGraphDefinition) []string {
+	var images []string
+	for _, r := range rgds {
+		for _, ref := range Images(r) {
+			images = append(images, ref.Image())
+		}
+	}
+	slices.Sort(images)
+	return slices.Compact(images)
+}
+
+// PinImages rewrites the images at refs in data, the file they were parsed
+// from, to their value in pinned. Images missing from pinned are left
+// alone. Only the image values change, the file keeps its formatting and
+// comments.
+func PinImages(data []byte, refs []ImageRef, pinned map[string]string) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	for _, ref := range refs {
+		to, ok := pinned[ref.Image()]
+		if !ok || ref.Node.Line < 1 || ref.Node.Line > len(lines) {
+			continue
+		}
+		line := lines[ref.Node.Line-1]
+		start := ref.Node.Column - 1
+		if ref.Node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			start++
+		}
+		if start < 0 || start > len(line) || !strings.HasPrefix(line[start:], ref.Image()) {
+			continue
+		}
+		lines[ref.Node.Line-1] = line[:start] + to + line[start+len(ref.Image()):]
+	}
+	return []byte(strings.Join(lines, ""))
+}
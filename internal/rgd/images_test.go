@@ -0,0 +1,100 @@
+package rgd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+const imagesRGD = `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: webapp
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: WebApp
+    spec:
+      image: string | default=nginx
+  resources:
+    - id: deployment
+      template:
+        apiVersion: apps/v1
+        kind: Deployment
+        spec:
+          template:
+            spec:
+              initContainers:
+                - name: migrate
+                  image: ghcr.io/acme/migrate:v1
+              containers:
+                - name: app
+                  image: ${schema.spec.image}
+                - name: proxy
+                  image: envoyproxy/envoy:v1.30.1
+    - id: job
+      template:
+        apiVersion: batch/v1
+        kind: Job
+        spec:
+          template:
+            spec:
+              containers:
+                - name: migrate
+                  image: ghcr.io/acme/migrate:v1
+`
+
+func TestImages(t *testing.T) {
+	rgds, err := rgd.Parse("webapp.yaml", []byte(imagesRGD))
+	require.NoError(t, err)
+
+	refs := rgd.Images(rgds[0])
+	require.Len(t, refs, 3)
+	assert.Equal(t, "ghcr.io/acme/migrate:v1", refs[0].Image())
+	assert.Equal(t, "deployment", refs[0].Resource)
+	assert.Equal(t, 21, refs[0].Node.Line)
+	assert.Equal(t, "envoyproxy/envoy:v1.30.1", refs[1].Image())
+	assert.Equal(t, "job", refs[2].Resource)
+
+	assert.Equal(t, []string{"envoyproxy/envoy:v1.30.1", "ghcr.io/acme/migrate:v1"}, rgd.Summarize(rgds).Images)
+}
+
+func TestPinImages(t *testing.T) {
+	data := "# pinned by kroctl images --pin\n" + imagesRGD + "---\n" +
+		"apiVersion: kro.run/v1alpha1\n" +
+		"kind: ResourceGraphDefinition\n" +
+		"metadata:\n" +
+		"  name: cache\n" +
+		"spec:\n" +
+		"  schema:\n" +
+		"    apiVersion: v1alpha1\n" +
+		"    kind: Cache\n" +
+		"  resources:\n" +
+		"    - id: redis\n" +
+		"      template:\n" +
+		"        apiVersion: v1\n" +
+		"        kind: Pod\n" +
+		"        spec:\n" +
+		"          containers:\n" +
+		"            - image: \"redis:7\" # cache\n"
+
+	rgds, err := rgd.Parse("stack.yaml", []byte(data))
+	require.NoError(t, err)
+	var refs []rgd.ImageRef
+	for _, r := range rgds {
+		refs = append(refs, rgd.Images(r)...)
+	}
+
+	pinned := rgd.PinImages([]byte(data), refs, map[string]string{
+		"ghcr.io/acme/migrate:v1": "ghcr.io/acme/migrate:v1@sha256:aaa",
+		"redis:7":                 "redis:7@sha256:bbb",
+	})
+
+	want := strings.ReplaceAll(data, "image: ghcr.io/acme/migrate:v1\n", "image: ghcr.io/acme/migrate:v1@sha256:aaa\n")
+	want = strings.Replace(want, `"redis:7" # cache`, `"redis:7@sha256:bbb" # cache`, 1)
+	assert.Equal(t, want, string(pinned))
+}
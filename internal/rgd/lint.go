@@ -0,0 +1,366 @@
+package rgd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaRef is the root identifier CEL expressions use to refer to the
+// instance, as in ${schema.spec.name}.
+const SchemaRef = "schema"
+
+// linter collects the diagnostics of a single ResourceGraphDefinition.
+type linter struct {
+	file  string
+	rgd   *ResourceGraphDefinition
+	diags Diagnostics
+}
+
+// LintOptions configures the checks Lint runs.
+type LintOptions struct {
+	// KubernetesVersion is the major.minor version of the cluster the RGDs
+	// target. Templates using APIs removed in that version are errors.
+	KubernetesVersion string
+}
+
+// Lint checks a parsed ResourceGraphDefinition for problems that kro would
+// otherwise only report once the RGD is reconciled in a cluster.
+func Lint(filename string, r *ResourceGraphDefinition, opts LintOptions) Diagnostics {
+	l := &linter{file: filename, rgd: r}
+	l.lintSchema()
+	l.lintResources()
+	l.lintDeprecations(opts.KubernetesVersion)
+
+	slices.SortStableFunc(l.diags, func(a, b Diagnostic) int {
+		return a.Line - b.Line
+	})
+	return l.diags
+}
+
+func (l *linter) report(line int, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{
+		File:    l.file,
+		Line:    line,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// lintSchema checks the simpleSchema definitions of the instance API.
+func (l *linter) lintSchema() {
+	schema := l.rgd.Spec.Schema
+	if schema == nil {
+		l.report(0, "%s has no spec.schema", l.rgd.Metadata.Name)
+		return
+	}
+	if schema.Kind == "" {
+		l.report(0, "%s has no spec.schema.kind", l.rgd.Metadata.Name)
+	}
+	if schema.APIVersion == "" {
+		l.report(0, "%s has no spec.schema.apiVersion", l.rgd.Metadata.Name)
+	}
+
+	custom := schema.customTypes()
+
+	for _, section := range []*yaml.Node{&schema.Types, &schema.Spec} {
+		fields, errs := specFields(section, "")
+		for _, e := range errs {
+			l.report(e.Line, "%s", e.Message)
+		}
+		for _, f := range fields {
+			l.lintField(f, custom)
+		}
+	}
+
+	for _, f := range schema.StatusFields() {
+		exprs := Expressions(f.Expression)
+		if len(exprs) == 0 {
+			l.report(f.Line, "status field %s must be a ${...} expression", f.Path)
+			continue
+		}
+		for _, expr := range exprs {
+			for _, sel := range Selectors(expr) {
+				l.checkSelector(sel, f.Line, "status field "+f.Path)
+			}
+		}
+	}
+}
+
+// lintResources checks the resource graph: resource IDs must be unique,
+// expressions may only reference declared resources and schema fields, and
+// the dependencies between resources must not form a cycle.
+func (l *linter) lintResources() {
+	seen := map[string]bool{}
+	for _, r := range l.rgd.Spec.Resources {
+		switch {
+		case r.ID == "":
+			l.report(r.Template.Line, "resource without an id")
+		case seen[r.ID]:
+			l.report(r.Template.Line, "duplicate resource id %q", r.ID)
+		}
+		seen[r.ID] = true
+	}
+
+	deps := map[string][]string{}
+	for _, r := range l.rgd.Spec.Resources {
+		walkExpressions(&r.Template, func(line int, expr string) {
+			for _, sel := range Selectors(expr) {
+				l.checkSelector(sel, line, "resource "+r.ID)
+				if seen[sel.Root] && !slices.Contains(deps[r.ID], sel.Root) {
+					deps[r.ID] = append(deps[r.ID], sel.Root)
+				}
+			}
+		})
+
+		// readyWhen judges the resource itself, includeWhen decides on the
+		// instance spec alone before any resource exists
+		for _, cond := range r.ReadyWhen {
+			for _, expr := range Expressions(cond) {
+				for _, ref := range References(expr) {
+					if ref != r.ID {
+						l.report(r.Template.Line, "readyWhen of resource %s may only reference %s, not %q", r.ID, r.ID, ref)
+					}
+				}
+			}
+		}
+		for _, cond := range r.IncludeWhen {
+			for _, expr := range Expressions(cond) {
+				for _, sel := range Selectors(expr) {
+					if sel.Root != SchemaRef {
+						l.report(r.Template.Line, "includeWhen of resource %s may only reference schema, not %q", r.ID, sel.Root)
+						continue
+					}
+					l.checkSelector(sel, r.Template.Line, "includeWhen of resource "+r.ID)
+				}
+			}
+		}
+	}
+
+	for _, cycle := range findCycles(l.rgd.Spec.Resources, deps) {
+		l.report(0, "dependency cycle between resources: %s", strings.Join(cycle, " -> "))
+	}
+}
+
+// checkSelector reports selectors rooted at unknown resources, and schema
+// selectors for spec fields that the schema does not declare.
+func (l *linter) checkSelector(sel Selector, line int, context string) {
+	if sel.Root == SchemaRef {
+		if len(sel.Fields) > 1 && sel.Fields[0] == "spec" && l.rgd.Spec.Schema != nil &&
+			!hasSchemaPath(&l.rgd.Spec.Schema.Spec, sel.Fields[1:]) {
+			l.report(line, "%s references %s, which is not declared in the schema", context, sel)
+		}
+		return
+	}
+
+	for _, r := range l.rgd.Spec.Resources {
+		if r.ID == sel.Root {
+			return
+		}
+	}
+	l.report(line, "%s references unknown resource %q", context, sel.Root)
+}
+
+// hasSchemaPath reports whether the simpleSchema spec declares the field at
+// path. Fields below a typed field, such as a map or custom type, cannot be
+// derived and are assumed to exist.
+func hasSchemaPath(n *yaml.Node, path []string) bool {
+	for _, field := range path {
+		if n.Kind != yaml.MappingNode {
+			return true
+		}
+		if n = mappingValue(n, field); n == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// walkExpressions calls fn for every ${...} expression in the string
+// scalars below n.
+func walkExpressions(n *yaml.Node, fn func(line int, expr string)) {
+	if n.Kind == yaml.ScalarNode {
+		for _, expr := range Expressions(n.Value) {
+			fn(n.Line, expr)
+		}
+		return
+	}
+	for _, c := range n.Content {
+		walkExpressions(c, fn)
+	}
+}
+
+// findCycles returns the dependency cycles in the resource graph, each as
+// the list of resource IDs along the cycle ending where it started.
+func findCycles(resources []*Resource, deps map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	state := map[string]int{}
+	var stack []string
+	var cycles [][]string
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range deps[id] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := slices.Index(stack, dep)
+				cycle := append(slices.Clone(stack[start:]), dep)
+				cycles = append(cycles, cycle)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+
+	for _, r := range resources {
+		if state[r.ID] == unvisited {
+			visit(r.ID)
+		}
+	}
+	return cycles
+}
+
+// lintField checks the type and markers of a single simpleSchema field.
+func (l *linter) lintField(f Field, custom map[string]bool) {
+	if f.Type == TypeObject && f.Markers == nil {
+		// Nested mappings have no definition of their own to check
+		return
+	}
+
+	if err := checkType(f.Type, custom); err != nil {
+		l.report(f.Line, "field %s: %s", f.Path, err)
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(f.Markers)) {
+		value := f.Markers[name]
+
+		kind, ok := markerKinds[name]
+		if !ok {
+			if suggestion := closest(name, slices.Sorted(maps.Keys(markerKinds))); suggestion != "" {
+				l.report(f.Line, "field %s: unknown marker %q, did you mean %q?", f.Path, name, suggestion)
+			} else {
+				l.report(f.Line, "field %s: unknown marker %q", f.Path, name)
+			}
+			continue
+		}
+
+		var err error
+		switch kind {
+		case "default":
+			err = checkValue(f.Type, value)
+		case "boolean", "integer", "number":
+			err = checkValue(kind, value)
+		}
+		if err != nil {
+			l.report(f.Line, "field %s: marker %s: %s", f.Path, name, err)
+		}
+	}
+
+	if enum, ok := f.Markers["enum"]; ok {
+		values := strings.Split(enum, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+			if err := checkValue(f.Type, values[i]); err != nil {
+				l.report(f.Line, "field %s: enum value %q: %s", f.Path, values[i], err)
+			}
+		}
+		if def, ok := f.Default(); ok && !slices.Contains(values, def) {
+			l.report(f.Line, "field %s: default %q is not one of the enum values", f.Path, def)
+		}
+	}
+}
+
+// checkType validates simpleSchema type syntax: atomic types, custom types,
+// arrays written as []T, and maps written as map[string]T.
+func checkType(typ string, custom map[string]bool) error {
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return checkType(typ[2:], custom)
+	case strings.HasPrefix(typ, "map["):
+		key, value, ok := strings.Cut(typ[len("map["):], "]")
+		if !ok {
+			return fmt.Errorf("invalid map type %q", typ)
+		}
+		if key != "string" {
+			return fmt.Errorf("invalid map type %q, keys must be strings", typ)
+		}
+		return checkType(value, custom)
+	case atomicTypes[typ] || custom[typ]:
+		return nil
+	default:
+		return fmt.Errorf("unknown type %q", typ)
+	}
+}
+
+// checkValue reports whether value is a valid literal of the given type.
+// Custom and object types accept any value.
+func checkValue(typ, value string) error {
+	var err error
+	switch {
+	case typ == "integer":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case typ == "number" || typ == "float":
+		_, err = strconv.ParseFloat(value, 64)
+	case typ == "boolean":
+		if value != "true" && value != "false" {
+			err = fmt.Errorf("invalid syntax")
+		}
+	case strings.HasPrefix(typ, "[]"):
+		var v []any
+		err = yaml.Unmarshal([]byte(value), &v)
+	case strings.HasPrefix(typ, "map["):
+		var v map[string]any
+		err = yaml.Unmarshal([]byte(value), &v)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a valid %s", value, typ)
+	}
+	return nil
+}
+
+// closest returns the candidate within an edit distance of two of s, or an
+// empty string when nothing is close enough to be a likely typo.
+func closest(s string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, c := range candidates {
+		if d := levenshtein(strings.ToLower(s), strings.ToLower(c)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
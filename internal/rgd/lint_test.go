@@ -0,0 +1,235 @@
+package rgd_test
+
+import (
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lint(t *testing.T, schema string) []string {
+	t.Helper()
+	data := "apiVersion: kro.run/v1alpha1\n" +
+		"kind: ResourceGraphDefinition\n" +
+		"metadata:\n" +
+		"  name: app\n" +
+		"spec:\n" +
+		"  schema:\n" +
+		"    apiVersion: v1alpha1\n" +
+		"    kind: App\n" +
+		schema +
+		"  resources:\n" +
+		"    - id: deployment\n" +
+		"      template: {}\n"
+
+	rgds, err := rgd.Parse("rgd.yaml", []byte(data))
+	require.NoError(t, err)
+	require.Len(t, rgds, 1)
+
+	var messages []string
+	for _, d := range rgd.Lint("rgd.yaml", rgds[0], rgd.LintOptions{}) {
+		messages = append(messages, d.Message)
+	}
+	return messages
+}
+
+func TestLint_ValidSchema(t *testing.T) {
+	messages := lint(t, ""+
+		"    types:\n"+
+		"      Port:\n"+
+		"        number: integer | required=true\n"+
+		"    spec:\n"+
+		"      name: string | required=true description=\"The app name\"\n"+
+		"      replicas: integer | default=3 minimum=1 maximum=10\n"+
+		"      ratio: float | default=0.5\n"+
+		"      tags: \"[]string | default=[\\\"a\\\"]\"\n"+
+		"      labels: map[string]string\n"+
+		"      ports: \"[]Port\"\n"+
+		"      mode: string | enum=\"fast,slow\" default=fast\n"+
+		"      ingress:\n"+
+		"        enabled: boolean | default=false\n"+
+		"    status:\n"+
+		"      ready: ${deployment.status.readyReplicas > 0}\n"+
+		"      name: ${schema.spec.name}\n")
+
+	assert.Empty(t, messages)
+}
+
+func TestLint_InvalidType(t *testing.T) {
+	messages := lint(t, "    spec:\n      name: strng\n      labels: map[int]string\n")
+
+	assert.Equal(t, []string{
+		`field name: unknown type "strng"`,
+		`field labels: invalid map type "map[int]string", keys must be strings`,
+	}, messages)
+}
+
+func TestLint_DefaultTypeMismatch(t *testing.T) {
+	messages := lint(t, "    spec:\n      replicas: integer | default=three\n      enabled: boolean | default=yes\n")
+
+	assert.Equal(t, []string{
+		`field replicas: marker default: "three" is not a valid integer`,
+		`field enabled: marker default: "yes" is not a valid boolean`,
+	}, messages)
+}
+
+func TestLint_MisspelledMarker(t *testing.T) {
+	messages := lint(t, "    spec:\n      name: string | requird=true\n")
+
+	assert.Equal(t, []string{`field name: unknown marker "requird", did you mean "required"?`}, messages)
+}
+
+func TestLint_MarkerWithoutValue(t *testing.T) {
+	messages := lint(t, "    spec:\n      name: string | required\n")
+
+	assert.Equal(t, []string{`field name: marker "required" has no value`}, messages)
+}
+
+func TestLint_DefaultNotInEnum(t *testing.T) {
+	messages := lint(t, "    spec:\n      mode: string | enum=\"a,b\" default=c\n")
+
+	assert.Equal(t, []string{`field mode: default "c" is not one of the enum values`}, messages)
+}
+
+func TestLint_StatusReferencesUnknownResource(t *testing.T) {
+	messages := lint(t, "    status:\n      id: ${vpc.status.id}\n      plain: hello\n")
+
+	assert.Equal(t, []string{
+		`status field id references unknown resource "vpc"`,
+		`status field plain must be a ${...} expression`,
+	}, messages)
+}
+
+func TestSchema_SpecFields(t *testing.T) {
+	data := "apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\nmetadata:\n  name: app\n" +
+		"spec:\n  schema:\n    apiVersion: v1alpha1\n    kind: App\n    spec:\n" +
+		"      name: string | required=true\n" +
+		"      ingress:\n" +
+		"        host: string | default=\"example.com\"\n"
+
+	rgds, err := rgd.Parse("rgd.yaml", []byte(data))
+	require.NoError(t, err)
+
+	fields := rgds[0].Spec.Schema.SpecFields()
+	require.Len(t, fields, 3)
+	assert.Equal(t, "name", fields[0].Path)
+	assert.True(t, fields[0].Required())
+	assert.Equal(t, "ingress", fields[1].Path)
+	assert.Equal(t, rgd.TypeObject, fields[1].Type)
+	assert.Equal(t, "ingress.host", fields[2].Path)
+	def, ok := fields[2].Default()
+	assert.True(t, ok)
+	assert.Equal(t, "example.com", def)
+}
+
+func lintResources(t *testing.T, resources string) []string {
+	t.Helper()
+	data := "apiVersion: kro.run/v1alpha1\n" +
+		"kind: ResourceGraphDefinition\n" +
+		"metadata:\n" +
+		"  name: app\n" +
+		"spec:\n" +
+		"  schema:\n" +
+		"    apiVersion: v1alpha1\n" +
+		"    kind: App\n" +
+		"    spec:\n" +
+		"      name: string\n" +
+		"      labels: map[string]string\n" +
+		"  resources:\n" +
+		resources
+
+	rgds, err := rgd.Parse("rgd.yaml", []byte(data))
+	require.NoError(t, err)
+	require.Len(t, rgds, 1)
+
+	var messages []string
+	for _, d := range rgd.Lint("rgd.yaml", rgds[0], rgd.LintOptions{}) {
+		messages = append(messages, d.Message)
+	}
+	return messages
+}
+
+func TestLint_ResourceReferences(t *testing.T) {
+	messages := lintResources(t, ""+
+		"    - id: vpc\n"+
+		"      template:\n"+
+		"        metadata:\n"+
+		"          name: ${schema.spec.name}\n"+
+		"          labels: ${schema.spec.labels.team}\n"+
+		"    - id: subnet\n"+
+		"      readyWhen:\n"+
+		"        - ${subnet.status.state == 'available'}\n"+
+		"      template:\n"+
+		"        spec:\n"+
+		"          vpcID: ${vpc.status.vpcID}\n")
+
+	assert.Empty(t, messages)
+}
+
+func TestLint_ExtensionFunctions(t *testing.T) {
+	messages := lintResources(t, ""+
+		"    - id: cm\n"+
+		"      template:\n"+
+		"        data:\n"+
+		"          secret: ${base64.encode(bytes(schema.spec.name))}\n"+
+		"          upper: ${strings.quote(schema.spec.name)}\n"+
+		"          count: ${string(math.greatest([1, 2]))}\n"+
+		"          zones: ${lists.range(3).map(i, string(i))}\n"+
+		"          same: ${sets.equivalent([1], [1])}\n")
+
+	assert.Empty(t, messages)
+}
+
+func TestLint_UndefinedReferences(t *testing.T) {
+	messages := lintResources(t, ""+
+		"    - id: subnet\n"+
+		"      template:\n"+
+		"        spec:\n"+
+		"          vpcID: ${vpc.status.vpcID}\n"+
+		"          name: ${schema.spec.nmae}\n")
+
+	assert.Equal(t, []string{
+		`resource subnet references unknown resource "vpc"`,
+		`resource subnet references schema.spec.nmae, which is not declared in the schema`,
+	}, messages)
+}
+
+func TestLint_DependencyCycle(t *testing.T) {
+	messages := lintResources(t, ""+
+		"    - id: a\n"+
+		"      template:\n"+
+		"        spec: ${b.status.id}\n"+
+		"    - id: b\n"+
+		"      template:\n"+
+		"        spec: ${a.status.id}\n")
+
+	assert.Equal(t, []string{`dependency cycle between resources: a -> b -> a`}, messages)
+}
+
+func TestLint_DuplicateResourceID(t *testing.T) {
+	messages := lintResources(t, ""+
+		"    - id: a\n"+
+		"      template: {}\n"+
+		"    - id: a\n"+
+		"      template: {}\n")
+
+	assert.Equal(t, []string{`duplicate resource id "a"`}, messages)
+}
+
+func TestLint_ConditionReferences(t *testing.T) {
+	messages := lintResources(t, ""+
+		"    - id: a\n"+
+		"      template: {}\n"+
+		"    - id: b\n"+
+		"      readyWhen:\n"+
+		"        - ${a.status.ready}\n"+
+		"      includeWhen:\n"+
+		"        - ${a.status.ready}\n"+
+		"      template: {}\n")
+
+	assert.Equal(t, []string{
+		`readyWhen of resource b may only reference b, not "a"`,
+		`includeWhen of resource b may only reference schema, not "a"`,
+	}, messages)
+}
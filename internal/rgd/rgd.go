@@ -0,0 +1,244 @@
+package rgd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Kind is the kind of a ResourceGraphDefinition object
+	Kind = "ResourceGraphDefinition"
+	// Group is the API group kro serves ResourceGraphDefinitions from
+	Group = "kro.run"
+)
+
+// ResourceGraphDefinition mirrors the fields of a kro ResourceGraphDefinition
+// that kroctl understands. Free-form sections such as the schema and the
+// resource templates are kept as YAML nodes so positions are preserved.
+type ResourceGraphDefinition struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   ObjectMeta `yaml:"metadata"`
+	Spec       Spec       `yaml:"spec"`
+	Status     yaml.Node  `yaml:"status,omitempty"`
+}
+
+// ObjectMeta is the Kubernetes object metadata of an RGD. Fields the server
+// sets are accepted so RGDs read back from a cluster parse, but kroctl only
+// looks at the name, labels, and annotations.
+type ObjectMeta struct {
+	Name                       string            `yaml:"name"`
+	GenerateName               string            `yaml:"generateName,omitempty"`
+	Namespace                  string            `yaml:"namespace,omitempty"`
+	Labels                     map[string]string `yaml:"labels,omitempty"`
+	Annotations                map[string]string `yaml:"annotations,omitempty"`
+	Finalizers                 []string          `yaml:"finalizers,omitempty"`
+	OwnerReferences            yaml.Node         `yaml:"ownerReferences,omitempty"`
+	UID                        string            `yaml:"uid,omitempty"`
+	ResourceVersion            string            `yaml:"resourceVersion,omitempty"`
+	Generation                 int64             `yaml:"generation,omitempty"`
+	SelfLink                   string            `yaml:"selfLink,omitempty"`
+	CreationTimestamp          string            `yaml:"creationTimestamp,omitempty"`
+	DeletionTimestamp          string            `yaml:"deletionTimestamp,omitempty"`
+	DeletionGracePeriodSeconds *int64            `yaml:"deletionGracePeriodSeconds,omitempty"`
+	ManagedFields              yaml.Node         `yaml:"managedFields,omitempty"`
+}
+
+// Spec is the specification of a ResourceGraphDefinition.
+type Spec struct {
+	Schema                 *Schema           `yaml:"schema"`
+	Resources              []*Resource       `yaml:"resources,omitempty"`
+	DefaultServiceAccounts map[string]string `yaml:"defaultServiceAccounts,omitempty"`
+}
+
+// Schema defines the API of the instances created from the RGD, written in
+// kro's simpleSchema syntax.
+type Schema struct {
+	APIVersion               string          `yaml:"apiVersion"`
+	Kind                     string          `yaml:"kind"`
+	Group                    string          `yaml:"group,omitempty"`
+	Spec                     yaml.Node       `yaml:"spec,omitempty"`
+	Status                   yaml.Node       `yaml:"status,omitempty"`
+	Types                    yaml.Node       `yaml:"types,omitempty"`
+	Validation               []Validation    `yaml:"validation,omitempty"`
+	AdditionalPrinterColumns []PrinterColumn `yaml:"additionalPrinterColumns,omitempty"`
+}
+
+// Validation is a CEL validation rule applied to instances.
+type Validation struct {
+	Expression string `yaml:"expression"`
+	Message    string `yaml:"message,omitempty"`
+}
+
+// PrinterColumn is an additional column shown by kubectl get for instances.
+type PrinterColumn struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	JSONPath    string `yaml:"jsonPath"`
+	Description string `yaml:"description,omitempty"`
+	Format      string `yaml:"format,omitempty"`
+	Priority    int    `yaml:"priority,omitempty"`
+}
+
+// Resource is a single node in the resource graph.
+type Resource struct {
+	ID          string       `yaml:"id"`
+	Template    yaml.Node    `yaml:"template,omitempty"`
+	ExternalRef *ExternalRef `yaml:"externalRef,omitempty"`
+	ReadyWhen   []string     `yaml:"readyWhen,omitempty"`
+	IncludeWhen []string     `yaml:"includeWhen,omitempty"`
+}
+
+// ExternalRef points at an existing object instead of templating a new one.
+type ExternalRef struct {
+	APIVersion string          `yaml:"apiVersion"`
+	Kind       string          `yaml:"kind"`
+	Metadata   ExternalRefMeta `yaml:"metadata"`
+}
+
+// ExternalRefMeta identifies the object an ExternalRef points at.
+type ExternalRefMeta struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// Parse strictly decodes the ResourceGraphDefinitions in a YAML file. Tab
+// indentation, duplicate mapping keys, unknown fields, and objects that are
+// not ResourceGraphDefinitions are rejected. Problems are returned as
+// Diagnostics positioned at the offending line of filename.
+func Parse(filename string, data []byte) ([]*ResourceGraphDefinition, error) {
+	// Both decoders walk the same stream in lockstep: the node decoder
+	// positions each document while the strict decoder fills in the types.
+	// Node.Decode cannot reject unknown fields, so one decoder is not enough.
+	nodes := yaml.NewDecoder(bytes.NewReader(data))
+	strict := yaml.NewDecoder(bytes.NewReader(data))
+	strict.KnownFields(true)
+
+	var rgds []*ResourceGraphDefinition
+	var diags Diagnostics
+	for {
+		var doc yaml.Node
+		if err := nodes.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// Syntax errors leave the decoders in an unusable state
+			return nil, append(diags, explainTabs(data, diagnosticsFromYAML(filename, err))...)
+		}
+
+		var rgd ResourceGraphDefinition
+		if err := strict.Decode(&rgd); err != nil {
+			diags = append(diags, diagnosticsFromYAML(filename, err)...)
+			continue
+		}
+
+		// Skip empty documents, such as a trailing document separator
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		// The strict decoder only sees the keys of typed fields, the
+		// schema and templates are kept as nodes
+		if dups := duplicateKeys(filename, &doc); len(dups) > 0 {
+			diags = append(diags, dups...)
+			continue
+		}
+
+		if rgd.Kind != Kind || !strings.HasPrefix(rgd.APIVersion, Group+"/") {
+			diags = append(diags, Diagnostic{
+				File:    filename,
+				Line:    doc.Line,
+				Message: fmt.Sprintf("expected %s/* %s, got %s %s", Group, Kind, rgd.APIVersion, rgd.Kind),
+			})
+			continue
+		}
+
+		rgds = append(rgds, &rgd)
+	}
+
+	if len(diags) > 0 {
+		return nil, diags
+	}
+
+	return rgds, nil
+}
+
+// Documents splits a YAML stream into the text of its documents, as
+// written. The document separators themselves are dropped.
+func Documents(data []byte) [][]byte {
+	var docs [][]byte
+	start := 0
+	for offset := 0; offset < len(data); {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += offset + 1
+		}
+
+		line := strings.TrimRight(string(data[offset:end]), "\r\n")
+		if line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t") {
+			docs = append(docs, data[start:offset])
+			start = end
+		}
+		offset = end
+	}
+	return append(docs, data[start:])
+}
+
+// explainTabs rewords syntax errors caused by tab indentation, which YAML
+// forbids but only reports as an obscure scanner error. The scanner points
+// at the offending line or the one before it. Tabs in block scalars are
+// content and parse fine, so they never get here.
+func explainTabs(data []byte, diags Diagnostics) Diagnostics {
+	lines := strings.Split(string(data), "\n")
+	for i, diag := range diags {
+		for _, n := range []int{diag.Line, diag.Line + 1} {
+			if n < 1 || n > len(lines) {
+				continue
+			}
+			line := lines[n-1]
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			if strings.Contains(indent, "\t") {
+				diags[i].Line = n
+				diags[i].Message = "tab character used for indentation"
+				break
+			}
+		}
+	}
+	return diags
+}
+
+// duplicateKeys reports the keys that are repeated within a mapping of
+// node or of any node below it.
+func duplicateKeys(filename string, node *yaml.Node) Diagnostics {
+	var diags Diagnostics
+	if node.Kind == yaml.MappingNode {
+		seen := map[string]int{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			// Merge keys may repeat, and complex keys are left alone
+			if key.Kind != yaml.ScalarNode || key.Tag == "!!merge" {
+				continue
+			}
+			if line, ok := seen[key.Value]; ok {
+				diags = append(diags, Diagnostic{
+					File:    filename,
+					Line:    key.Line,
+					Message: fmt.Sprintf("mapping key %q already defined at line %d", key.Value, line),
+				})
+				continue
+			}
+			seen[key.Value] = key.Line
+		}
+	}
+	for _, child := range node.Content {
+		diags = append(diags, duplicateKeys(filename, child)...)
+	}
+	return diags
+}
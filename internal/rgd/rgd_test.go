@@ -0,0 +1,179 @@
+package rgd_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseDiagnostics(t *testing.T, data string) rgd.Diagnostics {
+	t.Helper()
+	_, err := rgd.Parse("rgd.yaml", []byte(data))
+	var diags rgd.Diagnostics
+	require.True(t, errors.As(err, &diags), "expected diagnostics, got %v", err)
+	return diags
+}
+
+func TestParse_Assets(t *testing.T) {
+	files, err := filepath.Glob("../../assets/stacks/network/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, filename := range files {
+		data, err := os.ReadFile(filename)
+		require.NoError(t, err)
+
+		rgds, err := rgd.Parse(filename, data)
+		require.NoError(t, err, filename)
+		require.Len(t, rgds, 1)
+		assert.NotEmpty(t, rgds[0].Metadata.Name)
+		assert.NotNil(t, rgds[0].Spec.Schema)
+	}
+}
+
+func TestParse_MultipleDocuments(t *testing.T) {
+	data := "apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\nmetadata:\n  name: a\n" +
+		"---\n" +
+		"apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\nmetadata:\n  name: b\n" +
+		"---\n"
+
+	rgds, err := rgd.Parse("rgd.yaml", []byte(data))
+	require.NoError(t, err)
+	require.Len(t, rgds, 2)
+	assert.Equal(t, "a", rgds[0].Metadata.Name)
+	assert.Equal(t, "b", rgds[1].Metadata.Name)
+}
+
+func TestDocuments(t *testing.T) {
+	data := "# network\napiVersion: v1\n---\nkind: a\n--- # second\nkind: b\n---\n"
+
+	docs := rgd.Documents([]byte(data))
+	require.Len(t, docs, 4)
+	assert.Equal(t, "# network\napiVersion: v1\n", string(docs[0]))
+	assert.Equal(t, "kind: a\n", string(docs[1]))
+	assert.Equal(t, "kind: b\n", string(docs[2]))
+	assert.Empty(t, docs[3])
+}
+
+func TestParse_DuplicateKey(t *testing.T) {
+	diags := parseDiagnostics(t, "apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\n"+
+		"metadata:\n  name: a\n  name: b\n")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, 5, diags[0].Line)
+	assert.Contains(t, diags[0].Message, `mapping key "name" already defined`)
+}
+
+func TestParse_DuplicateKeyInSchema(t *testing.T) {
+	diags := parseDiagnostics(t, `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: app
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: App
+    spec:
+      name: string
+      name: integer
+`)
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, "rgd.yaml:11: mapping key \"name\" already defined at line 10", diags[0].String())
+}
+
+func TestParse_DuplicateKeyInTemplate(t *testing.T) {
+	diags := parseDiagnostics(t, `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: app
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: App
+  resources:
+    - id: config
+      template:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: a
+          name: b
+        data:
+          key: value
+          key: other
+`)
+
+	require.Len(t, diags, 2)
+	assert.Equal(t, "rgd.yaml:16: mapping key \"name\" already defined at line 15", diags[0].String())
+	assert.Equal(t, "rgd.yaml:19: mapping key \"key\" already defined at line 18", diags[1].String())
+}
+
+func TestParse_UnknownField(t *testing.T) {
+	diags := parseDiagnostics(t, "apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\n"+
+		"spec:\n  resources:\n    - id: vpc\n      templte: {}\n")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, "rgd.yaml:6: unknown field \"templte\"", diags[0].String())
+}
+
+func TestParse_TabIndentation(t *testing.T) {
+	diags := parseDiagnostics(t, "metadata:\n\tname: a\n")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, 2, diags[0].Line)
+	assert.Equal(t, "tab character used for indentation", diags[0].Message)
+}
+
+func TestParse_TabIndentationNested(t *testing.T) {
+	diags := parseDiagnostics(t, "metadata:\n  name: a\n\tlabels: {}\n")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, "tab character used for indentation", diags[0].Message)
+}
+
+func TestParse_TabInBlockScalar(t *testing.T) {
+	data := "apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\nmetadata:\n  name: a\n" +
+		"spec:\n  schema:\n    apiVersion: v1alpha1\n    kind: A\n" +
+		"  resources:\n  - id: script\n    template:\n      apiVersion: v1\n      kind: ConfigMap\n" +
+		"      data:\n        run.sh: |\n          if true; then\n          \techo ok\n          fi\n"
+
+	rgds, err := rgd.Parse("rgd.yaml", []byte(data))
+	require.NoError(t, err)
+	require.Len(t, rgds, 1)
+}
+
+func TestParse_ServerMetadata(t *testing.T) {
+	data := "apiVersion: kro.run/v1alpha1\nkind: ResourceGraphDefinition\nmetadata:\n  name: a\n" +
+		"  uid: 6f1c0c9e-2b1a-4a4e-9a55-0d6a8f1f3c11\n  resourceVersion: \"4711\"\n  generation: 3\n" +
+		"  creationTimestamp: 2026-01-02T03:04:05Z\n  finalizers:\n  - kro.run/finalizer\n" +
+		"  managedFields:\n  - manager: kubectl\n    operation: Apply\n" +
+		"spec:\n  schema:\n    apiVersion: v1alpha1\n    kind: A\n"
+
+	rgds, err := rgd.Parse("rgd.yaml", []byte(data))
+	require.NoError(t, err)
+	require.Len(t, rgds, 1)
+	assert.Equal(t, []string{"kro.run/finalizer"}, rgds[0].Metadata.Finalizers)
+	assert.Equal(t, int64(3), rgds[0].Metadata.Generation)
+}
+
+func TestParse_WrongKind(t *testing.T) {
+	diags := parseDiagnostics(t, "apiVersion: v1\nkind: ConfigMap\n")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Contains(t, diags[0].Message, "got v1 ConfigMap")
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	diags := parseDiagnostics(t, "a: [1\n")
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Line)
+}
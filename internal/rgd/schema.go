@@ -0,0 +1,216 @@
+package rgd
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypeObject is the type of a field that is declared as a nested mapping.
+const TypeObject = "object"
+
+// atomicTypes are the built-in simpleSchema types.
+var atomicTypes = map[string]bool{
+	"string":  true,
+	"integer": true,
+	"boolean": true,
+	"number":  true,
+	"float":   true,
+	"object":  true,
+}
+
+// markerKinds maps the known simpleSchema markers to the kind of value they take.
+var markerKinds = map[string]string{
+	"required":    "boolean",
+	"immutable":   "boolean",
+	"uniqueItems": "boolean",
+	"default":     "default",
+	"description": "string",
+	"enum":        "string",
+	"pattern":     "string",
+	"minimum":     "number",
+	"maximum":     "number",
+	"minLength":   "integer",
+	"maxLength":   "integer",
+	"minItems":    "integer",
+	"maxItems":    "integer",
+}
+
+// Field is a field of an instance API declared in simpleSchema syntax,
+// e.g. "replicas: integer | default=3 minimum=1".
+type Field struct {
+	// Path is the dotted path of the field below spec or status
+	Path string
+	// Type is the declared type, or TypeObject for nested mappings
+	Type string
+	// Markers holds the markers following the type, keyed by name
+	Markers map[string]string
+	// Line is the line the field is declared on
+	Line int
+}
+
+// Required reports whether the field is marked required=true.
+func (f Field) Required() bool {
+	return f.Markers["required"] == "true"
+}
+
+// Default returns the default value of the field, if it has one.
+func (f Field) Default() (string, bool) {
+	v, ok := f.Markers["default"]
+	return v, ok
+}
+
+// Description returns the description marker of the field.
+func (f Field) Description() string {
+	return f.Markers["description"]
+}
+
+// StatusField is a status field of an instance API, computed from a CEL
+// expression over the resources of the graph.
+type StatusField struct {
+	Path       string
+	Expression string
+	Line       int
+}
+
+// fieldError is a problem found while reading a simpleSchema definition.
+type fieldError struct {
+	Line    int
+	Message string
+}
+
+// SpecFields returns the fields declared in the spec of the schema, in
+// declaration order. Fields with an unparsable definition are skipped.
+func (s *Schema) SpecFields() []Field {
+	fields, _ := specFields(&s.Spec, "")
+	return fields
+}
+
+// StatusFields returns the status fields of the schema in declaration order.
+func (s *Schema) StatusFields() []StatusField {
+	var fields []StatusField
+	walkMapping(&s.Status, "", func(path string, value *yaml.Node) {
+		fields = append(fields, StatusField{Path: path, Expression: value.Value, Line: value.Line})
+	})
+	return fields
+}
+
+// specFields flattens a simpleSchema spec mapping into fields. Nested
+// mappings become fields of TypeObject followed by their own fields.
+func specFields(n *yaml.Node, prefix string) ([]Field, []fieldError) {
+	if n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var fields []Field
+	var errs []fieldError
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		path := joinPath(prefix, key.Value)
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			fields = append(fields, Field{Path: path, Type: TypeObject, Line: key.Line})
+			nested, nestedErrs := specFields(value, path)
+			fields = append(fields, nested...)
+			errs = append(errs, nestedErrs...)
+		case yaml.ScalarNode:
+			typ, markers, err := parseFieldDefinition(value.Value)
+			if err != nil {
+				errs = append(errs, fieldError{Line: value.Line, Message: fmt.Sprintf("field %s: %s", path, err)})
+				continue
+			}
+			fields = append(fields, Field{Path: path, Type: typ, Markers: markers, Line: value.Line})
+		default:
+			errs = append(errs, fieldError{Line: value.Line, Message: fmt.Sprintf("field %s: expected a type definition or a nested object", path)})
+		}
+	}
+	return fields, errs
+}
+
+// walkMapping calls fn for every scalar leaf of a nested mapping.
+func walkMapping(n *yaml.Node, prefix string, fn func(path string, value *yaml.Node)) {
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		path := joinPath(prefix, key.Value)
+		if value.Kind == yaml.MappingNode {
+			walkMapping(value, path, fn)
+			continue
+		}
+		fn(path, value)
+	}
+}
+
+// parseFieldDefinition splits a definition such as
+// `string | default="a b" required=true` into its type and markers.
+func parseFieldDefinition(def string) (string, map[string]string, error) {
+	typ, rest, _ := strings.Cut(def, "|")
+	typ = strings.TrimSpace(typ)
+	if typ == "" {
+		return "", nil, fmt.Errorf("missing type")
+	}
+
+	markers := map[string]string{}
+	rest = strings.TrimSpace(rest)
+	for rest != "" {
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok || strings.ContainsAny(name, " \t") {
+			word, _, _ := strings.Cut(rest, " ")
+			return "", nil, fmt.Errorf("marker %q has no value", word)
+		}
+
+		var err error
+		value, rest, err = cutMarkerValue(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("marker %s: %w", name, err)
+		}
+		if _, dup := markers[name]; dup {
+			return "", nil, fmt.Errorf("marker %s is set more than once", name)
+		}
+		markers[name] = value
+		rest = strings.TrimSpace(rest)
+	}
+
+	return typ, markers, nil
+}
+
+// cutMarkerValue reads a marker value from the start of s, which is either
+// a double quoted string or runs until the next space. It returns the value
+// and the remainder of s.
+func cutMarkerValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		value, rest, _ := strings.Cut(s, " ")
+		return value, rest, nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted value")
+}
+
+// customTypes returns the names of the types declared under schema.types.
+func (s *Schema) customTypes() map[string]bool {
+	types := map[string]bool{}
+	if s.Types.Kind == yaml.MappingNode {
+		for i := 0; i < len(s.Types.Content); i += 2 {
+			types[s.Types.Content[i].Value] = true
+		}
+	}
+	return types
+}
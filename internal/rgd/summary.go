@@ -0,0 +1,123 @@
+package rgd
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// KindCount is the number of resource templates of a Kubernetes kind.
+type KindCount struct {
+	Kind  string
+	Count int
+}
+
+// Summary describes what a set of ResourceGraphDefinitions will manage.
+type Summary struct {
+	// RGDs is the number of ResourceGraphDefinitions.
+	RGDs int
+	// APIs are the kinds the RGDs define, qualified by their group, such as
+	// Network.kro.run.
+	APIs []string
+	// Resources counts the resource templates per Kubernetes kind, most
+	// common first.
+	Resources []KindCount
+	// ExternalRefs is the number of existing objects the RGDs read from.
+	ExternalRefs int
+	// Images are the distinct container images the resource templates
+	// reference, sorted.
+	Images []string
+}
+
+// Summarize counts the APIs and resource kinds of the given RGDs and lists
+// the images they deploy.
+func Summarize(rgds []*ResourceGraphDefinition) Summary {
+	summary := Summary{RGDs: len(rgds), Images: uniqueImages(rgds)}
+
+	counts := map[string]int{}
+	for _, r := range rgds {
+		if s := r.Spec.Schema; s != nil && s.Kind != "" {
+			group := s.Group
+			if group == "" {
+				group = Group
+			}
+			summary.APIs = append(summary.APIs, s.Kind+"."+group)
+		}
+
+		for _, res := range r.Spec.Resources {
+			if res.ExternalRef != nil {
+				summary.ExternalRefs++
+				continue
+			}
+			kind := "unknown"
+			if n := mappingValue(&res.Template, "kind"); n != nil && n.Value != "" {
+				kind = n.Value
+			}
+			counts[kind]++
+		}
+	}
+
+	for kind, count := range counts {
+		summary.Resources = append(summary.Resources, KindCount{Kind: kind, Count: count})
+	}
+	slices.SortFunc(summary.Resources, func(a, b KindCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Kind, b.Kind)
+	})
+
+	return summary
+}
+
+// Dependencies returns the custom APIs the RGDs create or read resources of
+// without defining them, qualified by their group, such as
+// VPC.ec2.services.k8s.aws. Their CRDs must be installed before the RGDs
+// work. Built-in Kubernetes APIs are left out.
+func Dependencies(rgds []*ResourceGraphDefinition) []string {
+	defined := map[string]bool{}
+	for _, api := range Summarize(rgds).APIs {
+		defined[api] = true
+	}
+
+	seen := map[string]bool{}
+	var deps []string
+	add := func(apiVersion, kind string) {
+		group, _, ok := strings.Cut(apiVersion, "/")
+		if !ok || kind == "" || isBuiltinGroup(group) {
+			return
+		}
+		api := kind + "." + group
+		if defined[api] || seen[api] {
+			return
+		}
+		seen[api] = true
+		deps = append(deps, api)
+	}
+
+	for _, r := range rgds {
+		for _, res := range r.Spec.Resources {
+			if res.ExternalRef != nil {
+				add(res.ExternalRef.APIVersion, res.ExternalRef.Kind)
+				continue
+			}
+			var apiVersion, kind string
+			if n := mappingValue(&res.Template, "apiVersion"); n != nil {
+				apiVersion = n.Value
+			}
+			if n := mappingValue(&res.Template, "kind"); n != nil {
+				kind = n.Value
+			}
+			add(apiVersion, kind)
+		}
+	}
+
+	slices.Sort(deps)
+	return deps
+}
+
+// isBuiltinGroup reports whether an API group is served by Kubernetes
+// itself, such as apps or networking.k8s.io.
+func isBuiltinGroup(group string) bool {
+	return !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
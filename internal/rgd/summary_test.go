@@ -0,0 +1,97 @@
+package rgd_test
+
+import (
+	"testing"
+
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	data := deprecatedRGD + "    - id: service\n" +
+		"      template:\n" +
+		"        apiVersion: v1\n" +
+		"        kind: Service\n" +
+		"    - id: other\n" +
+		"      template:\n" +
+		"        apiVersion: apps/v1\n" +
+		"        kind: Deployment\n" +
+		"    - id: config\n" +
+		"      externalRef:\n" +
+		"        apiVersion: v1\n" +
+		"        kind: ConfigMap\n" +
+		"        metadata:\n" +
+		"          name: settings\n" +
+		"---\n" +
+		"apiVersion: kro.run/v1alpha1\n" +
+		"kind: ResourceGraphDefinition\n" +
+		"metadata:\n" +
+		"  name: network\n" +
+		"spec:\n" +
+		"  schema:\n" +
+		"    apiVersion: v1alpha1\n" +
+		"    kind: Network\n" +
+		"    group: acme.io\n"
+
+	rgds, err := rgd.Parse("stack.yaml", []byte(data))
+	require.NoError(t, err)
+
+	summary := rgd.Summarize(rgds)
+	assert.Equal(t, 2, summary.RGDs)
+	assert.Equal(t, []string{"App.kro.run", "Network.acme.io"}, summary.APIs)
+	assert.Equal(t, []rgd.KindCount{
+		{Kind: "Deployment", Count: 2},
+		{Kind: "PodDisruptionBudget", Count: 1},
+		{Kind: "Service", Count: 1},
+	}, summary.Resources)
+	assert.Equal(t, 1, summary.ExternalRefs)
+}
+
+func TestDependencies(t *testing.T) {
+	data := `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: network
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: Network
+    group: acme.io
+  resources:
+    - id: vpc
+      template:
+        apiVersion: ec2.services.k8s.aws/v1alpha1
+        kind: VPC
+    - id: subnet
+      template:
+        apiVersion: ec2.services.k8s.aws/v1alpha1
+        kind: Subnet
+    - id: policy
+      template:
+        apiVersion: networking.k8s.io/v1
+        kind: NetworkPolicy
+    - id: deployment
+      template:
+        apiVersion: apps/v1
+        kind: Deployment
+    - id: child
+      template:
+        apiVersion: acme.io/v1alpha1
+        kind: Network
+    - id: zone
+      externalRef:
+        apiVersion: route53.services.k8s.aws/v1alpha1
+        kind: HostedZone
+        metadata:
+          name: main
+`
+	rgds, err := rgd.Parse("network.yaml", []byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"HostedZone.route53.services.k8s.aws",
+		"Subnet.ec2.services.k8s.aws",
+		"VPC.ec2.services.k8s.aws",
+	}, rgd.Dependencies(rgds))
+}
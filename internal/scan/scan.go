@@ -0,0 +1,207 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Severity ranks how serious a vulnerability is.
+type Severity int
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// ParseSeverity reads a severity as Trivy and Grype report it, in any
+// case. Grype's Negligible counts as low.
+func ParseSeverity(s string) (Severity, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	if upper == "NEGLIGIBLE" {
+		return SeverityLow, nil
+	}
+	if i := slices.Index(severityNames, upper); i >= 0 {
+		return Severity(i), nil
+	}
+	return SeverityUnknown, fmt.Errorf("unknown severity %q, expected one of %s",
+		s, strings.ToLower(strings.Join(severityNames, ", ")))
+}
+
+// Finding is a vulnerability found in an image.
+type Finding struct {
+	ID       string
+	Package  string
+	Version  string
+	Severity Severity
+}
+
+// Report is what a scanner found in an image.
+type Report struct {
+	// Image is the image that was scanned, as the scanner reports it
+	Image    string
+	Findings []Finding
+}
+
+// Scanners are the scanners kroctl can run and read reports of.
+var Scanners = []string{"trivy", "grype"}
+
+// Run scans an image with the scanner, which must be installed.
+func Run(ctx context.Context, scanner, image string) (*Report, error) {
+	var args []string
+	switch scanner {
+	case "trivy":
+		args = []string{"image", "--quiet", "--format", "json", image}
+	case "grype":
+		args = []string{image, "--quiet", "--output", "json"}
+	default:
+		return nil, fmt.Errorf("unknown scanner %q, expected one of %s", scanner, strings.Join(Scanners, ", "))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, scanner, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%s is not installed, install it or pass reports of earlier scans", scanner)
+		}
+		return nil, fmt.Errorf("failed to scan %s with %s: %w: %s", image, scanner, err, strings.TrimSpace(stderr.String()))
+	}
+
+	report, err := Parse(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	// Scanners normalize names, keep the one the stack uses
+	report.Image = image
+	return report, nil
+}
+
+// Parse reads a JSON report of Trivy or Grype, telling them apart by their
+// fields.
+func Parse(data []byte) (*Report, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse scan report: %w", err)
+	}
+	switch {
+	case probe["Results"] != nil || probe["ArtifactName"] != nil:
+		return parseTrivy(data)
+	case probe["matches"] != nil:
+		return parseGrype(data)
+	}
+	return nil, fmt.Errorf("scan report is neither a Trivy nor a Grype JSON report")
+}
+
+// trivyReport is the part of a Trivy JSON report kroctl reads.
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Results      []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+func parseTrivy(data []byte) (*Report, error) {
+	var tr trivyReport
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return nil, fmt.Errorf("failed to parse Trivy report: %w", err)
+	}
+	report := &Report{Image: tr.ArtifactName}
+	for _, result := range tr.Results {
+		for _, v := range result.Vulnerabilities {
+			// Unknown severities are reported as such, not dropped
+			severity, _ := ParseSeverity(v.Severity)
+			report.Findings = append(report.Findings, Finding{
+				ID:       v.VulnerabilityID,
+				Package:  v.PkgName,
+				Version:  v.InstalledVersion,
+				Severity: severity,
+			})
+		}
+	}
+	return report, nil
+}
+
+// grypeReport is the part of a Grype JSON report kroctl reads.
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		Target json.RawMessage `json:"target"`
+	} `json:"source"`
+}
+
+func parseGrype(data []byte) (*Report, error) {
+	var gr grypeReport
+	if err := json.Unmarshal(data, &gr); err != nil {
+		return nil, fmt.Errorf("failed to parse Grype report: %w", err)
+	}
+	report := &Report{}
+	// The target of an image scan is an object, of other scans a string
+	var target struct {
+		UserInput string `json:"userInput"`
+	}
+	if json.Unmarshal(gr.Source.Target, &target) == nil {
+		report.Image = target.UserInput
+	} else {
+		_ = json.Unmarshal(gr.Source.Target, &report.Image)
+	}
+	for _, m := range gr.Matches {
+		severity, _ := ParseSeverity(m.Vulnerability.Severity)
+		report.Findings = append(report.Findings, Finding{
+			ID:       m.Vulnerability.ID,
+			Package:  m.Artifact.Name,
+			Version:  m.Artifact.Version,
+			Severity: severity,
+		})
+	}
+	return report, nil
+}
+
+// Counts returns the number of findings per severity.
+func (r *Report) Counts() map[Severity]int {
+	counts := map[Severity]int{}
+	for _, f := range r.Findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// AtLeast returns the findings of the given severity or worse.
+func (r *Report) AtLeast(threshold Severity) []Finding {
+	var findings []Finding
+	for _, f := range r.Findings {
+		if f.Severity >= threshold {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
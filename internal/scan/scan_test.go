@@ -0,0 +1,69 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/scan"
+)
+
+func TestParseSeverity(t *testing.T) {
+	s, err := scan.ParseSeverity("critical")
+	require.NoError(t, err)
+	assert.Equal(t, scan.SeverityCritical, s)
+
+	s, err = scan.ParseSeverity("Negligible")
+	require.NoError(t, err)
+	assert.Equal(t, scan.SeverityLow, s)
+
+	_, err = scan.ParseSeverity("severe")
+	assert.Error(t, err)
+}
+
+func TestParse_Trivy(t *testing.T) {
+	data := `{
+  "ArtifactName": "nginx:1.25",
+  "Results": [
+    {"Target": "debian", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-1", "PkgName": "openssl", "InstalledVersion": "3.0.1", "Severity": "CRITICAL"},
+      {"VulnerabilityID": "CVE-2024-2", "PkgName": "zlib", "InstalledVersion": "1.2", "Severity": "LOW"}
+    ]},
+    {"Target": "app"}
+  ]
+}`
+	report, err := scan.Parse([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.25", report.Image)
+	require.Len(t, report.Findings, 2)
+	assert.Equal(t, scan.Finding{ID: "CVE-2024-1", Package: "openssl", Version: "3.0.1", Severity: scan.SeverityCritical}, report.Findings[0])
+
+	counts := report.Counts()
+	assert.Equal(t, 1, counts[scan.SeverityCritical])
+	assert.Equal(t, 1, counts[scan.SeverityLow])
+	assert.Len(t, report.AtLeast(scan.SeverityHigh), 1)
+}
+
+func TestParse_Grype(t *testing.T) {
+	data := `{
+  "matches": [
+    {"vulnerability": {"id": "CVE-2024-3", "severity": "High"}, "artifact": {"name": "curl", "version": "8.0"}},
+    {"vulnerability": {"id": "CVE-2024-4", "severity": "Negligible"}, "artifact": {"name": "bash", "version": "5.1"}}
+  ],
+  "source": {"type": "image", "target": {"userInput": "redis:7"}}
+}`
+	report, err := scan.Parse([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "redis:7", report.Image)
+	require.Len(t, report.Findings, 2)
+	assert.Equal(t, scan.SeverityHigh, report.Findings[0].Severity)
+	assert.Equal(t, scan.SeverityLow, report.Findings[1].Severity)
+}
+
+func TestParse_Unknown(t *testing.T) {
+	_, err := scan.Parse([]byte(`{"foo": 1}`))
+	assert.Error(t, err)
+	_, err = scan.Parse([]byte(`not json`))
+	assert.Error(t, err)
+}
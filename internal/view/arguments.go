@@ -7,6 +7,7 @@ const (
 	ViewNone  ViewType = 0
 	ViewHuman ViewType = 'H'
 	ViewJSON  ViewType = 'J'
+	ViewYAML  ViewType = 'Y'
 )
 
 // String returns the string representation of the ViewType.
@@ -18,6 +19,8 @@ func (vt ViewType) String() string {
 		return "human"
 	case ViewJSON:
 		return "json"
+	case ViewYAML:
+		return "yaml"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,13 @@
+package view
+
+import "strings"
+
+// IsCI reports whether kroctl runs in a CI system. GitHub Actions, GitLab
+// CI, Jenkins, and most others set the CI environment variable.
+func IsCI(getenv func(string) string) bool {
+	switch strings.ToLower(getenv("CI")) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
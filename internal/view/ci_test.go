@@ -0,0 +1,23 @@
+package view_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestIsCI(t *testing.T) {
+	for value, want := range map[string]bool{
+		"":      false,
+		"false": false,
+		"0":     false,
+		"true":  true,
+		"1":     true,
+		"TRUE":  true,
+	} {
+		getenv := func(string) string { return value }
+		assert.Equal(t, want, view.IsCI(getenv), "CI=%q", value)
+	}
+}
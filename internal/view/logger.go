@@ -65,11 +65,11 @@ func rewriteLogLevel(groups []string, a slog.Attr) slog.Attr {
 		case slog.LevelDebug:
 			levelText = "DEBUG"
 		case slog.LevelInfo:
-			levelText = color.GreenString("INFO")
+			levelText = Success.Sprint("INFO")
 		case slog.LevelWarn:
-			levelText = color.YellowString("WARN")
+			levelText = Caution.Sprint("WARN")
 		case slog.LevelError:
-			levelText = color.RedString("ERROR")
+			levelText = Failure.Sprint("ERROR")
 		default:
 			levelText = level.String()
 		}
@@ -117,6 +117,7 @@ func NewHumanLogger(w io.Writer, level LogLevel) Logger {
 		Level:       level.toSlogLevel(),
 		TimeFormat:  time.DateTime,
 		ReplaceAttr: rewriteLogLevel,
+		NoColor:     color.NoColor,
 	}
 	handler := tint.NewHandler(w, opts)
 	logger := slog.New(handler)
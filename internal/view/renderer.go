@@ -0,0 +1,53 @@
+package view
+
+// Renderer prints the result of a command in one output format. Commands
+// implement the human format, the structured formats are shared.
+type Renderer[T any] interface {
+	Render(s *Stream, result T) error
+}
+
+// RendererFunc adapts a function to the Renderer interface.
+type RendererFunc[T any] func(s *Stream, result T) error
+
+func (f RendererFunc[T]) Render(s *Stream, result T) error {
+	return f(s, result)
+}
+
+// JSONRenderer prints results as indented JSON.
+type JSONRenderer[T any] struct{}
+
+func (JSONRenderer[T]) Render(s *Stream, result T) error {
+	return s.PrintJSON(result)
+}
+
+// YAMLRenderer prints results as YAML, with the field names of the JSON
+// output.
+type YAMLRenderer[T any] struct{}
+
+func (YAMLRenderer[T]) Render(s *Stream, result T) error {
+	return s.PrintYAML(result)
+}
+
+// TemplateRenderer prints results with a Go template.
+type TemplateRenderer[T any] struct {
+	Template string
+}
+
+func (r TemplateRenderer[T]) Render(s *Stream, result T) error {
+	return s.PrintTemplate(r.Template, result)
+}
+
+// NewRenderer returns the renderer for a view type. A template takes
+// precedence over the view type, and the human view uses human.
+func NewRenderer[T any](vt ViewType, template string, human Renderer[T]) Renderer[T] {
+	switch {
+	case template != "":
+		return TemplateRenderer[T]{Template: template}
+	case vt == ViewJSON:
+		return JSONRenderer[T]{}
+	case vt == ViewYAML:
+		return YAMLRenderer[T]{}
+	default:
+		return human
+	}
+}
@@ -0,0 +1,43 @@
+package view_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+type renderResult struct {
+	Reference string   `json:"reference"`
+	Files     []string `json:"files"`
+}
+
+func TestNewRenderer(t *testing.T) {
+	result := renderResult{Reference: "ghcr.io/acme/kro-stack:v1", Files: []string{"a.yaml"}}
+	human := view.RendererFunc[renderResult](func(s *view.Stream, r renderResult) error {
+		s.Printf("Pushed %s\n", r.Reference)
+		return nil
+	})
+
+	for _, tc := range []struct {
+		name     string
+		vt       view.ViewType
+		template string
+		want     string
+	}{
+		{"human", view.ViewHuman, "", "Pushed ghcr.io/acme/kro-stack:v1\n"},
+		{"json", view.ViewJSON, "", "{\n  \"reference\": \"ghcr.io/acme/kro-stack:v1\",\n  \"files\": [\n    \"a.yaml\"\n  ]\n}\n"},
+		{"yaml", view.ViewYAML, "", "reference: ghcr.io/acme/kro-stack:v1\nfiles:\n  - a.yaml\n"},
+		{"template", view.ViewJSON, "{{.Reference}}", "ghcr.io/acme/kro-stack:v1\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			r := view.NewRenderer[renderResult](tc.vt, tc.template, human)
+			require.NoError(t, r.Render(view.NewStream(buf), result))
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package view
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/bschaatsbergen/kroctl/version"
 )
@@ -25,6 +30,74 @@ func (s *Stream) Printf(fmtStr string, args ...any) {
 	fmt.Fprintf(s.Writer, fmtStr, args...)
 }
 
+// PrintJSON writes v as indented JSON.
+func (s *Stream) PrintJSON(v any) error {
+	enc := json.NewEncoder(s.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// PrintYAML writes v as YAML. v is encoded as JSON first, so fields are
+// named and ordered as in the JSON output.
+func (s *Stream) PrintYAML(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML, decoding it into a node keeps the key order
+	var n yaml.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	setBlockStyle(&n)
+
+	enc := yaml.NewEncoder(s.Writer)
+	enc.SetIndent(2)
+	if err := enc.Encode(&n); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// setBlockStyle drops the flow style and quotes a node decoded from JSON
+// carries.
+func setBlockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		setBlockStyle(c)
+	}
+}
+
+// templateFuncs are the functions available to --format templates, in
+// addition to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	"join": strings.Join,
+}
+
+// PrintTemplate renders v with a Go template, such as {{.Reference}}. A
+// newline is added unless the output ends with one.
+func (s *Stream) PrintTemplate(text string, v any) error {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid format template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, v); err != nil {
+		return fmt.Errorf("failed to render format template: %w", err)
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err = io.WriteString(s.Writer, out)
+	return err
+}
+
 func (s *Stream) PrintVersion() {
 	version.Fprint(s.Writer)
 }
@@ -0,0 +1,32 @@
+package view_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestStream_PrintTemplate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := view.NewStream(buf)
+
+	v := struct {
+		Reference string
+		Files     []string
+	}{Reference: "ghcr.io/acme/kro-stack:v1", Files: []string{"a.yaml", "b.yaml"}}
+
+	require.NoError(t, s.PrintTemplate("{{.Reference}}", v))
+	require.NoError(t, s.PrintTemplate("{{join .Files \",\"}} {{json .Files}}\n", v))
+	assert.Equal(t, "ghcr.io/acme/kro-stack:v1\na.yaml,b.yaml [\"a.yaml\",\"b.yaml\"]\n", buf.String())
+}
+
+func TestStream_PrintTemplate_Invalid(t *testing.T) {
+	s := view.NewStream(new(bytes.Buffer))
+
+	assert.ErrorContains(t, s.PrintTemplate("{{.Reference", nil), "invalid format template")
+	assert.ErrorContains(t, s.PrintTemplate("{{.Missing}}", struct{}{}), "failed to render")
+}
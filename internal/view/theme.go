@@ -0,0 +1,55 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+// ColorMode tells when output is styled with color.
+type ColorMode string
+
+const (
+	// ColorAuto styles output written to a terminal, unless NO_COLOR is set
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+// ParseColorMode validates the value of --color.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(s); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid color mode %q, expected auto, always, or never", s)
+}
+
+// SetColorMode turns styling on or off for all output. In auto mode color
+// keeps the decision it made on startup, which leaves out pipes, dumb
+// terminals, and NO_COLOR; plain is set when output must stay plain anyway,
+// such as in CI.
+func SetColorMode(mode ColorMode, plain bool) {
+	switch mode {
+	case ColorAlways:
+		color.NoColor = false
+	case ColorNever:
+		color.NoColor = true
+	default:
+		color.NoColor = color.NoColor || plain
+	}
+}
+
+// The theme of kroctl. Basic ANSI colors are used rather than RGB values, so
+// the terminal maps them onto its palette and they stay readable on light
+// and dark backgrounds alike.
+var (
+	// Accent highlights headings and the usage line
+	Accent = color.New(color.FgBlue, color.Bold)
+	// Success marks things that went well
+	Success = color.New(color.FgGreen)
+	// Caution marks things that need attention
+	Caution = color.New(color.FgYellow)
+	// Failure marks errors
+	Failure = color.New(color.FgRed)
+)
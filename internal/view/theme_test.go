@@ -0,0 +1,40 @@
+package view_test
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/internal/view"
+)
+
+func TestParseColorMode(t *testing.T) {
+	for _, s := range []string{"auto", "always", "never"} {
+		mode, err := view.ParseColorMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, view.ColorMode(s), mode)
+	}
+
+	_, err := view.ParseColorMode("sometimes")
+	assert.Error(t, err)
+}
+
+func TestSetColorMode(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+
+	color.NoColor = true
+	view.SetColorMode(view.ColorAlways, true)
+	assert.False(t, color.NoColor)
+
+	view.SetColorMode(view.ColorAuto, false)
+	assert.False(t, color.NoColor)
+
+	view.SetColorMode(view.ColorAuto, true)
+	assert.True(t, color.NoColor)
+
+	color.NoColor = false
+	view.SetColorMode(view.ColorNever, false)
+	assert.True(t, color.NoColor)
+}
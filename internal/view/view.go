@@ -2,11 +2,13 @@ package view
 
 var _ Viewer = (*HumanView)(nil)
 var _ Viewer = (*JSONView)(nil)
+var _ Viewer = (*YAMLView)(nil)
 
 // Viewer represents an output formatting strategy.
 // Each view type (e.g., Human, JSON) implements this interface to support different views (e.g., FmtView, PlanView).
 type Viewer interface {
 	Logger() Logger
+	ViewType() ViewType
 }
 
 func NewViewer(vt ViewType, s *Stream, level LogLevel) Viewer {
@@ -16,6 +18,8 @@ func NewViewer(vt ViewType, s *Stream, level LogLevel) Viewer {
 		return NewHumanView(s, level)
 	case ViewJSON:
 		return NewJSONView(s, level)
+	case ViewYAML:
+		return NewYAMLView(s, level)
 	default:
 		panic("unknown view type")
 	}
@@ -43,6 +47,10 @@ func (h *HumanView) Logger() Logger {
 	return h.logger
 }
 
+func (h *HumanView) ViewType() ViewType {
+	return ViewHuman
+}
+
 type JSONView struct {
 	*Stream
 	logger Logger
@@ -64,3 +72,21 @@ func NewJSONView(s *Stream, level LogLevel) *JSONView {
 func (j *JSONView) Logger() Logger {
 	return j.logger
 }
+
+func (j *JSONView) ViewType() ViewType {
+	return ViewJSON
+}
+
+// YAMLView prints results as YAML. Logs stay human-readable, YAML has no
+// line-oriented log format of its own.
+type YAMLView struct {
+	*HumanView
+}
+
+func NewYAMLView(s *Stream, level LogLevel) *YAMLView {
+	return &YAMLView{HumanView: NewHumanView(s, level)}
+}
+
+func (y *YAMLView) ViewType() ViewType {
+	return ViewYAML
+}
@@ -0,0 +1,217 @@
+// Package artifact packages ResourceGraphDefinitions as OCI artifacts and
+// moves them between registries, the same way the kroctl CLI does. It lets
+// other tools and operators embed kroctl instead of shelling out to it.
+//
+//	desc, err := artifact.New().
+//		AddFile("stack.yaml").
+//		AddFile("vpc.yaml").
+//		Push(ctx, "ghcr.io/acme/kro-stack:v1.0.0")
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/bschaatsbergen/kroctl/internal/oci"
+	"github.com/bschaatsbergen/kroctl/internal/rgd"
+)
+
+// File is a single ResourceGraphDefinition file of an artifact.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Artifact is an RGD stack being built. Methods that add content record
+// the first error, which is returned by Validate and Push.
+type Artifact struct {
+	files       []File
+	annotations map[string]string
+	err         error
+}
+
+// New returns an empty artifact.
+func New() *Artifact {
+	return &Artifact{annotations: map[string]string{}}
+}
+
+// AddFile adds the file at path, named by its base name.
+func (a *Artifact) AddFile(path string) *Artifact {
+	if a.err != nil {
+		return a
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		a.err = fmt.Errorf("failed to read %s: %w", path, err)
+		return a
+	}
+	return a.AddContent(filepath.Base(path), data)
+}
+
+// AddContent adds a file with the given name and content.
+func (a *Artifact) AddContent(name string, data []byte) *Artifact {
+	if a.err != nil {
+		return a
+	}
+	for _, f := range a.files {
+		if f.Name == name {
+			a.err = fmt.Errorf("duplicate file %s", name)
+			return a
+		}
+	}
+	a.files = append(a.files, File{Name: name, Content: data})
+	return a
+}
+
+// Annotate sets an annotation on the artifact manifest.
+func (a *Artifact) Annotate(key, value string) *Artifact {
+	a.annotations[key] = value
+	return a
+}
+
+// Files returns the files added so far.
+func (a *Artifact) Files() []File {
+	return a.files
+}
+
+// Validate parses and lints the files with the checks of kroctl validate.
+// Warnings do not fail validation.
+func (a *Artifact) Validate() error {
+	if a.err != nil {
+		return a.err
+	}
+	if len(a.files) == 0 {
+		return errors.New("artifact has no files")
+	}
+
+	var all rgd.Diagnostics
+	for _, f := range a.files {
+		rgds, err := rgd.Parse(f.Name, f.Content)
+		var diags rgd.Diagnostics
+		if errors.As(err, &diags) {
+			all = append(all, diags...)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, r := range rgds {
+			all = append(all, rgd.Lint(f.Name, r, rgd.LintOptions{})...)
+		}
+	}
+
+	if all.HasErrors() {
+		return fmt.Errorf("invalid ResourceGraphDefinitions:\n%w", all)
+	}
+	return nil
+}
+
+// Push validates the artifact and pushes it to the registry at reference,
+// using the Docker credential store for authentication. It returns the
+// descriptor of the pushed manifest.
+func (a *Artifact) Push(ctx context.Context, reference string) (v1.Descriptor, error) {
+	if err := a.Validate(); err != nil {
+		return v1.Descriptor{}, err
+	}
+
+	store := memory.New()
+	layers := make([]v1.Descriptor, 0, len(a.files))
+	contents := make([][]byte, 0, len(a.files))
+	for _, f := range a.files {
+		desc := content.NewDescriptorFromBytes(oci.LayerMediaType, f.Content)
+		desc.Annotations = map[string]string{v1.AnnotationTitle: f.Name}
+		if err := store.Push(ctx, desc, bytes.NewReader(f.Content)); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("failed to add %s to store: %w", f.Name, err)
+		}
+		layers = append(layers, desc)
+		contents = append(contents, f.Content)
+	}
+
+	config, err := oci.NewStackConfig(reference, layers, contents)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	manifestDesc, err := oci.PackStack(ctx, store, config, layers, a.annotations, nil)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if err := store.Tag(ctx, manifestDesc, reference); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to tag manifest: %w", err)
+	}
+
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if _, err := oras.Copy(ctx, store, reference, repo, reference, oras.DefaultCopyOptions); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to push artifact: %w", err)
+	}
+
+	return manifestDesc, nil
+}
+
+// Pull downloads the artifact at reference and returns it with its files
+// and manifest annotations, ready to be validated or pushed elsewhere.
+func Pull(ctx context.Context, reference string) (*Artifact, error) {
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := fetchStack(ctx, repo, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	a := New()
+	for k, v := range manifest.Annotations {
+		if k != v1.AnnotationCreated {
+			a.Annotate(k, v)
+		}
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != oci.LayerMediaType {
+			continue
+		}
+		data, err := oci.FetchLayer(ctx, repo, layer)
+		if err != nil {
+			return nil, err
+		}
+		a.AddContent(oci.LayerTitle(layer), data)
+	}
+
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a, nil
+}
+
+// Inspect fetches the manifest of the RGD stack at reference.
+func Inspect(ctx context.Context, reference string) (*v1.Manifest, error) {
+	repo, err := oci.SetupRepository(reference)
+	if err != nil {
+		return nil, err
+	}
+	return fetchStack(ctx, repo, reference)
+}
+
+func fetchStack(ctx context.Context, repo *remote.Repository, reference string) (*v1.Manifest, error) {
+	_, manifest, err := oci.FetchManifest(ctx, repo, reference, "")
+	if err != nil {
+		return nil, err
+	}
+	if !oci.IsStack(manifest) {
+		return nil, fmt.Errorf("%s is not a kro RGD stack", reference)
+	}
+	return manifest, nil
+}
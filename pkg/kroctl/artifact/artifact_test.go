@@ -0,0 +1,59 @@
+package artifact_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bschaatsbergen/kroctl/pkg/kroctl/artifact"
+)
+
+const validRGD = "apiVersion: kro.run/v1alpha1\n" +
+	"kind: ResourceGraphDefinition\n" +
+	"metadata:\n" +
+	"  name: app\n" +
+	"spec:\n" +
+	"  schema:\n" +
+	"    apiVersion: v1alpha1\n" +
+	"    kind: App\n"
+
+func TestArtifact_AddFile(t *testing.T) {
+	a := artifact.New().AddFile(filepath.Join("..", "..", "..", "assets", "stacks", "network", "vpc.yaml"))
+
+	require.NoError(t, a.Validate())
+	require.Len(t, a.Files(), 1)
+	assert.Equal(t, "vpc.yaml", a.Files()[0].Name)
+}
+
+func TestArtifact_AddFileMissing(t *testing.T) {
+	a := artifact.New().
+		AddFile("missing.yaml").
+		AddContent("app.yaml", []byte(validRGD))
+
+	err := a.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read missing.yaml")
+	assert.Empty(t, a.Files())
+}
+
+func TestArtifact_DuplicateFile(t *testing.T) {
+	a := artifact.New().
+		AddContent("app.yaml", []byte(validRGD)).
+		AddContent("app.yaml", []byte(validRGD))
+
+	assert.EqualError(t, a.Validate(), "duplicate file app.yaml")
+}
+
+func TestArtifact_ValidateEmpty(t *testing.T) {
+	assert.EqualError(t, artifact.New().Validate(), "artifact has no files")
+}
+
+func TestArtifact_ValidateInvalid(t *testing.T) {
+	a := artifact.New().AddContent("app.yaml", []byte(validRGD+"  bogus: true\n"))
+
+	err := a.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "app.yaml:9: unknown field \"bogus\"")
+}
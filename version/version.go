@@ -4,19 +4,82 @@ import (
 	_ "embed"
 	"fmt"
 	"io"
+	"os"
 	"runtime"
+	"runtime/debug"
 )
 
+// Set at build time with -ldflags "-X github.com/bschaatsbergen/kroctl/version.Version=...".
 var (
-	Version string = "dev"
+	Version   string = "dev"
+	Commit    string = ""
+	BuildDate string = ""
 )
 
+// orasModule is the module path of the OCI library kroctl is built with.
+const orasModule = "oras.land/oras-go/v2"
+
+// Info describes the kroctl build.
+type Info struct {
+	Version     string
+	Commit      string
+	BuildDate   string
+	GoVersion   string
+	OrasVersion string
+	Platform    string
+}
+
+// Get returns the build information. The commit and build date fall back
+// to the VCS information Go embeds when they are not set at build time.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, dep := range build.Deps {
+		if dep.Path == orasModule {
+			info.OrasVersion = dep.Version
+		}
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	return info
+}
+
 func Print() {
-	fmt.Printf("kroctl version %s\n", Version)
-	fmt.Printf("%s/%s\n", runtime.GOOS, runtime.GOARCH)
+	Fprint(os.Stdout)
 }
 
 func Fprint(w io.Writer) {
-	fmt.Fprintf(w, "kroctl version %s\n", Version)
-	fmt.Fprintf(w, "%s/%s\n", runtime.GOOS, runtime.GOARCH)
+	info := Get()
+	fmt.Fprintf(w, "kroctl version %s\n", info.Version)
+	fmt.Fprintf(w, "%s\n", info.Platform)
+	if info.Commit != "" {
+		fmt.Fprintf(w, "commit:     %s\n", info.Commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Fprintf(w, "built:      %s\n", info.BuildDate)
+	}
+	fmt.Fprintf(w, "go:         %s\n", info.GoVersion)
+	if info.OrasVersion != "" {
+		fmt.Fprintf(w, "oras-go:    %s\n", info.OrasVersion)
+	}
 }